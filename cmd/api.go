@@ -10,12 +10,17 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/Nasaee/go-todo-backend/internal/account"
 	"github.com/Nasaee/go-todo-backend/internal/auth"
 	"github.com/Nasaee/go-todo-backend/internal/env"
+	"github.com/Nasaee/go-todo-backend/internal/ratelimit"
+	"github.com/Nasaee/go-todo-backend/internal/share"
+	"github.com/Nasaee/go-todo-backend/internal/tag"
+	"github.com/Nasaee/go-todo-backend/internal/template"
 	"github.com/Nasaee/go-todo-backend/internal/todo"
 	"github.com/Nasaee/go-todo-backend/internal/todogroup"
 	"github.com/Nasaee/go-todo-backend/internal/user"
-	"github.com/Nasaee/go-todo-backend/pkg/utils"
+	"github.com/Nasaee/go-todo-backend/internal/workspace"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
@@ -37,9 +42,19 @@ type application struct {
 	userService      user.UserService
 	tokenService     auth.TokenService
 	todoGroupService todogroup.TodoGroupService
+	memberService    todogroup.MemberService
+	templateService  template.Service
+	tagService       tag.Service
 	todoService      todo.Service
+	shareService     share.Service
+	shareLimiter     *ratelimit.Limiter
+	workspaceService workspace.Service
+	accountService   account.Service
 	refreshTTL       time.Duration
 	isProd           bool
+	avatarMaxUpload  int64
+	// ไม่ nil เฉพาะตอนใช้ local blob storage (ต้องเสิร์ฟไฟล์เอง)
+	mediaHandler http.Handler
 }
 
 func (app *application) mount() http.Handler {
@@ -71,10 +86,21 @@ func (app *application) mount() http.Handler {
 		w.Write([]byte("all good"))
 	})
 
+	// ไฟล์ avatar ฯลฯ ตอนใช้ local storage (ถ้าใช้ S3 client โหลดจาก bucket ตรง ๆ)
+	if app.mediaHandler != nil {
+		r.Handle("/media/*", http.StripPrefix("/media/", app.mediaHandler))
+	}
+
 	authHandler := auth.NewHandler(app.userService, app.tokenService, app.refreshTTL, app.isProd)
 	todoGroupHandler := todogroup.NewHandler(app.todoGroupService)
+	memberHandler := todogroup.NewMemberHandler(app.memberService)
+	templateHandler := template.NewHandler(app.templateService)
+	tagHandler := tag.NewHandler(app.tagService)
 
 	todoHandler := todo.NewHandler(app.todoService)
+	shareHandler := share.NewHandler(app.shareService)
+	workspaceHandler := workspace.NewHandler(app.workspaceService)
+	accountHandler := account.NewHandler(app.accountService, app.isProd, app.avatarMaxUpload)
 
 	r.Route("/auth", func(r chi.Router) {
 		r.Post("/register", authHandler.Register)
@@ -83,41 +109,134 @@ func (app *application) mount() http.Handler {
 		r.Post("/logout", authHandler.Logout)
 	})
 
+	// public share link (ไม่ต้อง login) จำกัดจำนวนครั้งต่อ IP กันการสุ่ม token
+	r.With(app.shareLimiter.Middleware(ratelimit.ByIP)).Get("/shared/{token}", shareHandler.Open)
+
 	// ส่วนนี้คือ protected routes (ต้องมี access token)
 	r.Route("/", func(r chi.Router) {
 		r.Group(func(r chi.Router) {
 			// ใช้ AuthMiddleware ครอบทั้ง group
 			r.Use(auth.AuthMiddleware(app.tokenService))
 
-			// ตัวอย่าง route: GET /api/me
-			r.Get("/me", func(w http.ResponseWriter, r *http.Request) {
-				userID, ok := auth.UserIDFromContext(r.Context())
-				if !ok {
-					http.Error(w, "user not found in context", http.StatusUnauthorized)
-					return
-				}
-
-				// ตรงนี้อนาคตคุณค่อยไปดึง user จาก DB
-				utils.WriteJSON(w, http.StatusOK, map[string]any{
-					"user_id": userID,
-				})
-			})
+			r.Get("/me", accountHandler.Me)                   // GET /api/me
+			r.Put("/me/avatar", accountHandler.PutAvatar)     // PUT /api/me/avatar
+			r.Put("/me/timezone", accountHandler.PutTimezone) // PUT /api/me/timezone
+			r.Delete("/me", accountHandler.Delete)            // DELETE /api/me
+			r.Get("/me/export", accountHandler.Export)        // GET /api/me/export
 
-			// todo_groups routes
-			r.Route("/todo-groups", func(r chi.Router) {
+			// route ของ todo_groups / todos ใช้ชุดเดียวกันทั้งแบบเดิม (เห็นทุก workspace)
+			// และแบบ /workspaces/{wid}/... (service scope ด้วย workspace id ใน context)
+			todoGroupRoutes := func(r chi.Router) {
 				r.Post("/", todoGroupHandler.Create)
 				r.Get("/", todoGroupHandler.GetAll)
+				r.Get("/palette", todoGroupHandler.Palette)
+				r.Get("/{id}", todoGroupHandler.GetByID)
+				r.Put("/{id}", todoGroupHandler.Update)
+				r.Patch("/{id}", todoGroupHandler.Patch)
+				r.Delete("/{id}", todoGroupHandler.Delete)
+				r.Post("/{id}/move", todoGroupHandler.Move)
+				r.Post("/{id}/archive", todoGroupHandler.Archive)
+				r.Post("/{id}/unarchive", todoGroupHandler.Unarchive)
+				r.Post("/{id}/clone", todoGroupHandler.Clone)
+				r.Get("/{id}/todos", todoHandler.ListGroupTodos)
+
+				// แชร์ group: สมาชิก + คำเชิญ
+				r.Get("/{id}/members", memberHandler.ListMembers)
+				r.Patch("/{id}/members/{userID}", memberHandler.UpdateMember)
+				r.Delete("/{id}/members/{userID}", memberHandler.RemoveMember)
+				r.Post("/{id}/invitations", memberHandler.Invite)
+				r.Get("/{id}/invitations", memberHandler.ListGroupInvitations)
+				r.Delete("/{id}/invitations/{invitationID}", memberHandler.RevokeInvitation)
+
+				// public share link (read-only)
+				r.Post("/{id}/share-links", shareHandler.Create)
+				r.Get("/{id}/share-links", shareHandler.List)
+				r.Delete("/{id}/share-links/{linkID}", shareHandler.Revoke)
+			}
+
+			todoRoutes := func(r chi.Router) {
+				r.Get("/", todoHandler.ListTodos)                      // GET /api/todos
+				r.Post("/", todoHandler.CreateTodo)                    // POST /api/todos
+				r.Get("/search", todoHandler.SearchTodos)              // GET /api/todos/search
+				r.Get("/today", todoHandler.ListTodayTodos)            // GET /api/todos/today
+				r.Get("/tomorrow", todoHandler.ListTomorrow)           // GET /api/todos/tomorrow
+				r.Get("/this-week", todoHandler.ListThisWeek)          // GET /api/todos/this-week
+				r.Get("/overdue", todoHandler.ListOverdue)             // GET /api/todos/overdue
+				r.Get("/upcoming", todoHandler.ListUpcoming)           // GET /api/todos/upcoming?days=N
+				r.Get("/range", todoHandler.ListRange)                 // GET /api/todos/range?from=&to=
+				r.Get("/completed", todoHandler.ListCompleted)         // GET /api/todos/completed?from=&to=
+				r.Get("/assigned-to-me", todoHandler.ListAssignedToMe) // GET /api/todos/assigned-to-me
+				r.Get("/matrix", todoHandler.ListMatrix)               // GET /api/todos/matrix
+				r.Get("/{id}", todoHandler.GetTodoByID)                // GET /api/todos/{id}
+				r.Put("/{id}", todoHandler.UpdateTodo)                 // PUT /api/todos/{id}
+				r.Patch("/{id}", todoHandler.PatchTodo)                // PATCH /api/todos/{id}
+				r.Delete("/{id}", todoHandler.DeleteTodo)              // DELETE /api/todos/{id}
+				r.Post("/{id}/move", todoHandler.MoveTodo)             // POST /api/todos/{id}/move
+
+				// checklist ของ todo
+				r.Get("/{id}/checklist", todoHandler.ListChecklist)                    // GET /api/todos/{id}/checklist
+				r.Post("/{id}/checklist", todoHandler.AddChecklistItem)                // POST /api/todos/{id}/checklist
+				r.Patch("/{id}/checklist/{itemID}", todoHandler.PatchChecklistItem)    // PATCH /api/todos/{id}/checklist/{itemID}
+				r.Delete("/{id}/checklist/{itemID}", todoHandler.DeleteChecklistItem)  // DELETE /api/todos/{id}/checklist/{itemID}
+				r.Post("/{id}/checklist/{itemID}/move", todoHandler.MoveChecklistItem) // POST /api/todos/{id}/checklist/{itemID}/move
+			}
+
+			r.Route("/todo-groups", todoGroupRoutes)
+			r.Route("/todos", todoRoutes)
+
+			// คำเชิญที่ส่งถึง user
+			r.Route("/invitations", func(r chi.Router) {
+				r.Post("/{invitationID}/accept", memberHandler.AcceptInvitation)
+				r.Post("/{invitationID}/decline", memberHandler.DeclineInvitation)
+			})
+
+			// template ของ group (เป็นของ user คนเดียว)
+			r.Route("/templates", func(r chi.Router) {
+				r.Get("/", templateHandler.List)
+				r.Post("/", templateHandler.Create)
+				r.Get("/{id}", templateHandler.Get)
+				r.Put("/{id}", templateHandler.Update)
+				r.Delete("/{id}", templateHandler.Delete)
+				r.Post("/{id}/instantiate", templateHandler.Instantiate)
+			})
+
+			// tag ของ todo (เป็นของ user คนเดียว ติดกับ todo ผ่าน tag_ids)
+			r.Route("/tags", func(r chi.Router) {
+				r.Get("/", tagHandler.List)
+				r.Post("/", tagHandler.Create)
+				r.Get("/{id}", tagHandler.Get)
+				r.Patch("/{id}", tagHandler.Patch)
+				r.Delete("/{id}", tagHandler.Delete)
 			})
 
-			r.Route("/todos", func(r chi.Router) {
-				r.Get("/", todoHandler.ListTodos)             // GET /api/todos
-				r.Post("/", todoHandler.CreateTodo)           // POST /api/todos
-				r.Get("/today", todoHandler.ListTodayTodos)   // GET /api/todos/today
-				r.Get("/tomorrow", todoHandler.ListTomorrow)  // GET /api/todos/tomorrow
-				r.Get("/this-week", todoHandler.ListThisWeek) // GET /api/todos/this-week
-				r.Get("/{id}", todoHandler.GetTodoByID)       // GET /api/todos/{id}
-				r.Put("/{id}", todoHandler.UpdateTodo)        // PUT /api/todos/{id}
-				r.Delete("/{id}", todoHandler.DeleteTodo)     // DELETE /api/todos/{id}
+			// workspaces (personal + team)
+			r.Route("/workspaces", func(r chi.Router) {
+				r.Get("/", workspaceHandler.List)
+				r.Post("/", workspaceHandler.Create)
+
+				// คำเชิญที่ส่งถึง user (ต้องมี token จาก email)
+				r.Post("/invitations/{invitationID}/accept", workspaceHandler.AcceptInvitation)
+				r.Post("/invitations/{invitationID}/decline", workspaceHandler.DeclineInvitation)
+
+				r.Route("/{wid}", func(r chi.Router) {
+					r.Get("/", workspaceHandler.Get)
+					r.Patch("/", workspaceHandler.Update)
+					r.Delete("/", workspaceHandler.Delete)
+
+					r.Get("/members", workspaceHandler.ListMembers)
+					r.Patch("/members/{userID}", workspaceHandler.UpdateMember)
+					r.Delete("/members/{userID}", workspaceHandler.RemoveMember)
+					r.Post("/invitations", workspaceHandler.Invite)
+					r.Get("/invitations", workspaceHandler.ListInvitations)
+					r.Delete("/invitations/{invitationID}", workspaceHandler.RevokeInvitation)
+
+					// GET /api/workspaces/{wid}/todos ...
+					r.Group(func(r chi.Router) {
+						r.Use(workspaceHandler.Middleware)
+						r.Route("/todo-groups", todoGroupRoutes)
+						r.Route("/todos", todoRoutes)
+					})
+				})
 			})
 		})
 	})
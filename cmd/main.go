@@ -3,14 +3,26 @@ package main
 import (
 	"context"
 	"log/slog"
+	"net/http"
 	"os"
 	"time"
+	// ฝัง tz database ไว้ใน binary (timezone ของ user / todo ที่เกิดซ้ำ) image ที่ไม่มี zoneinfo ก็ใช้ได้
+	_ "time/tzdata"
 
+	"github.com/Nasaee/go-todo-backend/internal/account"
+	"github.com/Nasaee/go-todo-backend/internal/audit"
 	"github.com/Nasaee/go-todo-backend/internal/auth"
 	"github.com/Nasaee/go-todo-backend/internal/env"
+	"github.com/Nasaee/go-todo-backend/internal/event"
+	"github.com/Nasaee/go-todo-backend/internal/ratelimit"
+	"github.com/Nasaee/go-todo-backend/internal/share"
+	"github.com/Nasaee/go-todo-backend/internal/storage"
+	"github.com/Nasaee/go-todo-backend/internal/tag"
+	"github.com/Nasaee/go-todo-backend/internal/template"
 	"github.com/Nasaee/go-todo-backend/internal/todo"
 	"github.com/Nasaee/go-todo-backend/internal/todogroup"
 	"github.com/Nasaee/go-todo-backend/internal/user"
+	"github.com/Nasaee/go-todo-backend/internal/workspace"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 )
@@ -63,15 +75,59 @@ func main() {
 	})
 	defer rdb.Close()
 
+	// blob storage (avatar): BLOB_DRIVER=local | s3
+	var blobs storage.BlobStore
+	var mediaHandler http.Handler
+	switch env.GetString("BLOB_DRIVER", "local") {
+	case "s3":
+		blobs = storage.NewS3Store(storage.S3Config{
+			Endpoint:  env.GetString("S3_ENDPOINT", "http://localhost:9000"),
+			Region:    env.GetString("S3_REGION", "us-east-1"),
+			Bucket:    env.GetString("S3_BUCKET", "todo-media"),
+			AccessKey: env.GetString("S3_ACCESS_KEY", ""),
+			SecretKey: env.GetString("S3_SECRET_KEY", ""),
+			PublicURL: env.GetString("S3_PUBLIC_URL", ""),
+		})
+	default:
+		local := storage.NewLocalStore(
+			env.GetString("BLOB_LOCAL_DIR", "./data/media"),
+			env.GetString("BLOB_LOCAL_BASE_URL", "http://localhost:8080/media"),
+		)
+		blobs = local
+		mediaHandler = local.Handler()
+	}
+
+	avatarLimits := user.AvatarLimits{
+		MaxBytes:     int64(env.GetInt("AVATAR_MAX_BYTES", int(user.DefaultAvatarLimits.MaxBytes))),
+		MinDimension: env.GetInt("AVATAR_MIN_DIMENSION", user.DefaultAvatarLimits.MinDimension),
+		MaxDimension: env.GetInt("AVATAR_MAX_DIMENSION", user.DefaultAvatarLimits.MaxDimension),
+	}
+
 	// services
 	userRepo := user.NewRepository(pool)
-	userSvc := user.NewService(userRepo)
+	userSvc := user.NewService(userRepo, blobs, avatarLimits)
+
+	// domain event (เช่น todo.assigned, คำเชิญที่ต้องส่ง email) publish เข้า redis ให้ worker subscribe
+	events := event.NewRedisPublisher(rdb, env.GetString("EVENTS_CHANNEL", "events"))
+
+	workspaceSvc := workspace.NewService(workspace.NewRepository(pool), events)
 
 	todoGroupRepo := todogroup.NewRepository(pool)
 	todoGroupSvc := todogroup.NewService(todoGroupRepo)
+	memberSvc := todogroup.NewMemberService(todogroup.NewMemberRepository(pool), todoGroupRepo, events)
+	templateSvc := template.NewService(template.NewRepository(pool), todoGroupRepo)
+	tagSvc := tag.NewService(tag.NewRepository(pool))
 
 	todoRepo := todo.NewRepository(pool)
-	todoSvc := todo.NewService(todoRepo)
+	todoSvc := todo.NewService(todoRepo, todoGroupRepo, events)
+
+	// public share link: เปิดได้โดยไม่ login จึงต้อง rate limit ต่อ IP + เก็บ audit log
+	auditLog := audit.NewLogger(pool)
+	shareSvc := share.NewService(share.NewRepository(pool), todoGroupRepo, auditLog)
+	shareLimiter := ratelimit.New(rdb, "shared",
+		env.GetInt("SHARE_RATE_LIMIT", 60),
+		time.Duration(env.GetInt("SHARE_RATE_WINDOW_SEC", 60))*time.Second,
+	)
 
 	refreshTTL := 7 * 24 * time.Hour
 	accessTTL := 15 * time.Minute
@@ -85,15 +141,32 @@ func main() {
 		rdb,
 	)
 
+	// ลบ account: รอ grace period ก่อน แล้วมี job คอย purge ทิ้งเป็นระยะ
+	deletionGrace := time.Duration(env.GetInt("ACCOUNT_DELETION_GRACE_DAYS", 30)) * 24 * time.Hour
+	purgeInterval := time.Duration(env.GetInt("ACCOUNT_PURGE_INTERVAL_MIN", 60)) * time.Minute
+
+	accountSvc := account.NewService(userSvc, tokenSvc, todoGroupSvc, todoSvc, deletionGrace)
+	go account.RunPurger(ctx, accountSvc, purgeInterval)
+
 	api := application{
 		config:           cfg,
 		db:               pool,
 		userService:      userSvc,
 		tokenService:     tokenSvc,
 		todoGroupService: todoGroupSvc,
+		memberService:    memberSvc,
+		templateService:  templateSvc,
+		tagService:       tagSvc,
 		todoService:      todoSvc,
+		shareService:     shareSvc,
+		shareLimiter:     shareLimiter,
+		workspaceService: workspaceSvc,
+		accountService:   accountSvc,
 		refreshTTL:       refreshTTL,
 		isProd:           isProd,
+		// เผื่อ overhead ของ multipart ไว้ 1 MB
+		avatarMaxUpload: avatarLimits.MaxBytes + 1<<20,
+		mediaHandler:    mediaHandler,
 	}
 
 	// ใช้ ctx + graceful shutdown
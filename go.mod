@@ -11,6 +11,7 @@ require (
 	github.com/joho/godotenv v1.5.1
 	github.com/redis/go-redis/v9 v9.17.0
 	golang.org/x/crypto v0.44.0
+	golang.org/x/image v0.25.0
 )
 
 require (
@@ -0,0 +1,236 @@
+package account
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/Nasaee/go-todo-backend/internal/auth"
+	"github.com/Nasaee/go-todo-backend/internal/user"
+	"github.com/Nasaee/go-todo-backend/pkg/utils"
+)
+
+type Handler struct {
+	svc             Service
+	isProd          bool
+	avatarMaxUpload int64
+}
+
+// avatarMaxUpload = ขนาด body สูงสุดของ PUT /me/avatar (ทั้ง multipart) กันคนยิงไฟล์ยักษ์เข้ามา
+func NewHandler(svc Service, isProd bool, avatarMaxUpload int64) *Handler {
+	return &Handler{
+		svc:             svc,
+		isProd:          isProd,
+		avatarMaxUpload: avatarMaxUpload,
+	}
+}
+
+// GET /me
+func (h *Handler) Me(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	u, err := h.svc.Profile(r.Context(), userID)
+	if err != nil {
+		fmt.Println(err)
+		if errors.Is(err, user.ErrUserNotFound) {
+			utils.WriteError(w, http.StatusNotFound, "user not found")
+			return
+		}
+		utils.WriteError(w, http.StatusInternalServerError, "could not fetch user")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]any{
+		"user_id": userID,
+		"user":    user.ToUserDTO(u),
+	})
+}
+
+// PUT /me/avatar (multipart/form-data, field "avatar")
+func (h *Handler) PutAvatar(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.avatarMaxUpload)
+
+	// อ่านแบบ stream หา part ชื่อ avatar ไม่ต้องพักไฟล์ลง disk แบบ ParseMultipartForm
+	mr, err := r.MultipartReader()
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "expected multipart/form-data body")
+		return
+	}
+
+	var part *multipart.Part
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "invalid multipart body")
+			return
+		}
+		if p.FormName() == "avatar" {
+			part = p
+			break
+		}
+	}
+	if part == nil {
+		utils.WriteError(w, http.StatusBadRequest, "avatar file is required")
+		return
+	}
+
+	u, err := h.svc.SetAvatar(r.Context(), userID, part)
+	if err != nil {
+		fmt.Println(err)
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.Is(err, user.ErrAvatarTooLarge), errors.As(err, &maxBytesErr):
+			utils.WriteError(w, http.StatusRequestEntityTooLarge, user.ErrAvatarTooLarge.Error())
+		case errors.Is(err, user.ErrAvatarUnsupported):
+			utils.WriteError(w, http.StatusUnsupportedMediaType, err.Error())
+		case errors.Is(err, user.ErrAvatarBadDimensions):
+			utils.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+		case errors.Is(err, user.ErrUserNotFound):
+			utils.WriteError(w, http.StatusNotFound, "user not found")
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, "could not update avatar")
+		}
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, user.ToUserDTO(u))
+}
+
+// PUT /me/timezone
+func (h *Handler) PutTimezone(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	var input user.SetTimezoneInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	u, err := h.svc.SetTimezone(r.Context(), userID, input.Timezone)
+	if err != nil {
+		fmt.Println(err)
+		switch {
+		case errors.Is(err, user.ErrInvalidTimezone):
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, user.ErrUserNotFound):
+			utils.WriteError(w, http.StatusNotFound, "user not found")
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, "could not update timezone")
+		}
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, user.ToUserDTO(u))
+}
+
+// DELETE /me
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	at, err := h.svc.ScheduleDeletion(r.Context(), userID)
+	if err != nil {
+		fmt.Println(err)
+		if errors.Is(err, user.ErrUserNotFound) {
+			utils.WriteError(w, http.StatusNotFound, "user not found")
+			return
+		}
+		utils.WriteError(w, http.StatusInternalServerError, "could not delete account")
+		return
+	}
+
+	// token ถูก revoke หมดแล้ว ลบ cookie ฝั่ง browser ด้วย
+	http.SetCookie(w, &http.Cookie{
+		Name:     "refresh_token",
+		Value:    "",
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   h.isProd,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   -1,
+	})
+
+	utils.WriteJSON(w, http.StatusAccepted, map[string]any{
+		"message":               "account scheduled for deletion, log in again before the deadline to cancel",
+		"deletion_scheduled_at": at,
+	})
+}
+
+// GET /me/export
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	data, err := h.svc.Export(r.Context(), userID)
+	if err != nil {
+		fmt.Println(err)
+		if errors.Is(err, user.ErrUserNotFound) {
+			utils.WriteError(w, http.StatusNotFound, "user not found")
+			return
+		}
+		utils.WriteError(w, http.StatusInternalServerError, "could not export account data")
+		return
+	}
+
+	filename := fmt.Sprintf("todo-export-%d-%s.zip", userID, data.ExportedAt.Format("20060102"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.WriteHeader(http.StatusOK)
+
+	// แยกไฟล์ละหมวด อ่านง่ายกว่า JSON ก้อนเดียว
+	files := []struct {
+		name string
+		body any
+	}{
+		{"profile.json", data.Profile},
+		{"todo_groups.json", data.Groups},
+		{"todos.json", data.Todos},
+	}
+
+	zw := zip.NewWriter(w)
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		enc := json.NewEncoder(fw)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.body); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		fmt.Println(err)
+	}
+}
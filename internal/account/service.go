@@ -0,0 +1,159 @@
+package account
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/Nasaee/go-todo-backend/internal/auth"
+	"github.com/Nasaee/go-todo-backend/internal/todo"
+	"github.com/Nasaee/go-todo-backend/internal/todogroup"
+	"github.com/Nasaee/go-todo-backend/internal/user"
+)
+
+// Export คือข้อมูลทั้งหมดของ user ที่ให้ download ออกไปได้
+type Export struct {
+	ExportedAt time.Time             `json:"exported_at"`
+	Profile    *user.User            `json:"profile"`
+	Groups     []todogroup.TodoGroup `json:"todo_groups"`
+	Todos      []todo.Todo           `json:"todos"`
+}
+
+type Service interface {
+	Profile(ctx context.Context, userID int64) (*user.User, error)
+	SetAvatar(ctx context.Context, userID int64, r io.Reader) (*user.User, error)
+	SetTimezone(ctx context.Context, userID int64, tz string) (*user.User, error)
+	ScheduleDeletion(ctx context.Context, userID int64) (time.Time, error)
+	Export(ctx context.Context, userID int64) (*Export, error)
+	PurgeDue(ctx context.Context) (int64, error)
+}
+
+type service struct {
+	users  user.UserService
+	tokens auth.TokenService
+	groups todogroup.TodoGroupService
+	todos  todo.Service
+	grace  time.Duration
+}
+
+func NewService(
+	users user.UserService,
+	tokens auth.TokenService,
+	groups todogroup.TodoGroupService,
+	todos todo.Service,
+	grace time.Duration,
+) Service {
+	return &service{
+		users:  users,
+		tokens: tokens,
+		groups: groups,
+		todos:  todos,
+		grace:  grace,
+	}
+}
+
+func (s *service) Profile(ctx context.Context, userID int64) (*user.User, error) {
+	return s.users.GetByID(ctx, userID)
+}
+
+func (s *service) SetAvatar(ctx context.Context, userID int64, r io.Reader) (*user.User, error) {
+	return s.users.SetAvatar(ctx, userID, r)
+}
+
+func (s *service) SetTimezone(ctx context.Context, userID int64, tz string) (*user.User, error) {
+	return s.users.SetTimezone(ctx, userID, tz)
+}
+
+// ScheduleDeletion ตั้งเวลาลบ account หลัง grace period แล้ว revoke token ทั้งหมดทันที
+// ถ้า user login กลับมาก่อนครบกำหนด การลบจะถูกยกเลิก (ดู user.Authenticate)
+func (s *service) ScheduleDeletion(ctx context.Context, userID int64) (time.Time, error) {
+	at, err := s.users.ScheduleDeletion(ctx, userID, s.grace)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if err := s.tokens.RevokeAll(ctx, userID); err != nil {
+		return time.Time{}, err
+	}
+
+	return at, nil
+}
+
+func (s *service) Export(ctx context.Context, userID int64) (*Export, error) {
+	u, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// export ทุกอย่าง รวม group ที่ archive ไปแล้ว (todos รวมที่เสร็จแล้วด้วย)
+	groups, err := s.groups.GetAll(ctx, userID, todogroup.ListOptions{IncludeArchived: true})
+	if err != nil {
+		return nil, err
+	}
+
+	// list todos คืนทีละหน้า ไล่ cursor จนครบ
+	var todos []todo.Todo
+	q := todo.ListQuery{
+		ListOptions: todo.ListOptions{IncludeArchived: true, IncludeCompleted: true},
+		Limit:       todo.MaxListLimit,
+	}
+	for {
+		page, err := s.todos.ListTodos(ctx, userID, q)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, page.Items...)
+		if page.NextCursor == nil {
+			break
+		}
+		q.Cursor = *page.NextCursor
+	}
+
+	// list ปกติรวม group / todos ที่คนอื่นแชร์มาด้วย export เอาเฉพาะของตัวเอง
+	own := groups[:0]
+	for _, g := range groups {
+		if g.UserID == userID {
+			own = append(own, g)
+		}
+	}
+
+	ownTodos := todos[:0]
+	for _, t := range todos {
+		if t.UserID == userID {
+			ownTodos = append(ownTodos, t)
+		}
+	}
+
+	return &Export{
+		ExportedAt: time.Now().UTC(),
+		Profile:    u,
+		Groups:     own,
+		Todos:      ownTodos,
+	}, nil
+}
+
+func (s *service) PurgeDue(ctx context.Context) (int64, error) {
+	return s.users.PurgeScheduledDeletions(ctx)
+}
+
+// RunPurger ลบ account ที่ครบ grace period แล้วทุก ๆ interval จนกว่า ctx จะถูก cancel
+func RunPurger(ctx context.Context, svc Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := svc.PurgeDue(ctx)
+		if err != nil {
+			slog.Error("failed to purge deleted accounts", "error", err)
+		} else if n > 0 {
+			slog.Info("purged deleted accounts", "count", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Package audit บันทึกเหตุการณ์ที่ต้องตรวจย้อนหลังได้ (ใครทำอะไร กับอะไร จากที่ไหน)
+package audit
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Nasaee/go-todo-backend/pkg/utils"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Client ข้อมูลฝั่งคนเรียก แยกออกมาให้ service รับไปได้โดยไม่ต้องรู้จัก *http.Request
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+func ClientFromRequest(r *http.Request) Client {
+	return Client{
+		IP:        utils.ClientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+}
+
+type Entry struct {
+	ActorID      *int64 // nil = ไม่ได้ login
+	Action       string // เช่น share_link.created
+	ResourceType string // เช่น share_link
+	ResourceID   *int64
+	Client       Client
+	Metadata     map[string]any
+}
+
+type Logger interface {
+	Record(ctx context.Context, e Entry) error
+}
+
+type postgresLogger struct {
+	db *pgxpool.Pool
+}
+
+func NewLogger(db *pgxpool.Pool) Logger {
+	return &postgresLogger{db: db}
+}
+
+func (l *postgresLogger) Record(ctx context.Context, e Entry) error {
+	query := `
+		INSERT INTO audit_logs (actor_user_id, action, resource_type, resource_id, ip, user_agent, metadata)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	metadata := e.Metadata
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+
+	_, err := l.db.Exec(ctx, query,
+		e.ActorID,
+		e.Action,
+		e.ResourceType,
+		e.ResourceID,
+		e.Client.IP,
+		e.Client.UserAgent,
+		metadata,
+	)
+	return err
+}
@@ -28,6 +28,35 @@ func UserIDFromContext(ctx context.Context) (int64, bool) {
 	return id, ok
 }
 
+// ---- workspace ที่ request นี้ทำงานอยู่ (route /workspaces/{wid}/...) ----
+
+const ctxKeyWorkspaceID ctxKey = "workspaceID"
+
+func ContextWithWorkspaceID(ctx context.Context, workspaceID int64) context.Context {
+	return context.WithValue(ctx, ctxKeyWorkspaceID, workspaceID)
+}
+
+// WorkspaceIDFromContext ok = false คือ route เดิมที่ไม่ได้ระบุ workspace (เห็นข้อมูลทุก workspace)
+func WorkspaceIDFromContext(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(ctxKeyWorkspaceID).(int64)
+	return id, ok
+}
+
+// InWorkspace ของที่อยู่ workspace workspaceID มองเห็นได้ใน request นี้ไหม
+func InWorkspace(ctx context.Context, workspaceID int64) bool {
+	id, ok := WorkspaceIDFromContext(ctx)
+	return !ok || id == workspaceID
+}
+
+// WorkspaceScope คืน workspace ของ request เป็น pointer (nil = ไม่ได้ระบุ) ไว้ส่งต่อเป็น filter ของ query
+func WorkspaceScope(ctx context.Context) *int64 {
+	id, ok := WorkspaceIDFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return &id
+}
+
 // ---- AuthMiddleware สำหรับตรวจ access token ----
 
 func AuthMiddleware(ts TokenService) func(http.Handler) http.Handler {
@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 	"errors"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -12,6 +13,9 @@ import (
 
 type Claims struct {
 	UserID int64 `json:"uid"`
+	// IssuedAtMicro เวลาออก token ละเอียดระดับ microsecond (iat ของ JWT ละเอียดแค่วินาที
+	// token ที่ออกในวินาทีเดียวกับ RevokeAll จึงแยกไม่ได้ว่าออกก่อนหรือหลัง)
+	IssuedAtMicro int64 `json:"iat_us"`
 	jwt.RegisteredClaims
 }
 
@@ -26,6 +30,7 @@ type TokenService interface {
 	GenerateTokens(ctx context.Context, userID int64) (accessToken, refreshToken string, accessExpiresAt int64, err error)
 	ParseAccessToken(ctx context.Context, tokenStr string) (*Claims, error)
 	RefreshTokens(ctx context.Context, refreshToken string) (string, string, int64, error)
+	RevokeAll(ctx context.Context, userID int64) error
 }
 
 type tokenService struct {
@@ -74,7 +79,8 @@ func (s *tokenService) GenerateTokens(ctx context.Context, userID int64) (string
 
 	// ----- access token -----
 	accessClaims := &Claims{
-		UserID: userID,
+		UserID:        userID,
+		IssuedAtMicro: now.UnixMicro(),
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(now.Add(s.accessTTL)),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -108,13 +114,22 @@ func (s *tokenService) GenerateTokens(ctx context.Context, userID int64) (string
 		return "", "", 0, err
 	}
 
+	// เก็บ jti ทั้งหมดของ user ไว้ใน set ด้วย เพื่อให้ RevokeAll ลบได้ครบ
+	userKey := userRefreshKey(userID)
+	if err := s.redis.SAdd(ctx, userKey, jti).Err(); err != nil {
+		return "", "", 0, err
+	}
+	if err := s.redis.Expire(ctx, userKey, s.refreshTTL).Err(); err != nil {
+		return "", "", 0, err
+	}
+
 	expiresAt := accessExpiresAt.Unix()
 
 	return accessToken, refreshToken, expiresAt, nil
 }
 
 func (s *tokenService) ParseAccessToken(ctx context.Context, tokenStr string) (*Claims, error) {
-	// ตอนนี้ยังไม่ได้ใช้ ctx ข้างใน แต่รับมาไว้ก่อน เผื่ออนาคตอยากเช็ค blacklist ใน Redis เพิ่ม
+	// ใช้ ctx ตอนเช็ค revoke marker ใน Redis (ดู RevokeAll)
 	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(t *jwt.Token) (any, error) {
 		// กัน alg แปลก ๆ
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -135,6 +150,15 @@ func (s *tokenService) ParseAccessToken(ctx context.Context, tokenStr string) (*
 		return nil, ErrInvalidAccessToken
 	}
 
+	// access token ที่ออกก่อน RevokeAll ถือว่าใช้ไม่ได้แล้ว
+	revokedAt, err := s.redis.Get(ctx, revokedBeforeKey(claims.UserID)).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	if err == nil && claims.IssuedAtMicro <= revokedAt {
+		return nil, ErrInvalidAccessToken
+	}
+
 	return claims, nil
 }
 
@@ -173,6 +197,7 @@ func (s *tokenService) RefreshTokens(ctx context.Context, refreshToken string) (
 
 	// single-use: ลบ jti เดิมออก
 	_ = s.redis.Del(ctx, key).Err()
+	_ = s.redis.SRem(ctx, userRefreshKey(claims.UserID), claims.ID).Err()
 
 	// 3) ออก access + refresh ใหม่ ด้วย userID เดิม
 	newAccess, newRefresh, accessExp, err := s.GenerateTokens(ctx, claims.UserID)
@@ -182,3 +207,35 @@ func (s *tokenService) RefreshTokens(ctx context.Context, refreshToken string) (
 
 	return newAccess, newRefresh, accessExp, nil
 }
+
+// RevokeAll ลบ refresh token ทุกตัวของ user และทำให้ access token ที่ออกไปแล้วใช้ไม่ได้
+func (s *tokenService) RevokeAll(ctx context.Context, userID int64) error {
+	userKey := userRefreshKey(userID)
+
+	jtis, err := s.redis.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(jtis)+1)
+	for _, jti := range jtis {
+		keys = append(keys, "refresh:"+jti)
+	}
+	keys = append(keys, userKey)
+
+	if err := s.redis.Del(ctx, keys...).Err(); err != nil {
+		return err
+	}
+
+	// access token อยู่ได้ไม่เกิน accessTTL จึงเก็บ marker ไว้แค่นั้นพอ
+	// เก็บเป็น microsecond ให้ login ใหม่ทันทีหลัง revoke (วินาทีเดียวกัน) ยังใช้ได้
+	return s.redis.Set(ctx, revokedBeforeKey(userID), time.Now().UnixMicro(), s.accessTTL).Err()
+}
+
+func userRefreshKey(userID int64) string {
+	return "user_refresh:" + strconv.FormatInt(userID, 10)
+}
+
+func revokedBeforeKey(userID int64) string {
+	return "revoked_before:" + strconv.FormatInt(userID, 10)
+}
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- เวลาที่ account จะถูกลบจริง (NULL = ไม่ได้ขอลบ)
+ALTER TABLE users
+ADD COLUMN deletion_scheduled_at TIMESTAMPTZ;
+
+-- index เฉพาะแถวที่รอลบ ไว้ให้ job purge หาเจอเร็ว ๆ
+CREATE INDEX IF NOT EXISTS idx_users_deletion_scheduled_at
+ON users(deletion_scheduled_at)
+WHERE deletion_scheduled_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_users_deletion_scheduled_at;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS deletion_scheduled_at;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- prefix ของไฟล์ avatar ใน blob storage เช่น "avatars/1/<version>" (NULL = ไม่มีรูป)
+ALTER TABLE users
+ADD COLUMN avatar_key VARCHAR(255);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+DROP COLUMN IF EXISTS avatar_key;
+-- +goose StatementEnd
@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+-- todo ที่ผูกกับ group ของ user คนอื่น (เกิดได้ก่อนมีการเช็ค ownership) ต้องเคลียร์ก่อน
+-- ไม่งั้น constraint ด้านล่างจะสร้างไม่ผ่าน: ให้เป็นของเจ้าของ group ไปเลย (ไม่ลบข้อมูลทิ้ง)
+UPDATE todos t
+SET user_id = g.user_id
+FROM todo_groups g
+WHERE t.todo_group_id = g.id
+  AND t.user_id <> g.user_id;
+
+-- ต้องมี unique (id, user_id) ก่อนถึงจะใช้เป็นปลายทางของ composite FK ได้
+ALTER TABLE todo_groups
+ADD CONSTRAINT uq_todo_groups_id_user_id UNIQUE (id, user_id);
+
+-- เปลี่ยน FK เดิม (todo_group_id อย่างเดียว) เป็น (todo_group_id, user_id)
+-- = todos.user_id ต้องตรงกับเจ้าของ group เสมอ
+ALTER TABLE todos
+DROP CONSTRAINT IF EXISTS todos_todo_group_id_fkey;
+
+ALTER TABLE todos
+ADD CONSTRAINT fk_todos_todo_group_owner
+FOREIGN KEY (todo_group_id, user_id)
+REFERENCES todo_groups(id, user_id)
+ON DELETE CASCADE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE todos
+DROP CONSTRAINT IF EXISTS fk_todos_todo_group_owner;
+
+ALTER TABLE todos
+ADD CONSTRAINT todos_todo_group_id_fkey
+FOREIGN KEY (todo_group_id)
+REFERENCES todo_groups(id)
+ON DELETE CASCADE;
+
+ALTER TABLE todo_groups
+DROP CONSTRAINT IF EXISTS uq_todo_groups_id_user_id;
+-- +goose StatementEnd
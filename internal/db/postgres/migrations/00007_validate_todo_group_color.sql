@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+-- ทำข้อมูลเก่าให้อยู่รูปเดียวกับที่ service เก็บ (#rrggbb ตัวพิมพ์เล็ก) ก่อนใส่ CHECK
+UPDATE todo_groups
+SET color = LOWER(TRIM(color))
+WHERE color <> LOWER(TRIM(color));
+
+-- #rgb -> #rrggbb
+UPDATE todo_groups
+SET color = '#'
+    || REPEAT(SUBSTRING(color FROM 2 FOR 1), 2)
+    || REPEAT(SUBSTRING(color FROM 3 FOR 1), 2)
+    || REPEAT(SUBSTRING(color FROM 4 FOR 1), 2)
+WHERE color ~ '^#[0-9a-f]{3}$';
+
+-- ชื่อสีใน palette ("red") -> hex ของสีนั้น (ตรงกับ todogroup.Palette)
+UPDATE todo_groups g
+SET color = p.hex
+FROM (VALUES
+    ('blue', '#1c7ed6'),
+    ('cyan', '#1098ad'),
+    ('teal', '#0ca678'),
+    ('green', '#37b24d'),
+    ('lime', '#74b816'),
+    ('yellow', '#f59f00'),
+    ('orange', '#f76707'),
+    ('red', '#f03e3e'),
+    ('pink', '#d6336c'),
+    ('grape', '#ae3ec9'),
+    ('violet', '#7048e8'),
+    ('indigo', '#4263eb'),
+    ('gray', '#495057')
+) AS p(key, hex)
+WHERE g.color = p.key;
+
+-- ค่าที่ใช้ไม่ได้ (ว่าง / ชื่อสีอื่นที่ไม่อยู่ใน palette) กลับไปเป็นสี default
+UPDATE todo_groups
+SET color = '#1c7ed6'
+WHERE color !~ '^#[0-9a-f]{6}$';
+
+ALTER TABLE todo_groups
+ADD CONSTRAINT chk_todo_groups_color_hex CHECK (color ~ '^#[0-9a-f]{6}$');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE todo_groups
+DROP CONSTRAINT IF EXISTS chk_todo_groups_color_hex;
+-- +goose StatementEnd
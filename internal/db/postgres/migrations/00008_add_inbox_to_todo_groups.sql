@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- inbox = group ตั้งต้นของ user (สร้างตอน register, ลบไม่ได้)
+ALTER TABLE todo_groups
+ADD COLUMN is_inbox BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- user หนึ่งคนมี inbox ได้อันเดียว
+CREATE UNIQUE INDEX IF NOT EXISTS idx_todo_groups_user_inbox
+ON todo_groups(user_id)
+WHERE is_inbox;
+
+-- user เก่าที่ยังไม่มี inbox สร้างให้
+INSERT INTO todo_groups (name, user_id, is_inbox)
+SELECT 'Inbox', u.id, TRUE
+FROM users u
+WHERE NOT EXISTS (
+    SELECT 1 FROM todo_groups g WHERE g.user_id = u.id AND g.is_inbox
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_todo_groups_user_inbox;
+
+ALTER TABLE todo_groups
+DROP COLUMN IF EXISTS is_inbox;
+-- +goose StatementEnd
@@ -0,0 +1,73 @@
+-- +goose Up
+-- +goose StatementBegin
+-- position = key สำหรับเรียงลำดับเอง (fractional index, ดู pkg/rank)
+-- ต้องเทียบแบบ byte order จึงใช้ COLLATE "C"
+ALTER TABLE todo_groups
+ADD COLUMN position TEXT COLLATE "C";
+
+ALTER TABLE todos
+ADD COLUMN position TEXT COLLATE "C";
+
+-- key ลำดับที่ n (เริ่มจาก 0) แบบเดียวกับที่ rank.After สร้างต่อ ๆ กัน: a0..az, b00..bzz, c000...
+CREATE FUNCTION pg_temp.rank_key(n BIGINT) RETURNS TEXT AS $$
+DECLARE
+    digits CONSTANT TEXT := '0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz';
+    digit_count INT := 1;
+    cap BIGINT := 62;
+    result TEXT := '';
+BEGIN
+    WHILE n >= cap LOOP
+        n := n - cap;
+        digit_count := digit_count + 1;
+        cap := cap * 62;
+    END LOOP;
+
+    FOR i IN 1..digit_count LOOP
+        result := SUBSTRING(digits FROM (n % 62)::INT + 1 FOR 1) || result;
+        n := n / 62;
+    END LOOP;
+
+    RETURN CHR(ASCII('a') + digit_count - 1) || result;
+END;
+$$ LANGUAGE plpgsql;
+
+-- ข้อมูลเก่าเรียงตามลำดับที่ API เคยคืนไป (inbox ก่อน แล้วตามชื่อ)
+UPDATE todo_groups g
+SET position = pg_temp.rank_key(o.rn - 1)
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY is_inbox DESC, name, id) AS rn
+    FROM todo_groups
+) o
+WHERE g.id = o.id;
+
+-- todos เรียงตาม date_start เหมือน GET /todos เดิม
+UPDATE todos t
+SET position = pg_temp.rank_key(o.rn - 1)
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY date_start, id) AS rn
+    FROM todos
+) o
+WHERE t.id = o.id;
+
+ALTER TABLE todo_groups
+ALTER COLUMN position SET NOT NULL;
+
+ALTER TABLE todos
+ALTER COLUMN position SET NOT NULL;
+
+-- unique ต่อ user: key ไม่ซ้ำ = หา key ระหว่างสองตัวได้เสมอ + ใช้เป็น index สำหรับ ORDER BY
+CREATE UNIQUE INDEX IF NOT EXISTS idx_todo_groups_user_position ON todo_groups(user_id, position);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_todos_user_position ON todos(user_id, position);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_todos_user_position;
+DROP INDEX IF EXISTS idx_todo_groups_user_position;
+
+ALTER TABLE todos
+DROP COLUMN IF EXISTS position;
+
+ALTER TABLE todo_groups
+DROP COLUMN IF EXISTS position;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- archived_at ไม่ NULL = group ถูก archive (ซ่อนจาก list ปกติ แต่ข้อมูลยังอยู่)
+ALTER TABLE todo_groups
+ADD COLUMN archived_at TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE todo_groups
+DROP COLUMN IF EXISTS archived_at;
+-- +goose StatementEnd
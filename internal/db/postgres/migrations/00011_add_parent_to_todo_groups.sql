@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- group ซ้อนกันได้ (Area > Project ...) parent ต้องเป็นของ user คนเดียวกัน จึงใช้ composite FK
+-- ลบ parent แล้ว group ลูกขึ้นมาอยู่ชั้นบนสุดแทน (SET NULL เฉพาะ parent_id, ต้องใช้ Postgres 15+)
+-- กันวน (cycle) และจำกัดความลึกที่ service/repository
+ALTER TABLE todo_groups
+ADD COLUMN parent_id BIGINT;
+
+ALTER TABLE todo_groups
+ADD CONSTRAINT fk_todo_groups_parent
+FOREIGN KEY (parent_id, user_id)
+REFERENCES todo_groups(id, user_id)
+ON DELETE SET NULL (parent_id);
+
+ALTER TABLE todo_groups
+ADD CONSTRAINT chk_todo_groups_parent_not_self CHECK (parent_id <> id);
+
+CREATE INDEX IF NOT EXISTS idx_todo_groups_parent_id ON todo_groups(parent_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_todo_groups_parent_id;
+
+ALTER TABLE todo_groups
+DROP CONSTRAINT IF EXISTS chk_todo_groups_parent_not_self;
+
+ALTER TABLE todo_groups
+DROP CONSTRAINT IF EXISTS fk_todo_groups_parent;
+
+ALTER TABLE todo_groups
+DROP COLUMN IF EXISTS parent_id;
+-- +goose StatementEnd
@@ -0,0 +1,77 @@
+-- +goose Up
+-- +goose StatementBegin
+-- =========================
+-- todo_group_members
+--   แชร์ group ให้ user อื่น (owner / editor / viewer)
+--   todo_groups.user_id ยังเป็นเจ้าของเหมือนเดิม แต่สิทธิ์มองเห็น/แก้ไขดูจากตารางนี้
+-- =========================
+CREATE TABLE IF NOT EXISTS todo_group_members (
+    todo_group_id BIGINT NOT NULL REFERENCES todo_groups(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (todo_group_id, user_id)
+);
+
+-- query "group ที่ user เห็น" วิ่งจาก user_id
+CREATE INDEX IF NOT EXISTS idx_todo_group_members_user_id ON todo_group_members(user_id);
+
+-- owner มีได้คนเดียวต่อ group
+CREATE UNIQUE INDEX IF NOT EXISTS idx_todo_group_members_owner
+ON todo_group_members(todo_group_id) WHERE role = 'owner';
+
+-- เจ้าของ group ทุกอันเป็นสมาชิก role owner (ของเดิม backfill, ของใหม่ให้ trigger ใส่ให้)
+INSERT INTO todo_group_members (todo_group_id, user_id, role)
+SELECT id, user_id, 'owner'
+FROM todo_groups
+ON CONFLICT DO NOTHING;
+
+CREATE OR REPLACE FUNCTION add_todo_group_owner_member()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO todo_group_members (todo_group_id, user_id, role)
+    VALUES (NEW.id, NEW.user_id, 'owner');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_todo_group_owner_member
+AFTER INSERT ON todo_groups
+FOR EACH ROW
+EXECUTE FUNCTION add_todo_group_owner_member();
+
+-- =========================
+-- todo_group_invitations
+--   เชิญด้วย email (ยังไม่ต้องมี account ก็เชิญได้) ผู้ถูกเชิญ accept / decline เอง
+--   รับคำเชิญต้องใช้ token ที่ส่งไปทาง email (เก็บแค่ hash) ไม่ใช่แค่ email ของ account ตรงกัน
+--   เพราะสมัครด้วย email ของคนอื่นได้โดยไม่ต้องยืนยัน
+-- =========================
+CREATE TABLE IF NOT EXISTS todo_group_invitations (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    todo_group_id BIGINT NOT NULL REFERENCES todo_groups(id) ON DELETE CASCADE,
+    email VARCHAR(150) NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('editor', 'viewer')),
+    invited_by BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash BYTEA NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined', 'revoked')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    responded_at TIMESTAMPTZ
+);
+
+-- เชิญ email เดิมซ้ำไม่ได้ ถ้ายังมีคำเชิญค้างอยู่
+CREATE UNIQUE INDEX IF NOT EXISTS idx_todo_group_invitations_pending
+ON todo_group_invitations(todo_group_id, LOWER(email)) WHERE status = 'pending';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_todo_group_invitations_token_hash
+ON todo_group_invitations(token_hash);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS todo_group_invitations;
+
+DROP TRIGGER IF EXISTS trigger_todo_group_owner_member ON todo_groups;
+DROP FUNCTION IF EXISTS add_todo_group_owner_member();
+
+DROP TABLE IF EXISTS todo_group_members;
+-- +goose StatementEnd
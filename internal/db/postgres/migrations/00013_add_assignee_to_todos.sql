@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- คนรับผิดชอบ todo ต้องเป็นสมาชิกของ group ที่ todo อยู่ (FK ไปที่ todo_group_members)
+-- โดนเอาออกจาก group เมื่อไหร่ assignment หลุดเอง (SET NULL เฉพาะ assignee_id)
+ALTER TABLE todos
+ADD COLUMN assignee_id BIGINT;
+
+ALTER TABLE todos
+ADD CONSTRAINT fk_todos_assignee_member
+FOREIGN KEY (todo_group_id, assignee_id)
+REFERENCES todo_group_members(todo_group_id, user_id)
+ON DELETE SET NULL (assignee_id);
+
+-- GET /todos/assigned-to-me
+CREATE INDEX IF NOT EXISTS idx_todos_assignee_id ON todos(assignee_id) WHERE assignee_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_todos_assignee_id;
+
+ALTER TABLE todos
+DROP CONSTRAINT IF EXISTS fk_todos_assignee_member;
+
+ALTER TABLE todos
+DROP COLUMN IF EXISTS assignee_id;
+-- +goose StatementEnd
@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+-- =========================
+-- share_links
+--   ลิงก์สาธารณะ (read-only) ของ group เก็บแค่ hash ของ token ตัวจริงโชว์ครั้งเดียวตอนสร้าง
+-- =========================
+CREATE TABLE IF NOT EXISTS share_links (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    todo_group_id BIGINT NOT NULL REFERENCES todo_groups(id) ON DELETE CASCADE,
+    token_hash BYTEA NOT NULL,
+    created_by BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_share_links_token_hash ON share_links(token_hash);
+CREATE INDEX IF NOT EXISTS idx_share_links_todo_group_id ON share_links(todo_group_id);
+
+-- =========================
+-- audit_logs
+--   ใครทำอะไรกับอะไร (actor = NULL คือคนที่ไม่ได้ login เช่นเปิด share link)
+--   ไม่ผูก FK กับ resource เพราะ log ต้องอยู่ต่อแม้ของจะถูกลบไปแล้ว
+-- =========================
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    actor_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(100) NOT NULL,
+    resource_type VARCHAR(50) NOT NULL,
+    resource_id BIGINT,
+    ip VARCHAR(64),
+    user_agent TEXT,
+    metadata JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_resource ON audit_logs(resource_type, resource_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor ON audit_logs(actor_user_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS audit_logs;
+DROP TABLE IF EXISTS share_links;
+-- +goose StatementEnd
@@ -0,0 +1,261 @@
+-- +goose Up
+-- +goose StatementBegin
+-- =========================
+-- workspaces
+--   ชั้นบนสุดของข้อมูล (tenant) group / todos ทุกอันอยู่ใน workspace เดียว
+--   personal = ของ user คนเดียว (สร้างตอน register, ลบไม่ได้), team = มีสมาชิกหลายคน
+-- =========================
+CREATE TABLE IF NOT EXISTS workspaces (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    name VARCHAR(200) NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('personal', 'team')),
+    -- มีค่าเฉพาะ personal workspace (ลบ user แล้ว workspace + ข้อมูลข้างในหายตาม)
+    personal_user_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_workspaces_personal_user CHECK ((kind = 'personal') = (personal_user_id IS NOT NULL))
+);
+
+-- user หนึ่งคนมี personal workspace ได้อันเดียว
+CREATE UNIQUE INDEX IF NOT EXISTS idx_workspaces_personal_user
+ON workspaces(personal_user_id)
+WHERE personal_user_id IS NOT NULL;
+
+CREATE TRIGGER trigger_update_timestamp_workspaces
+BEFORE UPDATE ON workspaces
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- =========================
+-- workspace_members
+--   owner  = ลบ workspace ได้ (มีได้คนเดียว)
+--   admin  = แก้ชื่อ / จัดการสมาชิก
+--   member = เห็นและแก้ todos ในทุก group ของ workspace (เหมือน editor)
+--   viewer = เห็นทุก group ของ workspace แต่แก้ไม่ได้
+-- =========================
+CREATE TABLE IF NOT EXISTS workspace_members (
+    workspace_id BIGINT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'admin', 'member', 'viewer')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_workspace_members_user_id ON workspace_members(user_id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_workspace_members_owner
+ON workspace_members(workspace_id) WHERE role = 'owner';
+
+-- =========================
+-- workspace_invitations
+--   เข้า team workspace ได้ทางคำเชิญเท่านั้น (เหมือน todo_group_invitations)
+--   รับคำเชิญต้องใช้ token ที่ส่งไปทาง email (เก็บแค่ hash) email ของ account ไม่ได้ยืนยันจึงใช้จับคู่ไม่ได้
+-- =========================
+CREATE TABLE IF NOT EXISTS workspace_invitations (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    workspace_id BIGINT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    email VARCHAR(150) NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('admin', 'member', 'viewer')),
+    invited_by BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash BYTEA NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined', 'revoked')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    responded_at TIMESTAMPTZ
+);
+
+-- เชิญ email เดิมซ้ำไม่ได้ ถ้ายังมีคำเชิญค้างอยู่
+CREATE UNIQUE INDEX IF NOT EXISTS idx_workspace_invitations_pending
+ON workspace_invitations(workspace_id, LOWER(email)) WHERE status = 'pending';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_workspace_invitations_token_hash
+ON workspace_invitations(token_hash);
+
+-- user เดิมทุกคนได้ personal workspace + เป็น owner
+INSERT INTO workspaces (name, kind, personal_user_id)
+SELECT 'Personal', 'personal', u.id
+FROM users u
+WHERE NOT EXISTS (SELECT 1 FROM workspaces w WHERE w.personal_user_id = u.id);
+
+INSERT INTO workspace_members (workspace_id, user_id, role)
+SELECT id, personal_user_id, 'owner'
+FROM workspaces
+WHERE kind = 'personal'
+ON CONFLICT DO NOTHING;
+
+-- =========================
+-- todo_groups.workspace_id
+--   ข้อมูลเดิมย้ายเข้า personal workspace ของเจ้าของ group
+--   parent ต้องอยู่ workspace เดียวกัน (composite FK เหมือน fk_todo_groups_parent)
+-- =========================
+ALTER TABLE todo_groups
+ADD COLUMN workspace_id BIGINT REFERENCES workspaces(id) ON DELETE CASCADE;
+
+UPDATE todo_groups g
+SET workspace_id = w.id
+FROM workspaces w
+WHERE w.personal_user_id = g.user_id;
+
+ALTER TABLE todo_groups
+ALTER COLUMN workspace_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_todo_groups_workspace_id ON todo_groups(workspace_id);
+
+ALTER TABLE todo_groups
+ADD CONSTRAINT uq_todo_groups_id_workspace UNIQUE (id, workspace_id);
+
+ALTER TABLE todo_groups
+ADD CONSTRAINT fk_todo_groups_parent_workspace
+FOREIGN KEY (parent_id, workspace_id)
+REFERENCES todo_groups(id, workspace_id)
+ON DELETE SET NULL (parent_id);
+
+-- =========================
+-- todos.workspace_id
+--   ตามหลัง group เสมอ ให้ trigger เซ็ตเองทุกครั้งที่สร้าง / ย้าย group
+-- =========================
+ALTER TABLE todos
+ADD COLUMN workspace_id BIGINT REFERENCES workspaces(id) ON DELETE CASCADE;
+
+UPDATE todos t
+SET workspace_id = g.workspace_id
+FROM todo_groups g
+WHERE g.id = t.todo_group_id;
+
+ALTER TABLE todos
+ALTER COLUMN workspace_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_todos_workspace_id ON todos(workspace_id);
+
+CREATE OR REPLACE FUNCTION set_todo_workspace()
+RETURNS TRIGGER AS $$
+BEGIN
+    SELECT workspace_id INTO NEW.workspace_id
+    FROM todo_groups
+    WHERE id = NEW.todo_group_id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_set_todo_workspace
+BEFORE INSERT OR UPDATE OF todo_group_id ON todos
+FOR EACH ROW
+EXECUTE FUNCTION set_todo_workspace();
+
+-- =========================
+-- todo_group_access
+--   สิทธิ์ที่ user มีต่อ group = สมาชิกของ group โดยตรง (แชร์มา)
+--   หรือเป็นสมาชิกของ team workspace ที่ group อยู่ (viewer = viewer, อื่น ๆ = editor)
+--   จัดการ group (แก้ชื่อ / ลบ / แชร์) ยังเป็นของเจ้าของ group คนเดียว
+-- =========================
+CREATE OR REPLACE VIEW todo_group_access AS
+SELECT m.todo_group_id, m.user_id, m.role
+FROM todo_group_members m
+UNION ALL
+SELECT g.id, wm.user_id, CASE WHEN wm.role = 'viewer' THEN 'viewer' ELSE 'editor' END
+FROM todo_groups g
+JOIN workspaces w ON w.id = g.workspace_id AND w.kind = 'team'
+JOIN workspace_members wm ON wm.workspace_id = g.workspace_id
+WHERE NOT EXISTS (
+    SELECT 1 FROM todo_group_members m
+    WHERE m.todo_group_id = g.id AND m.user_id = wm.user_id
+);
+
+-- =========================
+-- todos.assignee_id
+--   สมาชิก workspace เข้าถึง group ได้โดยไม่มีแถวใน todo_group_members จึงใช้ FK ไปที่ todo_group_members (00013) ไม่ได้แล้ว
+--   service เช็คสิทธิ์จาก todo_group_access ตอน assign ส่วนตอนสิทธิ์หายไปให้ trigger เอา assignment ออกแทน
+-- =========================
+ALTER TABLE todos
+DROP CONSTRAINT IF EXISTS fk_todos_assignee_member;
+
+ALTER TABLE todos
+ADD CONSTRAINT fk_todos_assignee
+FOREIGN KEY (assignee_id)
+REFERENCES users(id)
+ON DELETE SET NULL;
+
+-- ถูกเอาออกจาก group: todo ใน group นั้นที่คนนี้รับผิดชอบอยู่หลุด (ถ้ายังเข้าถึงผ่าน workspace ได้ก็ยังอยู่)
+CREATE OR REPLACE FUNCTION clear_group_member_assignments()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE todos t
+    SET assignee_id = NULL
+    WHERE t.todo_group_id = OLD.todo_group_id
+      AND t.assignee_id = OLD.user_id
+      AND NOT EXISTS (
+          SELECT 1 FROM todo_group_access a
+          WHERE a.todo_group_id = t.todo_group_id AND a.user_id = t.assignee_id
+      );
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_clear_group_member_assignments
+AFTER DELETE ON todo_group_members
+FOR EACH ROW
+EXECUTE FUNCTION clear_group_member_assignments();
+
+-- ออกจาก workspace: todo ทุก group ใน workspace ที่คนนี้ไม่เหลือสิทธิ์แล้วหลุด (group ที่แชร์ให้ตรง ๆ ยังอยู่)
+CREATE OR REPLACE FUNCTION clear_workspace_member_assignments()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE todos t
+    SET assignee_id = NULL
+    WHERE t.workspace_id = OLD.workspace_id
+      AND t.assignee_id = OLD.user_id
+      AND NOT EXISTS (
+          SELECT 1 FROM todo_group_access a
+          WHERE a.todo_group_id = t.todo_group_id AND a.user_id = t.assignee_id
+      );
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_clear_workspace_member_assignments
+AFTER DELETE ON workspace_members
+FOR EACH ROW
+EXECUTE FUNCTION clear_workspace_member_assignments();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trigger_clear_workspace_member_assignments ON workspace_members;
+DROP FUNCTION IF EXISTS clear_workspace_member_assignments();
+DROP TRIGGER IF EXISTS trigger_clear_group_member_assignments ON todo_group_members;
+DROP FUNCTION IF EXISTS clear_group_member_assignments();
+
+-- assignee ที่เข้าถึงผ่าน workspace อย่างเดียวใส่กลับเข้า FK เดิมไม่ได้ เอาออกก่อน
+ALTER TABLE todos
+DROP CONSTRAINT IF EXISTS fk_todos_assignee;
+
+UPDATE todos t
+SET assignee_id = NULL
+WHERE t.assignee_id IS NOT NULL
+  AND NOT EXISTS (
+      SELECT 1 FROM todo_group_members m
+      WHERE m.todo_group_id = t.todo_group_id AND m.user_id = t.assignee_id
+  );
+
+ALTER TABLE todos
+ADD CONSTRAINT fk_todos_assignee_member
+FOREIGN KEY (todo_group_id, assignee_id)
+REFERENCES todo_group_members(todo_group_id, user_id)
+ON DELETE SET NULL (assignee_id);
+
+DROP VIEW IF EXISTS todo_group_access;
+
+DROP TRIGGER IF EXISTS trigger_set_todo_workspace ON todos;
+DROP FUNCTION IF EXISTS set_todo_workspace();
+
+DROP INDEX IF EXISTS idx_todos_workspace_id;
+ALTER TABLE todos DROP COLUMN IF EXISTS workspace_id;
+
+ALTER TABLE todo_groups DROP CONSTRAINT IF EXISTS fk_todo_groups_parent_workspace;
+ALTER TABLE todo_groups DROP CONSTRAINT IF EXISTS uq_todo_groups_id_workspace;
+DROP INDEX IF EXISTS idx_todo_groups_workspace_id;
+ALTER TABLE todo_groups DROP COLUMN IF EXISTS workspace_id;
+
+DROP TABLE IF EXISTS workspace_invitations;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+-- =========================
+-- templates
+--   ต้นแบบ group ที่สร้างซ้ำบ่อย ๆ (checklist ออก release, onboarding, จัดกระเป๋า)
+--   items = [{title, description, day_offset, duration_days}] วันเก็บเป็นระยะห่างจากวันเริ่ม ไม่ใช่วันจริง
+--   title / description ใส่ตัวแปร {{name}} ได้ แทนค่าตอนสร้าง group จาก template
+-- =========================
+CREATE TABLE IF NOT EXISTS templates (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(200) NOT NULL,
+    group_name VARCHAR(200) NOT NULL,
+    color VARCHAR(20) NOT NULL,
+    items JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_templates_user_id ON templates(user_id);
+
+CREATE TRIGGER trigger_update_timestamp_templates
+BEFORE UPDATE ON templates
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS templates;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- =========================
+-- full-text search ของ todos
+--   ใช้ config 'simple' (ไม่ตัด stem / stop word) เพราะมีทั้งไทยและอังกฤษปนกัน
+--   ภาษาไทยไม่เว้นวรรคระหว่างคำ tsvector จึงได้ทั้งประโยคเป็นคำเดียว
+--   -> มี trigram index บน title / description ไว้หาแบบ substring (ILIKE) ด้วย
+-- =========================
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- title สำคัญกว่า description (weight A > B)
+ALTER TABLE todos
+ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', COALESCE(title, '')), 'A') ||
+    setweight(to_tsvector('simple', COALESCE(description, '')), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_todos_search_vector ON todos USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_todos_title_trgm ON todos USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_todos_description_trgm ON todos USING GIN (description gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_todos_description_trgm;
+DROP INDEX IF EXISTS idx_todos_title_trgm;
+DROP INDEX IF EXISTS idx_todos_search_vector;
+
+ALTER TABLE todos
+DROP COLUMN IF EXISTS search_vector;
+-- extension ปล่อยไว้ อาจมีอย่างอื่นใช้อยู่
+-- +goose StatementEnd
@@ -0,0 +1,59 @@
+-- +goose Up
+-- +goose StatementBegin
+-- timezone ของ user (IANA เช่น Asia/Bangkok) ใช้คิดวันของ todo ที่เกิดซ้ำ
+ALTER TABLE users
+ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+-- =========================
+-- todo_series
+--   todo ที่เกิดซ้ำตาม RRULE (RFC 5545) มีแถวจริงทีละ occurrence
+--   ทำ occurrence ปัจจุบันเสร็จแล้วค่อยสร้างอันถัดไปจาก "ต้นแบบ" ในตารางนี้
+--   dtstart = วันแรกของ series (นับ COUNT จากวันนี้) วันคิดตาม timezone ของ series
+-- =========================
+CREATE TABLE IF NOT EXISTS todo_series (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    rrule TEXT NOT NULL,
+    timezone VARCHAR(64) NOT NULL,
+    dtstart DATE NOT NULL,
+    -- ต้นแบบของ occurrence ถัดไป
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    duration_days INT CHECK (duration_days >= 0), -- date_end - date_start (NULL = ไม่มี date_end)
+    todo_group_id BIGINT NOT NULL REFERENCES todo_groups(id) ON DELETE CASCADE,
+    assignee_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_series_todo_group_id ON todo_series(todo_group_id);
+
+CREATE TRIGGER trigger_update_timestamp_todo_series
+BEFORE UPDATE ON todo_series
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- occurrence_date = วันตาม rule ของ occurrence นี้ (เลื่อน date_start เฉพาะอันนี้ได้โดย slot ไม่เปลี่ยน)
+-- ลบ series ทิ้ง = todo ที่เคยสร้างไว้แล้วยังอยู่ แค่ไม่เกิดซ้ำต่อ
+ALTER TABLE todos
+ADD COLUMN series_id BIGINT REFERENCES todo_series(id) ON DELETE SET NULL,
+ADD COLUMN occurrence_date DATE;
+
+-- กันสร้าง occurrence เดียวกันซ้ำ (เช่นกดเสร็จพร้อมกันสองเครื่อง)
+CREATE UNIQUE INDEX IF NOT EXISTS uq_todos_series_occurrence
+ON todos(series_id, occurrence_date)
+WHERE series_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS uq_todos_series_occurrence;
+
+ALTER TABLE todos
+DROP COLUMN IF EXISTS occurrence_date,
+DROP COLUMN IF EXISTS series_id;
+
+DROP TABLE IF EXISTS todo_series;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS timezone;
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- =========================
+-- checklist_items
+--   ขั้นตอนย่อยของ todo (ชั้นเดียว ไม่มี checklist ซ้อน checklist)
+--   position เรียงภายใน todo เดียวกัน (fractional index, ดู pkg/rank)
+-- =========================
+CREATE TABLE IF NOT EXISTS checklist_items (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    todo_id BIGINT NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    is_done BOOLEAN NOT NULL DEFAULT false,
+    position TEXT COLLATE "C" NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_checklist_items_todo_position ON checklist_items(todo_id, position);
+
+CREATE TRIGGER trigger_update_timestamp_checklist_items
+BEFORE UPDATE ON checklist_items
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- auto_complete = ติ๊ก checklist ครบทุกข้อแล้ว todo เสร็จเอง
+ALTER TABLE todos
+ADD COLUMN auto_complete BOOLEAN NOT NULL DEFAULT false;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE todos
+DROP COLUMN IF EXISTS auto_complete;
+
+DROP TABLE IF EXISTS checklist_items;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- priority: 0 none, 1 low, 2 medium, 3 high, 4 urgent (เก็บเป็นตัวเลขให้ sort ได้ตรง ๆ)
+-- important: ใช้กับ Eisenhower matrix (GET /todos/matrix)
+ALTER TABLE todos
+ADD COLUMN priority SMALLINT NOT NULL DEFAULT 0 CHECK (priority BETWEEN 0 AND 4),
+ADD COLUMN important BOOLEAN NOT NULL DEFAULT false;
+
+-- matrix ดึงเฉพาะที่ยังไม่เสร็จ
+CREATE INDEX IF NOT EXISTS idx_todos_open_priority
+ON todos(todo_group_id, priority DESC)
+WHERE NOT is_success;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_todos_open_priority;
+
+ALTER TABLE todos
+DROP COLUMN IF EXISTS important,
+DROP COLUMN IF EXISTS priority;
+-- +goose StatementEnd
@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+-- =========================
+-- tags
+--   ป้ายของ user แต่ละคน (ไม่แชร์กัน) ติดกับ todo ไหนก็ได้ที่มองเห็น
+--   todo ใน group ที่แชร์กัน แต่ละคนเห็นเฉพาะ tag ของตัวเอง
+-- =========================
+CREATE TABLE IF NOT EXISTS tags (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    color VARCHAR(20) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- ชื่อซ้ำกันไม่ได้ (ไม่สนตัวพิมพ์เล็ก/ใหญ่)
+CREATE UNIQUE INDEX IF NOT EXISTS uq_tags_user_name ON tags(user_id, lower(name));
+
+CREATE TRIGGER trigger_update_timestamp_tags
+BEFORE UPDATE ON tags
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS todo_tags (
+    todo_id BIGINT NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    tag_id BIGINT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (todo_id, tag_id)
+);
+
+-- filter todos ตาม tag / นับจำนวนที่ใช้
+CREATE INDEX IF NOT EXISTS idx_todo_tags_tag_id ON todo_tags(tag_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS todo_tags;
+DROP TABLE IF EXISTS tags;
+-- +goose StatementEnd
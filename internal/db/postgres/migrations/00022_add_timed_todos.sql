@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+-- todo มีเวลา (นัดประชุม ฯลฯ) vs ทั้งวัน (start_at = NULL)
+--   start_at / end_at = เวลาจริง, timezone = IANA zone ที่ใช้แสดงผล / คิดวัน
+--   date_start / date_end ของ todo ที่มีเวลา = วันของ start_at / end_at ตาม timezone นั้น (service เซ็ตให้)
+--   จึงยัง sort / filter ด้วย date_start ร่วมกับ todo ทั้งวันได้เหมือนเดิม
+ALTER TABLE todos
+ADD COLUMN start_at TIMESTAMPTZ,
+ADD COLUMN end_at TIMESTAMPTZ,
+ADD COLUMN timezone VARCHAR(64),
+ADD CONSTRAINT chk_todos_timed_zone CHECK ((start_at IS NULL) = (timezone IS NULL)),
+ADD CONSTRAINT chk_todos_end_at CHECK (end_at IS NULL OR (start_at IS NOT NULL AND end_at >= start_at));
+
+-- มุมมองรายวัน (today / tomorrow / this-week) หา todo ที่มีเวลาจากช่วงเวลาจริง
+CREATE INDEX IF NOT EXISTS idx_todos_start_at
+ON todos(start_at)
+WHERE start_at IS NOT NULL;
+
+-- ต้นแบบของ series ที่มีเวลา: เวลาเริ่มเป็นนาทีนับจากเที่ยงคืนตาม timezone ของ series (NULL = ทั้งวัน)
+ALTER TABLE todo_series
+ADD COLUMN start_minute INT CHECK (start_minute BETWEEN 0 AND 1439),
+ADD COLUMN duration_minutes INT CHECK (duration_minutes >= 0); -- end_at - start_at (NULL = ไม่มี end_at)
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE todo_series
+DROP COLUMN IF EXISTS duration_minutes,
+DROP COLUMN IF EXISTS start_minute;
+
+DROP INDEX IF EXISTS idx_todos_start_at;
+
+ALTER TABLE todos
+DROP CONSTRAINT IF EXISTS chk_todos_end_at,
+DROP CONSTRAINT IF EXISTS chk_todos_timed_zone,
+DROP COLUMN IF EXISTS timezone,
+DROP COLUMN IF EXISTS end_at,
+DROP COLUMN IF EXISTS start_at;
+-- +goose StatementEnd
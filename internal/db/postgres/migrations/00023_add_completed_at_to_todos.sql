@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- completed_at = เวลาที่ทำเสร็จ (service เซ็ต / ล้างตาม is_success) ใช้กับ logbook (GET /todos/completed)
+ALTER TABLE todos
+ADD COLUMN completed_at TIMESTAMPTZ;
+
+-- todo ที่เสร็จไปก่อนมี column นี้ไม่รู้เวลาจริง ใช้เวลาที่แก้ล่าสุดแทน
+-- (ปิด trigger ไว้ก่อน ไม่งั้น updated_at กลายเป็นตอน migrate)
+ALTER TABLE todos DISABLE TRIGGER trigger_update_timestamp_todos;
+
+UPDATE todos
+SET completed_at = updated_at
+WHERE is_success;
+
+ALTER TABLE todos ENABLE TRIGGER trigger_update_timestamp_todos;
+
+ALTER TABLE todos
+ADD CONSTRAINT chk_todos_completed_at CHECK (is_success = (completed_at IS NOT NULL));
+
+CREATE INDEX IF NOT EXISTS idx_todos_completed_at
+ON todos(completed_at DESC)
+WHERE is_success;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_todos_completed_at;
+
+ALTER TABLE todos
+DROP CONSTRAINT IF EXISTS chk_todos_completed_at,
+DROP COLUMN IF EXISTS completed_at;
+-- +goose StatementEnd
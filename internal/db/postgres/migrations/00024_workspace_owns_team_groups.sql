@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- group ใน team workspace เป็นของ workspace: สมาชิกออก / ลบ account แล้วโอน group + todos ให้ owner ของ workspace
+-- ระหว่างโอน todos.user_id / parent ไม่ตรงกับเจ้าของ group ชั่วคราว จึงให้เลื่อนไปเช็คตอน commit ได้
+ALTER TABLE todos
+ALTER CONSTRAINT fk_todos_todo_group_owner DEFERRABLE INITIALLY IMMEDIATE;
+
+ALTER TABLE todo_groups
+ALTER CONSTRAINT fk_todo_groups_parent DEFERRABLE INITIALLY IMMEDIATE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE todo_groups
+ALTER CONSTRAINT fk_todo_groups_parent NOT DEFERRABLE;
+
+ALTER TABLE todos
+ALTER CONSTRAINT fk_todos_todo_group_owner NOT DEFERRABLE;
+-- +goose StatementEnd
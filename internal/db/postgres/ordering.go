@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// helper ที่ใช้ร่วมกันระหว่าง repo ที่มี column position (todo_groups, todos, checklist_items)
+
+var ErrAnchorNotFound = errors.New("anchor row not found")
+
+// LockUserOrdering ให้การสร้าง/ย้ายของ user เดียวกันทำทีละคำขอ
+// ไม่งั้นสองคำขอพร้อมกันจะได้ position เดียวกันแล้วชน unique index
+func LockUserOrdering(ctx context.Context, tx pgx.Tx, userID int64) error {
+	_, err := tx.Exec(ctx, `SELECT 1 FROM users WHERE id = $1 FOR NO KEY UPDATE`, userID)
+	return err
+}
+
+// NeighborPositions หา position ขอบล่าง/บนของช่องที่จะย้ายไปวาง ("" = สุดขอบ list)
+// table มาจากโค้ดเราเองเท่านั้น (todo_groups / todos) ไม่ได้มาจาก input
+func NeighborPositions(ctx context.Context, tx pgx.Tx, table string, id, userID int64, beforeID, afterID *int64) (string, string, error) {
+	return NeighborPositionsIn(ctx, tx, table, "user_id", userID, id, beforeID, afterID)
+}
+
+// NeighborPositionsIn เหมือน NeighborPositions แต่ list แบ่งตาม column อื่น
+// (เช่น checklist_items เรียงภายใน todo_id เดียวกัน) table / column มาจากโค้ดเราเองเท่านั้น
+func NeighborPositionsIn(ctx context.Context, tx pgx.Tx, table, column string, scopeID, id int64, beforeID, afterID *int64) (string, string, error) {
+	anchor := func(anchorID int64) (string, error) {
+		var pos string
+		err := tx.QueryRow(ctx,
+			`SELECT position FROM `+table+` WHERE id = $1 AND `+column+` = $2`,
+			anchorID, scopeID,
+		).Scan(&pos)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrAnchorNotFound
+		}
+		return pos, err
+	}
+
+	var lower, upper string
+	var err error
+
+	if afterID != nil {
+		if lower, err = anchor(*afterID); err != nil {
+			return "", "", err
+		}
+	}
+	if beforeID != nil {
+		if upper, err = anchor(*beforeID); err != nil {
+			return "", "", err
+		}
+	}
+
+	switch {
+	case afterID != nil && beforeID == nil:
+		// ตัวถัดจาก after (ไม่นับตัวที่กำลังย้าย)
+		err = tx.QueryRow(ctx, `
+			SELECT COALESCE(MIN(position), '')
+			FROM `+table+`
+			WHERE `+column+` = $1 AND position > $2 AND id <> $3
+		`, scopeID, lower, id).Scan(&upper)
+	case beforeID != nil && afterID == nil:
+		// ตัวก่อนหน้า before (ไม่นับตัวที่กำลังย้าย)
+		err = tx.QueryRow(ctx, `
+			SELECT COALESCE(MAX(position), '')
+			FROM `+table+`
+			WHERE `+column+` = $1 AND position < $2 AND id <> $3
+		`, scopeID, upper, id).Scan(&lower)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	return lower, upper, nil
+}
@@ -0,0 +1,181 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Nasaee/go-todo-backend/pkg/rank"
+	"github.com/jackc/pgx/v5"
+)
+
+// group / todos ใน team workspace เป็นของ workspace: คนสร้างออกจาก workspace (หรือลบ account) แล้ว
+// ต้องโอนให้ owner ของ workspace ไม่งั้นคนที่ออกไปยังเป็นเจ้าของอยู่ หรือ cascade ลบของทีมทิ้งตอนลบ user
+
+var ErrWorkspaceOwnerNotFound = errors.New("workspace has no owner")
+
+// TransferWorkspaceGroups โอน group ทุกอันที่ fromUserID เป็นเจ้าของใน workspace (+ todos ข้างใน) ให้ owner ของ workspace
+// ต่อท้าย list ของ owner ตามลำดับเดิม แล้วเอา fromUserID ออกจากสมาชิกของทุก group ใน workspace นั้น
+// ต้องเรียกใน transaction (FK เจ้าของของ todos / parent ถูกเลื่อนไปเช็คตอน commit)
+func TransferWorkspaceGroups(ctx context.Context, tx pgx.Tx, workspaceID, fromUserID int64) error {
+	var ownerID int64
+	err := tx.QueryRow(ctx, `
+		SELECT user_id FROM workspace_members
+		WHERE workspace_id = $1 AND role = 'owner'
+	`, workspaceID).Scan(&ownerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrWorkspaceOwnerNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if ownerID != fromUserID {
+		if err := transferGroups(ctx, tx, workspaceID, fromUserID, ownerID); err != nil {
+			return err
+		}
+	}
+
+	// สิทธิ์ที่แชร์ให้ตรง ๆ ก็หมดไปด้วย (assignment ของคนนั้นหลุดตาม trigger ใน migration 00015)
+	_, err = tx.Exec(ctx, `
+		DELETE FROM todo_group_members m
+		USING todo_groups g
+		WHERE g.id = m.todo_group_id AND g.workspace_id = $1 AND m.user_id = $2
+	`, workspaceID, fromUserID)
+	return err
+}
+
+func transferGroups(ctx context.Context, tx pgx.Tx, workspaceID, fromUserID, ownerID int64) error {
+	// todos.user_id / parent ต้องตรงกับเจ้าของ group ระหว่างโอนจะไม่ตรงชั่วคราว
+	if _, err := tx.Exec(ctx, `SET CONSTRAINTS fk_todos_todo_group_owner, fk_todo_groups_parent DEFERRED`); err != nil {
+		return err
+	}
+	if err := LockUserOrdering(ctx, tx, ownerID); err != nil {
+		return err
+	}
+
+	groupIDs, err := appendPositions(ctx, tx, "todo_groups", ownerID, `
+		SELECT id FROM todo_groups
+		WHERE workspace_id = $1 AND user_id = $2
+		ORDER BY position
+	`, workspaceID, fromUserID)
+	if err != nil || len(groupIDs) == 0 {
+		return err
+	}
+
+	if _, err := appendPositions(ctx, tx, "todos", ownerID, `
+		SELECT id FROM todos
+		WHERE todo_group_id = ANY($1) AND user_id = $2
+		ORDER BY position
+	`, groupIDs, fromUserID); err != nil {
+		return err
+	}
+
+	// owner เดิมของ group = fromUserID (ลบทิ้งด้านล่าง) owner ใหม่อาจเป็นสมาชิกอยู่แล้ว อัปเกรด role แทน
+	_, err = tx.Exec(ctx, `
+		DELETE FROM todo_group_members
+		WHERE todo_group_id = ANY($1) AND role = 'owner'
+	`, groupIDs)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO todo_group_members (todo_group_id, user_id, role)
+		SELECT unnest($1::BIGINT[]), $2, 'owner'
+		ON CONFLICT (todo_group_id, user_id) DO UPDATE SET role = 'owner'
+	`, groupIDs, ownerID)
+	return err
+}
+
+// appendPositions ย้ายแถวที่ query เลือก (เรียงตามลำดับที่ต้องการ) ไปต่อท้าย list ของ ownerID
+// table มาจากโค้ดเราเองเท่านั้น (todo_groups / todos) ไม่ได้มาจาก input
+func appendPositions(ctx context.Context, tx pgx.Tx, table string, ownerID int64, query string, args ...any) ([]int64, error) {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil || len(ids) == 0 {
+		return ids, err
+	}
+
+	var last string
+	if err := tx.QueryRow(ctx,
+		`SELECT COALESCE(MAX(position), '') FROM `+table+` WHERE user_id = $1`,
+		ownerID,
+	).Scan(&last); err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		if last, err = rank.After(last); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(ctx,
+			`UPDATE `+table+` SET user_id = $1, position = $2 WHERE id = $3`,
+			ownerID, last, id,
+		); err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+// LeaveTeamWorkspaces ใช้ก่อนลบ user: ออกจาก team workspace ทุกอันแล้วโอน group ให้ owner
+// workspace ที่ user เป็น owner ให้ admin (ไม่มีก็สมาชิกที่อยู่นานสุด) เป็น owner แทน ไม่เหลือใครเลย = ลบ workspace
+func LeaveTeamWorkspaces(ctx context.Context, tx pgx.Tx, userID int64) error {
+	// รวม workspace ที่ไม่ได้เป็นสมาชิกแล้วแต่ยังมี group ค้างอยู่ (ข้อมูลก่อนมีการโอน)
+	rows, err := tx.Query(ctx, `
+		SELECT w.id
+		FROM workspaces w
+		WHERE w.kind = 'team'
+		  AND (EXISTS (SELECT 1 FROM workspace_members m WHERE m.workspace_id = w.id AND m.user_id = $1)
+		    OR EXISTS (SELECT 1 FROM todo_groups g WHERE g.workspace_id = w.id AND g.user_id = $1))
+		ORDER BY w.id
+	`, userID)
+	if err != nil {
+		return err
+	}
+	workspaceIDs, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return err
+	}
+
+	for _, id := range workspaceIDs {
+		var role string
+		err := tx.QueryRow(ctx, `
+			DELETE FROM workspace_members
+			WHERE workspace_id = $1 AND user_id = $2
+			RETURNING role
+		`, id, userID).Scan(&role)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		if role == "owner" {
+			cmdTag, err := tx.Exec(ctx, `
+				UPDATE workspace_members
+				SET role = 'owner'
+				WHERE (workspace_id, user_id) = (
+					SELECT workspace_id, user_id FROM workspace_members
+					WHERE workspace_id = $1
+					ORDER BY role = 'admin' DESC, created_at, user_id
+					LIMIT 1
+				)
+			`, id)
+			if err != nil {
+				return err
+			}
+			if cmdTag.RowsAffected() == 0 {
+				if _, err := tx.Exec(ctx, `DELETE FROM workspaces WHERE id = $1`, id); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if err := TransferWorkspaceGroups(ctx, tx, id, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+// Package event ส่ง domain event ออกไปให้ส่วนอื่น (เช่น worker ส่งแจ้งเตือน) ไปใช้ต่อ
+// ฝั่งที่ publish ไม่ต้องรู้ว่าใครจะ subscribe
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type Event struct {
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Payload    any       `json:"payload"`
+}
+
+func New(eventType string, payload any) Event {
+	return Event{
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		Payload:    payload,
+	}
+}
+
+type Publisher interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+// RedisPublisher PUBLISH event เป็น JSON เข้า channel เดียว (subscriber แยกตาม Type เอง)
+type RedisPublisher struct {
+	rdb     *redis.Client
+	channel string
+}
+
+func NewRedisPublisher(rdb *redis.Client, channel string) *RedisPublisher {
+	return &RedisPublisher{rdb: rdb, channel: channel}
+}
+
+func (p *RedisPublisher) Publish(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	return p.rdb.Publish(ctx, p.channel, body).Err()
+}
+
+// LogPublisher แค่ log event ไว้ (ใช้ตอนยังไม่มีใคร subscribe)
+type LogPublisher struct{}
+
+func (LogPublisher) Publish(ctx context.Context, e Event) error {
+	slog.InfoContext(ctx, "event", "type", e.Type, "payload", e.Payload)
+	return nil
+}
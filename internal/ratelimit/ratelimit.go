@@ -0,0 +1,81 @@
+// Package ratelimit จำกัดจำนวน request ต่อช่วงเวลา (fixed window) เก็บตัวนับใน redis
+// หลาย instance ของ API จึงนับรวมกันได้
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Nasaee/go-todo-backend/pkg/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+type Limiter struct {
+	rdb    *redis.Client
+	prefix string
+	limit  int64
+	window time.Duration
+}
+
+// New limit = จำนวน request สูงสุดต่อ key ใน 1 window
+func New(rdb *redis.Client, prefix string, limit int, window time.Duration) *Limiter {
+	return &Limiter{
+		rdb:    rdb,
+		prefix: prefix,
+		limit:  int64(limit),
+		window: window,
+	}
+}
+
+// Allow นับ request ของ key นี้ เกิน limit คืน false + เวลาที่ต้องรอจน window ถัดไป
+func (l *Limiter) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	k := "ratelimit:" + l.prefix + ":" + key
+
+	pipe := l.rdb.TxPipeline()
+	incr := pipe.Incr(ctx, k)
+	pipe.ExpireNX(ctx, k, l.window)
+	ttl := pipe.PTTL(ctx, k)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, 0, err
+	}
+
+	if incr.Val() > l.limit {
+		return false, ttl.Val(), nil
+	}
+	return true, 0, nil
+}
+
+// ByIP ใช้ IP ของ client เป็น key (ต้องมี middleware.RealIP อยู่ก่อน)
+func ByIP(r *http.Request) string {
+	return utils.ClientIP(r)
+}
+
+// Middleware ตอบ 429 + Retry-After เมื่อเกิน limit
+// redis มีปัญหาจะปล่อยผ่าน (fail open) ดีกว่าทำให้ทั้ง endpoint ล่ม
+func (l *Limiter) Middleware(keyFn func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ok, retryAfter, err := l.Allow(r.Context(), keyFn(r))
+			if err != nil {
+				slog.Error("rate limiter unavailable", "prefix", l.prefix, "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !ok {
+				secs := int(retryAfter.Round(time.Second) / time.Second)
+				if secs < 1 {
+					secs = 1
+				}
+				w.Header().Set("Retry-After", strconv.Itoa(secs))
+				utils.WriteError(w, http.StatusTooManyRequests, "too many requests, try again later")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
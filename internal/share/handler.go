@@ -0,0 +1,136 @@
+package share
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/Nasaee/go-todo-backend/internal/audit"
+	"github.com/Nasaee/go-todo-backend/internal/auth"
+	"github.com/Nasaee/go-todo-backend/internal/todogroup"
+	"github.com/Nasaee/go-todo-backend/pkg/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// POST /todo-groups/{id}/share-links (body ไม่ส่งก็ได้ = ลิงก์ไม่หมดอายุ)
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var input CreateShareLinkInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	l, err := h.svc.Create(r.Context(), groupID, userID, input, audit.ClientFromRequest(r))
+	if err != nil {
+		writeServiceError(w, err, "could not create share link")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, l)
+}
+
+// GET /todo-groups/{id}/share-links (เฉพาะลิงก์ที่ยังใช้ได้)
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	links, err := h.svc.List(r.Context(), groupID, userID)
+	if err != nil {
+		writeServiceError(w, err, "could not fetch share links")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, links)
+}
+
+// DELETE /todo-groups/{id}/share-links/{linkID}
+func (h *Handler) Revoke(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	linkID, err := strconv.ParseInt(chi.URLParam(r, "linkID"), 10, 64)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid linkID")
+		return
+	}
+
+	if err := h.svc.Revoke(r.Context(), groupID, linkID, userID, audit.ClientFromRequest(r)); err != nil {
+		writeServiceError(w, err, "could not revoke share link")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+// GET /shared/{token} (public ไม่ต้อง login)
+func (h *Handler) Open(w http.ResponseWriter, r *http.Request) {
+	g, err := h.svc.Open(r.Context(), chi.URLParam(r, "token"), audit.ClientFromRequest(r))
+	if err != nil {
+		writeServiceError(w, err, "could not open share link")
+		return
+	}
+
+	// ลิงก์ revoke ได้ ไม่ให้ cache ค้างไว้ และไม่ให้ search engine เก็บ
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	utils.WriteJSON(w, http.StatusOK, g)
+}
+
+func writeServiceError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		utils.WriteError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, todogroup.ErrNotFound):
+		utils.WriteError(w, http.StatusNotFound, "todo group not found")
+	case errors.Is(err, todogroup.ErrForbidden):
+		utils.WriteError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, todogroup.ErrInboxShare):
+		utils.WriteError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, ErrInvalidExpiry):
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		fmt.Println(err)
+		utils.WriteError(w, http.StatusInternalServerError, fallback)
+	}
+}
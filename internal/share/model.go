@@ -0,0 +1,43 @@
+package share
+
+import (
+	"time"
+
+	"github.com/Nasaee/go-todo-backend/pkg/civil"
+)
+
+type ShareLink struct {
+	ID          int64      `json:"id"`
+	TodoGroupID int64      `json:"todo_group_id"`
+	Token       string     `json:"token,omitempty"` // มีเฉพาะตอนสร้าง DB เก็บแค่ hash
+	CreatedBy   int64      `json:"created_by"`
+	ExpiresAt   *time.Time `json:"expires_at"` // nil = ไม่หมดอายุ
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// ใช้กับ POST /todo-groups/{id}/share-links
+type CreateShareLinkInput struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// SharedGroup คือสิ่งที่คนเปิดลิงก์เห็น ตั้งใจไม่มี user id / email / id ภายในใด ๆ
+type SharedGroup struct {
+	Name  string       `json:"name"`
+	Color string       `json:"color"`
+	Todos []SharedTodo `json:"todos"`
+}
+
+type SharedTodo struct {
+	Title       string      `json:"title"`
+	Description *string     `json:"description,omitempty"`
+	DateStart   civil.Date  `json:"date_start"`
+	DateEnd     *civil.Date `json:"date_end,omitempty"`
+	IsSuccess   bool        `json:"is_success"`
+
+	// todo ที่มีเวลา (ทั้งวัน = ไม่มี start_at / end_at / timezone) ความหมายเดียวกับ todo ปกติ
+	AllDay   bool       `json:"all_day"`
+	StartAt  *time.Time `json:"start_at,omitempty"`
+	EndAt    *time.Time `json:"end_at,omitempty"`
+	Timezone *string    `json:"timezone,omitempty"`
+}
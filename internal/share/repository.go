@@ -0,0 +1,165 @@
+package share
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrNotFound = errors.New("share link not found")
+
+const linkColumns = `id, todo_group_id, created_by, expires_at, revoked_at, created_at`
+
+func linkDest(l *ShareLink) []any {
+	return []any{
+		&l.ID,
+		&l.TodoGroupID,
+		&l.CreatedBy,
+		&l.ExpiresAt,
+		&l.RevokedAt,
+		&l.CreatedAt,
+	}
+}
+
+type Repository interface {
+	Create(ctx context.Context, l *ShareLink, tokenHash []byte) error
+	ListActive(ctx context.Context, groupID int64) ([]ShareLink, error)
+	Revoke(ctx context.Context, groupID, id int64) error
+	// FindActiveByHash ลิงก์ที่ยังไม่ถูก revoke และยังไม่หมดอายุ
+	FindActiveByHash(ctx context.Context, tokenHash []byte) (*ShareLink, error)
+	GetSharedGroup(ctx context.Context, groupID int64) (*SharedGroup, error)
+}
+
+type postgresRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Repository {
+	return &postgresRepo{db: db}
+}
+
+func (r *postgresRepo) Create(ctx context.Context, l *ShareLink, tokenHash []byte) error {
+	query := `
+		INSERT INTO share_links (todo_group_id, token_hash, created_by, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + linkColumns
+
+	return r.db.QueryRow(ctx, query, l.TodoGroupID, tokenHash, l.CreatedBy, l.ExpiresAt).Scan(linkDest(l)...)
+}
+
+func (r *postgresRepo) ListActive(ctx context.Context, groupID int64) ([]ShareLink, error) {
+	query := `
+		SELECT ` + linkColumns + `
+		FROM share_links
+		WHERE todo_group_id = $1
+		  AND revoked_at IS NULL
+		  AND (expires_at IS NULL OR expires_at > NOW())
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.Query(ctx, query, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []ShareLink{}
+	for rows.Next() {
+		var l ShareLink
+		if err := rows.Scan(linkDest(&l)...); err != nil {
+			return nil, err
+		}
+		links = append(links, l)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return links, nil
+}
+
+func (r *postgresRepo) Revoke(ctx context.Context, groupID, id int64) error {
+	query := `
+		UPDATE share_links
+		SET revoked_at = NOW()
+		WHERE id = $1 AND todo_group_id = $2 AND revoked_at IS NULL
+	`
+
+	cmdTag, err := r.db.Exec(ctx, query, id, groupID)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *postgresRepo) FindActiveByHash(ctx context.Context, tokenHash []byte) (*ShareLink, error) {
+	query := `
+		SELECT ` + linkColumns + `
+		FROM share_links
+		WHERE token_hash = $1
+		  AND revoked_at IS NULL
+		  AND (expires_at IS NULL OR expires_at > NOW())
+	`
+
+	var l ShareLink
+	if err := r.db.QueryRow(ctx, query, tokenHash).Scan(linkDest(&l)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &l, nil
+}
+
+func (r *postgresRepo) GetSharedGroup(ctx context.Context, groupID int64) (*SharedGroup, error) {
+	var g SharedGroup
+	err := r.db.QueryRow(ctx, `
+		SELECT name, color
+		FROM todo_groups
+		WHERE id = $1
+	`, groupID).Scan(&g.Name, &g.Color)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT title, description, date_start, date_end, is_success, start_at, end_at, timezone
+		FROM todos
+		WHERE todo_group_id = $1
+		ORDER BY position
+	`, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	g.Todos = []SharedTodo{}
+	for rows.Next() {
+		var t SharedTodo
+		if err := rows.Scan(
+			&t.Title, &t.Description, &t.DateStart, &t.DateEnd, &t.IsSuccess,
+			&t.StartAt, &t.EndAt, &t.Timezone,
+		); err != nil {
+			return nil, err
+		}
+		t.AllDay = t.StartAt == nil
+		g.Todos = append(g.Todos, t)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return &g, nil
+}
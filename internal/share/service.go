@@ -0,0 +1,168 @@
+package share
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Nasaee/go-todo-backend/internal/audit"
+	"github.com/Nasaee/go-todo-backend/internal/auth"
+	"github.com/Nasaee/go-todo-backend/internal/todogroup"
+)
+
+var ErrInvalidExpiry = errors.New("expires_at must be in the future")
+
+// tokenBytes = ความยาว token ก่อน encode (256 bit เดาไม่ได้)
+const tokenBytes = 32
+
+type Service interface {
+	Create(ctx context.Context, groupID, userID int64, input CreateShareLinkInput, client audit.Client) (*ShareLink, error)
+	List(ctx context.Context, groupID, userID int64) ([]ShareLink, error)
+	Revoke(ctx context.Context, groupID, linkID, userID int64, client audit.Client) error
+	// Open ใช้ตอนคนภายนอกเปิดลิงก์ (ไม่ต้อง login)
+	Open(ctx context.Context, token string, client audit.Client) (*SharedGroup, error)
+}
+
+type service struct {
+	repo   Repository
+	groups todogroup.TodoGroupRepository
+	audit  audit.Logger
+}
+
+func NewService(repo Repository, groups todogroup.TodoGroupRepository, auditLog audit.Logger) Service {
+	return &service{repo: repo, groups: groups, audit: auditLog}
+}
+
+func hashToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+func newToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// requireOwner เปิดลิงก์สาธารณะได้เฉพาะเจ้าของ group (inbox แชร์ไม่ได้)
+func (s *service) requireOwner(ctx context.Context, groupID, userID int64) (*todogroup.TodoGroup, error) {
+	g, err := s.groups.GetByID(ctx, groupID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !auth.InWorkspace(ctx, g.WorkspaceID) {
+		return nil, todogroup.ErrNotFound
+	}
+	if !g.Role.AtLeast(todogroup.RoleOwner) {
+		return nil, todogroup.ErrForbidden
+	}
+	if g.IsInbox {
+		return nil, todogroup.ErrInboxShare
+	}
+	return g, nil
+}
+
+// record audit พังไม่ควรทำให้ request พัง แค่ log ไว้
+func (s *service) record(ctx context.Context, e audit.Entry) {
+	if err := s.audit.Record(ctx, e); err != nil {
+		slog.Error("failed to record audit log", "action", e.Action, "error", err)
+	}
+}
+
+func (s *service) Create(ctx context.Context, groupID, userID int64, input CreateShareLinkInput, client audit.Client) (*ShareLink, error) {
+	if _, err := s.requireOwner(ctx, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidExpiry
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	l := &ShareLink{
+		TodoGroupID: groupID,
+		CreatedBy:   userID,
+		ExpiresAt:   input.ExpiresAt,
+	}
+	if err := s.repo.Create(ctx, l, hashToken(token)); err != nil {
+		return nil, err
+	}
+	l.Token = token
+
+	s.record(ctx, audit.Entry{
+		ActorID:      &userID,
+		Action:       "share_link.created",
+		ResourceType: "share_link",
+		ResourceID:   &l.ID,
+		Client:       client,
+		Metadata:     map[string]any{"todo_group_id": groupID, "expires_at": l.ExpiresAt},
+	})
+
+	return l, nil
+}
+
+func (s *service) List(ctx context.Context, groupID, userID int64) ([]ShareLink, error) {
+	if _, err := s.requireOwner(ctx, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListActive(ctx, groupID)
+}
+
+func (s *service) Revoke(ctx context.Context, groupID, linkID, userID int64, client audit.Client) error {
+	if _, err := s.requireOwner(ctx, groupID, userID); err != nil {
+		return err
+	}
+
+	if err := s.repo.Revoke(ctx, groupID, linkID); err != nil {
+		return err
+	}
+
+	s.record(ctx, audit.Entry{
+		ActorID:      &userID,
+		Action:       "share_link.revoked",
+		ResourceType: "share_link",
+		ResourceID:   &linkID,
+		Client:       client,
+		Metadata:     map[string]any{"todo_group_id": groupID},
+	})
+
+	return nil
+}
+
+func (s *service) Open(ctx context.Context, token string, client audit.Client) (*SharedGroup, error) {
+	// token ผิดรูปแบบไม่ต้องไปถาม DB
+	if raw, err := base64.RawURLEncoding.DecodeString(token); err != nil || len(raw) != tokenBytes {
+		return nil, ErrNotFound
+	}
+
+	l, err := s.repo.FindActiveByHash(ctx, hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := s.repo.GetSharedGroup(ctx, l.TodoGroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, audit.Entry{
+		Action:       "share_link.accessed",
+		ResourceType: "share_link",
+		ResourceID:   &l.ID,
+		Client:       client,
+		Metadata:     map[string]any{"todo_group_id": l.TodoGroupID},
+	})
+
+	return g, nil
+}
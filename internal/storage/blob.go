@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+)
+
+var ErrInvalidKey = errors.New("invalid blob key")
+
+// BlobStore คือที่เก็บไฟล์ (avatar ฯลฯ) เปลี่ยน implementation ได้โดยไม่กระทบ service
+//
+// key เป็น path แบบ "avatars/1/abc/large.png" (ใช้ / คั่นเสมอ ไม่มี / นำหน้า)
+type BlobStore interface {
+	Put(ctx context.Context, key string, body io.Reader, contentType string) error
+	Delete(ctx context.Context, key string) error
+	// URL คืน public URL ของ key นั้น ใช้ส่งกลับไปให้ client
+	URL(key string) string
+}
+
+// cleanKey กัน path traversal เช่น "../../etc/passwd"
+func cleanKey(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return "", ErrInvalidKey
+	}
+
+	cleaned := path.Clean(key)
+	if cleaned != key || cleaned == "." || strings.HasPrefix(cleaned, "../") || cleaned == ".." {
+		return "", ErrInvalidKey
+	}
+
+	return cleaned, nil
+}
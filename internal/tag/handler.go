@@ -0,0 +1,154 @@
+package tag
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Nasaee/go-todo-backend/internal/auth"
+	"github.com/Nasaee/go-todo-backend/internal/todogroup"
+	"github.com/Nasaee/go-todo-backend/pkg/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// POST /tags
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	var input CreateTagInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	t, err := h.svc.Create(r.Context(), userID, input)
+	if err != nil {
+		writeServiceError(w, err, "could not create tag")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, t)
+}
+
+// GET /tags
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	tags, err := h.svc.List(r.Context(), userID)
+	if err != nil {
+		writeServiceError(w, err, "could not fetch tags")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, tags)
+}
+
+// GET /tags/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id, ok := idFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	t, err := h.svc.Get(r.Context(), id, userID)
+	if err != nil {
+		writeServiceError(w, err, "could not fetch tag")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, t)
+}
+
+// PATCH /tags/{id}
+func (h *Handler) Patch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id, ok := idFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var input PatchTagInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	t, err := h.svc.Patch(r.Context(), id, userID, input)
+	if err != nil {
+		writeServiceError(w, err, "could not update tag")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, t)
+}
+
+// DELETE /tags/{id} (todo ที่ติด tag นี้อยู่แค่หลุด tag ไม่ได้ถูกลบ)
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id, ok := idFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Delete(r.Context(), id, userID); err != nil {
+		writeServiceError(w, err, "could not delete tag")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+func idFromURL(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid id")
+		return 0, false
+	}
+	return id, true
+}
+
+func writeServiceError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		utils.WriteError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrEmptyName), errors.Is(err, ErrNameTooLong), errors.Is(err, todogroup.ErrInvalidColor):
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrDuplicateName):
+		utils.WriteError(w, http.StatusConflict, err.Error())
+	default:
+		utils.WriteError(w, http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,43 @@
+package tag
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotFound      = errors.New("tag not found")
+	ErrEmptyName     = errors.New("tag name is required")
+	ErrNameTooLong   = errors.New("tag name must be at most 50 characters")
+	ErrDuplicateName = errors.New("you already have a tag with this name")
+)
+
+// MaxNameLength ตรงกับ tags.name VARCHAR(50)
+const MaxNameLength = 50
+
+// Tag เป็นของ user คนเดียว ติดกับ todo ได้ผ่าน tag_ids ของ todo
+type Tag struct {
+	ID     int64  `json:"id"`
+	UserID int64  `json:"user_id"`
+	Name   string `json:"name"`
+	Color  string `json:"color"`
+
+	// จำนวน todo ที่ติด tag นี้ (นับเฉพาะ todo ที่ยังมองเห็นอยู่)
+	TodoCount int `json:"todo_count"`
+	OpenCount int `json:"open_count"` // ที่ยังไม่เสร็จ
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ใช้กับ POST /tags
+type CreateTagInput struct {
+	Name  string `json:"name"`
+	Color string `json:"color"` // ไม่ส่ง = todogroup.DefaultColor
+}
+
+// ใช้กับ PATCH /tags/{id} (ไม่ส่ง = ไม่แก้)
+type PatchTagInput struct {
+	Name  *string `json:"name"`
+	Color *string `json:"color"`
+}
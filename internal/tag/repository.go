@@ -0,0 +1,144 @@
+package tag
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SELECT tag พร้อมจำนวนที่ใช้ (ต้องต่อด้วย WHERE ... แล้ว GROUP BY t.id)
+// todo ที่ไม่ได้เห็นแล้ว (ออกจาก group) ไม่นับ
+const selectTags = `
+	SELECT t.id, t.user_id, t.name, t.color,
+		COUNT(td.id),
+		COUNT(td.id) FILTER (WHERE NOT td.is_success),
+		t.created_at, t.updated_at
+	FROM tags t
+	LEFT JOIN todo_tags tt ON tt.tag_id = t.id
+	LEFT JOIN todos td ON td.id = tt.todo_id
+		AND td.todo_group_id IN (SELECT todo_group_id FROM todo_group_access WHERE user_id = t.user_id)
+`
+
+func tagDest(t *Tag) []any {
+	return []any{
+		&t.ID,
+		&t.UserID,
+		&t.Name,
+		&t.Color,
+		&t.TodoCount,
+		&t.OpenCount,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	}
+}
+
+// tag เป็นของ user คนเดียว ทุก method scope ด้วย userID
+type Repository interface {
+	Create(ctx context.Context, t *Tag) error
+	ListByUser(ctx context.Context, userID int64) ([]Tag, error)
+	GetByID(ctx context.Context, id, userID int64) (*Tag, error)
+	Update(ctx context.Context, t *Tag) error
+	Delete(ctx context.Context, id, userID int64) error
+}
+
+type postgresRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Repository {
+	return &postgresRepo{db: db}
+}
+
+// ชื่อซ้ำ (uq_tags_user_name) = ErrDuplicateName
+func mapNameError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "uq_tags_user_name" {
+		return ErrDuplicateName
+	}
+	return err
+}
+
+func (r *postgresRepo) Create(ctx context.Context, t *Tag) error {
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO tags (user_id, name, color)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at
+	`, t.UserID, t.Name, t.Color).Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+	return mapNameError(err)
+}
+
+func (r *postgresRepo) ListByUser(ctx context.Context, userID int64) ([]Tag, error) {
+	query := selectTags + `
+		WHERE t.user_id = $1
+		GROUP BY t.id
+		ORDER BY lower(t.name), t.id
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []Tag{}
+	for rows.Next() {
+		var t Tag
+		if err := rows.Scan(tagDest(&t)...); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return tags, nil
+}
+
+func (r *postgresRepo) GetByID(ctx context.Context, id, userID int64) (*Tag, error) {
+	query := selectTags + `
+		WHERE t.id = $1 AND t.user_id = $2
+		GROUP BY t.id
+	`
+
+	var t Tag
+	if err := r.db.QueryRow(ctx, query, id, userID).Scan(tagDest(&t)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (r *postgresRepo) Update(ctx context.Context, t *Tag) error {
+	err := r.db.QueryRow(ctx, `
+		UPDATE tags
+		SET name = $1, color = $2
+		WHERE id = $3 AND user_id = $4
+		RETURNING updated_at
+	`, t.Name, t.Color, t.ID, t.UserID).Scan(&t.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+
+	return mapNameError(err)
+}
+
+// Delete แถวใน todo_tags หายตาม (ON DELETE CASCADE)
+func (r *postgresRepo) Delete(ctx context.Context, id, userID int64) error {
+	cmdTag, err := r.db.Exec(ctx, `DELETE FROM tags WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
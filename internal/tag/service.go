@@ -0,0 +1,98 @@
+package tag
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Nasaee/go-todo-backend/internal/todogroup"
+)
+
+type Service interface {
+	Create(ctx context.Context, userID int64, input CreateTagInput) (*Tag, error)
+	// List tag ทั้งหมดของ user พร้อมจำนวน todo ที่ใช้
+	List(ctx context.Context, userID int64) ([]Tag, error)
+	Get(ctx context.Context, id, userID int64) (*Tag, error)
+	Patch(ctx context.Context, id, userID int64, input PatchTagInput) (*Tag, error)
+	Delete(ctx context.Context, id, userID int64) error
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+func normalizeName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", ErrEmptyName
+	}
+	if utf8.RuneCountInString(name) > MaxNameLength {
+		return "", ErrNameTooLong
+	}
+	return name, nil
+}
+
+func (s *service) Create(ctx context.Context, userID int64, input CreateTagInput) (*Tag, error) {
+	name, err := normalizeName(input.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	// ใช้ palette / รูปแบบสีเดียวกับ todo group
+	color, err := todogroup.NormalizeColor(input.Color)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Tag{UserID: userID, Name: name, Color: color}
+	if err := s.repo.Create(ctx, t); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (s *service) List(ctx context.Context, userID int64) ([]Tag, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+func (s *service) Get(ctx context.Context, id, userID int64) (*Tag, error) {
+	return s.repo.GetByID(ctx, id, userID)
+}
+
+func (s *service) Patch(ctx context.Context, id, userID int64, input PatchTagInput) (*Tag, error) {
+	t, err := s.repo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		if t.Name, err = normalizeName(*input.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	// PATCH ส่ง color มาแปลว่าตั้งใจจะเปลี่ยน ค่าว่างจึงไม่ถือเป็น "ใช้ default"
+	if input.Color != nil {
+		if strings.TrimSpace(*input.Color) == "" {
+			return nil, todogroup.ErrInvalidColor
+		}
+		if t.Color, err = todogroup.NormalizeColor(*input.Color); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Update(ctx, t); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (s *service) Delete(ctx context.Context, id, userID int64) error {
+	return s.repo.Delete(ctx, id, userID)
+}
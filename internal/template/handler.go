@@ -0,0 +1,190 @@
+package template
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/Nasaee/go-todo-backend/internal/auth"
+	"github.com/Nasaee/go-todo-backend/internal/todogroup"
+	"github.com/Nasaee/go-todo-backend/pkg/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// POST /templates
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	var input CreateTemplateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	t, err := h.svc.Create(r.Context(), userID, input)
+	if err != nil {
+		writeServiceError(w, err, "could not create template")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, t)
+}
+
+// GET /templates
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	templates, err := h.svc.List(r.Context(), userID)
+	if err != nil {
+		writeServiceError(w, err, "could not fetch templates")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, templates)
+}
+
+// GET /templates/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id, ok := idFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	t, err := h.svc.Get(r.Context(), id, userID)
+	if err != nil {
+		writeServiceError(w, err, "could not fetch template")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, t)
+}
+
+// PUT /templates/{id}
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id, ok := idFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var input UpdateTemplateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	t, err := h.svc.Update(r.Context(), id, userID, input)
+	if err != nil {
+		writeServiceError(w, err, "could not update template")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, t)
+}
+
+// DELETE /templates/{id}
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id, ok := idFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Delete(r.Context(), id, userID); err != nil {
+		writeServiceError(w, err, "could not delete template")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+// POST /templates/{id}/instantiate (body ไม่ส่งก็ได้ ถ้า template ไม่มีตัวแปร)
+func (h *Handler) Instantiate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id, ok := idFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var input InstantiateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	g, err := h.svc.Instantiate(r.Context(), id, userID, input)
+	if err != nil {
+		writeServiceError(w, err, "could not create group from template")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, g)
+}
+
+func idFromURL(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid id")
+		return 0, false
+	}
+	return id, true
+}
+
+func writeServiceError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrNotFound), errors.Is(err, todogroup.ErrNotFound):
+		utils.WriteError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrEmptyName), errors.Is(err, ErrEmptyTitle), errors.Is(err, ErrInvalidOffset), errors.Is(err, ErrInvalidTime),
+		errors.Is(err, ErrTooManyItems), errors.Is(err, ErrMissingVariable),
+		errors.Is(err, todogroup.ErrEmptyName), errors.Is(err, todogroup.ErrInvalidColor):
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, todogroup.ErrForbidden):
+		utils.WriteError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, todogroup.ErrInvalidParent), errors.Is(err, todogroup.ErrParentCycle),
+		errors.Is(err, todogroup.ErrTooDeep):
+		utils.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+	default:
+		fmt.Println(err)
+		utils.WriteError(w, http.StatusInternalServerError, fallback)
+	}
+}
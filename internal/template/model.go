@@ -0,0 +1,117 @@
+package template
+
+import (
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/Nasaee/go-todo-backend/internal/todo"
+	"github.com/Nasaee/go-todo-backend/pkg/civil"
+)
+
+var (
+	ErrNotFound        = errors.New("template not found")
+	ErrEmptyName       = errors.New("template name is required")
+	ErrEmptyTitle      = errors.New("every item needs a title")
+	ErrInvalidOffset   = errors.New("day_offset and duration_days cannot be negative")
+	ErrInvalidTime     = errors.New("start_minute must be 0-1439 with a valid timezone, duration_minutes cannot be negative and needs start_minute, timed items use duration_minutes instead of duration_days")
+	ErrTooManyItems    = errors.New("template has too many items")
+	ErrMissingVariable = errors.New("missing template variables")
+)
+
+// MaxItems จำนวน todo สูงสุดใน template หนึ่งอัน
+const MaxItems = 500
+
+// ตัวแปรใน title / description / group_name เขียนแบบ {{name}} (เว้นวรรคในวงเล็บได้)
+var variablePattern = regexp.MustCompile(`\{\{\s*([a-zA-Z0-9_]+)\s*\}\}`)
+
+// ตัวแปรที่ระบบเติมให้เอง ไม่ต้องส่งมาตอน instantiate
+//   - date = วัน anchor (YYYY-MM-DD)
+var builtinVariables = map[string]bool{"date": true}
+
+// Item todo หนึ่งอันใน template วันเก็บเป็นระยะห่างจาก anchor แทนวันจริง
+type Item struct {
+	Title       string  `json:"title"`
+	Description *string `json:"description,omitempty"`
+	// วันเริ่ม = anchor + day_offset วัน
+	DayOffset int `json:"day_offset"`
+	// date_end = วันเริ่ม + duration_days วัน (ไม่ส่ง = ไม่มี date_end)
+	DurationDays *int `json:"duration_days,omitempty"`
+
+	// todo ที่มีเวลา: เริ่มกี่นาทีหลังเที่ยงคืนตาม timezone (ไม่ส่ง = todo ทั้งวัน)
+	StartMinute     *int    `json:"start_minute,omitempty"`
+	DurationMinutes *int    `json:"duration_minutes,omitempty"` // end_at - start_at (ไม่ส่ง = ไม่มี end_at)
+	Timezone        *string `json:"timezone,omitempty"`
+
+	Priority  todo.Priority `json:"priority"`
+	Important bool          `json:"important"`
+}
+
+type Template struct {
+	ID        int64  `json:"id"`
+	UserID    int64  `json:"user_id"`
+	Name      string `json:"name"`
+	GroupName string `json:"group_name"` // ชื่อ group ที่ได้ตอน instantiate (ใส่ตัวแปรได้)
+	Color     string `json:"color"`
+	Items     []Item `json:"items"`
+
+	// ตัวแปรที่ต้องส่งมาตอน instantiate (คำนวณจาก group_name + items ไม่ได้เก็บใน DB)
+	Variables []string `json:"variables"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ใช้กับ POST /templates
+type CreateTemplateInput struct {
+	Name      string `json:"name"`
+	GroupName string `json:"group_name"` // ไม่ส่ง = ใช้ชื่อ template
+	Color     string `json:"color"`      // ไม่ส่ง = สีของ group ต้นแบบ / DefaultColor
+	Items     []Item `json:"items"`
+	// บันทึก todos ของ group ที่มีอยู่เป็น template (ใช้แทน items ที่ส่งมา)
+	FromGroupID *int64 `json:"from_group_id"`
+}
+
+// ใช้กับ PUT /templates/{id} (แทนที่ทั้งก้อน)
+type UpdateTemplateInput struct {
+	Name      string `json:"name"`
+	GroupName string `json:"group_name"`
+	Color     string `json:"color"`
+	Items     []Item `json:"items"`
+}
+
+// ใช้กับ POST /templates/{id}/instantiate
+type InstantiateInput struct {
+	AnchorDate  *civil.Date       `json:"anchor_date"` // ไม่ส่ง = วันนี้
+	Variables   map[string]string `json:"variables"`
+	Name        *string           `json:"name"`         // ไม่ส่ง = group_name ของ template
+	WorkspaceID *int64            `json:"workspace_id"` // ไม่ส่ง = personal workspace
+	ParentID    *int64            `json:"parent_id"`
+}
+
+// collectVariables ชื่อตัวแปรทั้งหมดที่ใช้ใน template (ไม่ซ้ำ ตามลำดับที่เจอ ไม่รวม built-in)
+func (t *Template) collectVariables() {
+	seen := map[string]bool{}
+	vars := []string{}
+
+	add := func(s string) {
+		for _, m := range variablePattern.FindAllStringSubmatch(s, -1) {
+			name := m[1]
+			if builtinVariables[name] || seen[name] {
+				continue
+			}
+			seen[name] = true
+			vars = append(vars, name)
+		}
+	}
+
+	add(t.GroupName)
+	for _, it := range t.Items {
+		add(it.Title)
+		if it.Description != nil {
+			add(*it.Description)
+		}
+	}
+
+	t.Variables = vars
+}
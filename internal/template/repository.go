@@ -0,0 +1,124 @@
+package template
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ลำดับต้องตรงกับ templateDest
+const templateColumns = `id, user_id, name, group_name, color, items, created_at, updated_at`
+
+func templateDest(t *Template) []any {
+	return []any{
+		&t.ID,
+		&t.UserID,
+		&t.Name,
+		&t.GroupName,
+		&t.Color,
+		&t.Items,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	}
+}
+
+// template เป็นของ user คนเดียว ทุก method scope ด้วย userID
+type Repository interface {
+	Create(ctx context.Context, t *Template) error
+	ListByUser(ctx context.Context, userID int64) ([]Template, error)
+	GetByID(ctx context.Context, id, userID int64) (*Template, error)
+	Update(ctx context.Context, t *Template) error
+	Delete(ctx context.Context, id, userID int64) error
+}
+
+type postgresRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Repository {
+	return &postgresRepo{db: db}
+}
+
+func (r *postgresRepo) Create(ctx context.Context, t *Template) error {
+	return r.db.QueryRow(ctx, `
+		INSERT INTO templates (user_id, name, group_name, color, items)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`, t.UserID, t.Name, t.GroupName, t.Color, t.Items).Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+}
+
+func (r *postgresRepo) ListByUser(ctx context.Context, userID int64) ([]Template, error) {
+	query := `
+		SELECT ` + templateColumns + `
+		FROM templates
+		WHERE user_id = $1
+		ORDER BY name, id
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []Template{}
+	for rows.Next() {
+		var t Template
+		if err := rows.Scan(templateDest(&t)...); err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return templates, nil
+}
+
+func (r *postgresRepo) GetByID(ctx context.Context, id, userID int64) (*Template, error) {
+	query := `
+		SELECT ` + templateColumns + `
+		FROM templates
+		WHERE id = $1 AND user_id = $2
+	`
+
+	var t Template
+	if err := r.db.QueryRow(ctx, query, id, userID).Scan(templateDest(&t)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (r *postgresRepo) Update(ctx context.Context, t *Template) error {
+	err := r.db.QueryRow(ctx, `
+		UPDATE templates
+		SET name = $1, group_name = $2, color = $3, items = $4
+		WHERE id = $5 AND user_id = $6
+		RETURNING created_at, updated_at
+	`, t.Name, t.GroupName, t.Color, t.Items, t.ID, t.UserID).Scan(&t.CreatedAt, &t.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+func (r *postgresRepo) Delete(ctx context.Context, id, userID int64) error {
+	cmdTag, err := r.db.Exec(ctx, `DELETE FROM templates WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,333 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Nasaee/go-todo-backend/internal/auth"
+	"github.com/Nasaee/go-todo-backend/internal/todo"
+	"github.com/Nasaee/go-todo-backend/internal/todogroup"
+	"github.com/Nasaee/go-todo-backend/pkg/civil"
+)
+
+type Service interface {
+	Create(ctx context.Context, userID int64, input CreateTemplateInput) (*Template, error)
+	List(ctx context.Context, userID int64) ([]Template, error)
+	Get(ctx context.Context, id, userID int64) (*Template, error)
+	Update(ctx context.Context, id, userID int64, input UpdateTemplateInput) (*Template, error)
+	Delete(ctx context.Context, id, userID int64) error
+	// Instantiate สร้าง group + todos ใหม่จาก template (แทนค่าตัวแปร + คำนวณวันจาก anchor)
+	Instantiate(ctx context.Context, id, userID int64, input InstantiateInput) (*todogroup.TodoGroup, error)
+}
+
+type service struct {
+	repo   Repository
+	groups todogroup.TodoGroupRepository
+}
+
+func NewService(repo Repository, groups todogroup.TodoGroupRepository) Service {
+	return &service{repo: repo, groups: groups}
+}
+
+// normalize trim / เติมค่า default แล้ว validate ก่อนบันทึก
+func normalize(t *Template) error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return ErrEmptyName
+	}
+
+	t.GroupName = strings.TrimSpace(t.GroupName)
+	if t.GroupName == "" {
+		t.GroupName = t.Name
+	}
+
+	color, err := todogroup.NormalizeColor(t.Color)
+	if err != nil {
+		return err
+	}
+	t.Color = color
+
+	if len(t.Items) > MaxItems {
+		return ErrTooManyItems
+	}
+	if t.Items == nil {
+		t.Items = []Item{}
+	}
+	for i := range t.Items {
+		it := &t.Items[i]
+		if it.Title = strings.TrimSpace(it.Title); it.Title == "" {
+			return ErrEmptyTitle
+		}
+		if it.DayOffset < 0 || (it.DurationDays != nil && *it.DurationDays < 0) {
+			return ErrInvalidOffset
+		}
+		if err := normalizeTime(it); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+const minutesPerDay = 24 * 60
+
+// normalizeTime ตรวจเวลาของ item ที่มีเวลา (item ทั้งวันห้ามมี duration_minutes / timezone)
+func normalizeTime(it *Item) error {
+	if it.StartMinute == nil {
+		if it.DurationMinutes != nil {
+			return ErrInvalidTime
+		}
+		it.Timezone = nil
+		return nil
+	}
+
+	if *it.StartMinute < 0 || *it.StartMinute >= minutesPerDay || it.DurationDays != nil ||
+		(it.DurationMinutes != nil && *it.DurationMinutes < 0) {
+		return ErrInvalidTime
+	}
+	// "Local" คือ timezone ของ server ไม่ใช่ของ user
+	if it.Timezone == nil || *it.Timezone == "" || *it.Timezone == "Local" {
+		return ErrInvalidTime
+	}
+	if _, err := time.LoadLocation(*it.Timezone); err != nil {
+		return ErrInvalidTime
+	}
+	return nil
+}
+
+func (s *service) Create(ctx context.Context, userID int64, input CreateTemplateInput) (*Template, error) {
+	t := &Template{
+		UserID:    userID,
+		Name:      input.Name,
+		GroupName: input.GroupName,
+		Color:     input.Color,
+		Items:     input.Items,
+	}
+
+	if input.FromGroupID != nil {
+		if err := s.captureGroup(ctx, t, *input.FromGroupID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := normalize(t); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, t); err != nil {
+		return nil, err
+	}
+
+	t.collectVariables()
+	return t, nil
+}
+
+// captureGroup เอา todos ของ group ที่ user มองเห็นมาเป็น items (วันแปลงเป็นระยะห่างจาก todo แรกสุด)
+func (s *service) captureGroup(ctx context.Context, t *Template, groupID int64) error {
+	g, err := s.groups.GetByID(ctx, groupID, t.UserID)
+	if err != nil {
+		return err
+	}
+	if !auth.InWorkspace(ctx, g.WorkspaceID) || !g.Role.AtLeast(todogroup.RoleViewer) {
+		return todogroup.ErrNotFound
+	}
+
+	seeds, err := s.groups.ListTodoSeeds(ctx, groupID)
+	if err != nil {
+		return err
+	}
+
+	if t.Name == "" {
+		t.Name = g.Name
+	}
+	if t.GroupName == "" {
+		t.GroupName = g.Name
+	}
+	if t.Color == "" {
+		t.Color = g.Color
+	}
+
+	earliest, _ := todogroup.EarliestStart(seeds)
+	t.Items = make([]Item, 0, len(seeds))
+	for _, seed := range seeds {
+		it := Item{
+			Title:       seed.Title,
+			Description: seed.Description,
+			DayOffset:   seed.DateStart.DaysSince(earliest),
+			Priority:    todo.Priority(seed.Priority),
+			Important:   seed.Important,
+		}
+		switch {
+		case seed.StartAt != nil:
+			start := seed.StartAt.In(todogroup.SeedLocation(&seed))
+			m := start.Hour()*60 + start.Minute()
+			it.StartMinute = &m
+			it.Timezone = seed.Timezone
+			if seed.EndAt != nil {
+				d := int(seed.EndAt.Sub(*seed.StartAt).Minutes())
+				it.DurationMinutes = &d
+			}
+		case seed.DateEnd != nil:
+			d := seed.DateEnd.DaysSince(seed.DateStart)
+			it.DurationDays = &d
+		}
+		t.Items = append(t.Items, it)
+	}
+
+	return nil
+}
+
+func (s *service) List(ctx context.Context, userID int64) ([]Template, error) {
+	templates, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range templates {
+		templates[i].collectVariables()
+	}
+	return templates, nil
+}
+
+func (s *service) Get(ctx context.Context, id, userID int64) (*Template, error) {
+	t, err := s.repo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	t.collectVariables()
+	return t, nil
+}
+
+func (s *service) Update(ctx context.Context, id, userID int64, input UpdateTemplateInput) (*Template, error) {
+	t := &Template{
+		ID:        id,
+		UserID:    userID,
+		Name:      input.Name,
+		GroupName: input.GroupName,
+		Color:     input.Color,
+		Items:     input.Items,
+	}
+	if err := normalize(t); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, t); err != nil {
+		return nil, err
+	}
+
+	t.collectVariables()
+	return t, nil
+}
+
+func (s *service) Delete(ctx context.Context, id, userID int64) error {
+	return s.repo.Delete(ctx, id, userID)
+}
+
+func (s *service) Instantiate(ctx context.Context, id, userID int64, input InstantiateInput) (*todogroup.TodoGroup, error) {
+	t, err := s.repo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	anchor := civil.Today(time.Local)
+	if input.AnchorDate != nil {
+		anchor = *input.AnchorDate
+	}
+
+	vars := map[string]string{}
+	for k, v := range input.Variables {
+		vars[k] = v
+	}
+	vars["date"] = anchor.String()
+
+	// เช็คตัวแปรครบก่อนสร้างอะไรทั้งนั้น
+	t.collectVariables()
+	var missing []string
+	for _, name := range t.Variables {
+		if _, ok := vars[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("%w: %s", ErrMissingVariable, strings.Join(missing, ", "))
+	}
+
+	name := strings.TrimSpace(render(t.GroupName, vars))
+	if input.Name != nil {
+		name = strings.TrimSpace(*input.Name)
+	}
+	if name == "" {
+		return nil, todogroup.ErrEmptyName
+	}
+
+	seeds := make([]todogroup.TodoSeed, 0, len(t.Items))
+	for _, it := range t.Items {
+		start := anchor.AddDays(it.DayOffset)
+		seed := todogroup.TodoSeed{
+			Title:     render(it.Title, vars),
+			DateStart: start,
+			Priority:  int16(it.Priority),
+			Important: it.Important,
+		}
+		if it.Description != nil {
+			desc := render(*it.Description, vars)
+			seed.Description = &desc
+		}
+		if it.DurationDays != nil {
+			end := start.AddDays(*it.DurationDays)
+			seed.DateEnd = &end
+		}
+		if it.StartMinute != nil {
+			timedSeed(&seed, it)
+		}
+		seeds = append(seeds, seed)
+	}
+
+	g := &todogroup.TodoGroup{
+		Name:     name,
+		UserID:   userID,
+		Color:    t.Color,
+		ParentID: input.ParentID,
+		Role:     todogroup.RoleOwner,
+	}
+	// ไม่ระบุ workspace = personal workspace (repo เช็คสิทธิ์เขียนใน workspace ให้)
+	if input.WorkspaceID != nil {
+		g.WorkspaceID = *input.WorkspaceID
+	}
+
+	if err := s.groups.CreateWithTodos(ctx, g, seeds); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// timedSeed ใส่เวลาให้ seed ตาม start_minute ของ item ในวัน date_start (เวลาเดิมตาม timezone ข้าม DST แล้วไม่เพี้ยน)
+func timedSeed(seed *todogroup.TodoSeed, it Item) {
+	tz := *it.Timezone
+	seed.Timezone = &tz
+	loc := todogroup.SeedLocation(seed)
+
+	d := seed.DateStart
+	start := time.Date(d.Year(), d.Month(), d.Day(), 0, *it.StartMinute, 0, 0, loc)
+	seed.StartAt = &start
+	seed.DateEnd = nil
+	if it.DurationMinutes != nil {
+		end := start.Add(time.Duration(*it.DurationMinutes) * time.Minute)
+		seed.EndAt = &end
+		endDate := civil.DateOf(end.In(loc))
+		seed.DateEnd = &endDate
+	}
+}
+
+// render แทน {{name}} ด้วยค่าใน vars (ต้องเช็คแล้วว่ามีครบ)
+func render(s string, vars map[string]string) string {
+	return variablePattern.ReplaceAllStringFunc(s, func(m string) string {
+		return vars[variablePattern.FindStringSubmatch(m)[1]]
+	})
+}
@@ -0,0 +1,131 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Nasaee/go-todo-backend/pkg/civil"
+)
+
+// ===== overdue / upcoming / range (จัดกลุ่มตามวัน) =====
+
+const (
+	DefaultUpcomingDays = 7
+	// MaxAgendaDays ช่วงยาวสุดของ upcoming / range (ราว 3 เดือน พอสำหรับปฏิทินรายเดือน)
+	MaxAgendaDays = 92
+)
+
+var ErrInvalidRange = errors.New("from and to must be dates (YYYY-MM-DD) with to on or after from")
+
+// ErrRangeTooLong ครอบด้วย ErrInvalidRange (handler ตอบ 400 เหมือนกัน)
+var ErrRangeTooLong = fmt.Errorf("%w: range can span at most %d days", ErrInvalidRange, MaxAgendaDays)
+
+// UpcomingQuery ใช้กับ GET /todos/upcoming
+type UpcomingQuery struct {
+	ListOptions
+
+	Days int // ?days= นับวันนี้เป็นวันแรก (ไม่ส่ง = DefaultUpcomingDays)
+}
+
+// RangeQuery ใช้กับ GET /todos/range และ GET /todos/completed
+type RangeQuery struct {
+	ListOptions
+
+	// ?from= / ?to= (YYYY-MM-DD รวมปลายทั้งสองข้าง)
+	From civil.Date
+	To   civil.Date
+}
+
+// Agenda todos จัดกลุ่มตามวัน (วันคิดตาม timezone ของ user) เอาไปวาดปฏิทินได้เลย
+//   - upcoming / range: มีทุกวันในช่วง (วันว่าง = todos ว่าง) todo หลายวันอยู่ในทุกวันที่คาบเกี่ยว
+//   - overdue: เฉพาะวันที่มี todo เลยกำหนด จัดตามวันครบกำหนด
+//   - completed: เฉพาะวันที่มี todo เสร็จ จัดตามวันที่ทำเสร็จ ล่าสุดก่อน
+type Agenda struct {
+	Timezone string      `json:"timezone"`
+	Days     []AgendaDay `json:"days"`
+}
+
+type AgendaDay struct {
+	Date  civil.Date `json:"date"`
+	Todos []Todo     `json:"todos"`
+}
+
+// span วันแรก / วันสุดท้ายของ todo ตามปฏิทินของ loc
+// todo ที่มีเวลาจบเที่ยงคืนพอดีไม่นับวันถัดไป
+func span(t *Todo, loc *time.Location) (civil.Date, civil.Date) {
+	if t.StartAt == nil {
+		last := t.DateStart
+		if t.DateEnd != nil {
+			last = *t.DateEnd
+		}
+		return t.DateStart, last
+	}
+
+	first := civil.DateOf(t.StartAt.In(loc))
+	if t.EndAt == nil || !t.EndAt.After(*t.StartAt) {
+		return first, first
+	}
+	end := t.EndAt.In(loc)
+	last := civil.DateOf(end)
+	if end.Equal(last.StartIn(loc)) {
+		last = last.AddDays(-1)
+	}
+	return first, last
+}
+
+// buildCalendar วางแต่ละ todo ลงทุกวันในช่วง w ที่มันคาบเกี่ยว (todos เรียงมาแล้ว)
+func buildCalendar(todos []Todo, w DayWindow) []AgendaDay {
+	n := w.To.DaysSince(w.From)
+	days := make([]AgendaDay, n)
+	for i := range days {
+		days[i] = AgendaDay{Date: w.From.AddDays(i), Todos: []Todo{}}
+	}
+
+	for _, t := range todos {
+		first, last := span(&t, w.Loc)
+		i, j := max(first.DaysSince(w.From), 0), min(last.DaysSince(w.From), n-1)
+		for ; i <= j; i++ {
+			days[i].Todos = append(days[i].Todos, t)
+		}
+	}
+	return days
+}
+
+// DefaultCompletedDays ช่วงของ logbook ถ้าไม่ส่ง from (นับย้อนจาก to)
+const DefaultCompletedDays = 30
+
+// groupByCompleted จัด todos ตามวันที่ทำเสร็จ (todos เรียง completed_at ล่าสุดก่อนมาแล้ว)
+func groupByCompleted(todos []Todo, loc *time.Location) []AgendaDay {
+	days := []AgendaDay{}
+	for _, t := range todos {
+		d := civil.DateOf(t.CompletedAt.In(loc))
+		if n := len(days); n == 0 || !days[n-1].Date.Equal(d.Time) {
+			days = append(days, AgendaDay{Date: d, Todos: []Todo{}})
+		}
+		days[len(days)-1].Todos = append(days[len(days)-1].Todos, t)
+	}
+	return days
+}
+
+// groupByDue จัด todos ตามวันครบกำหนด (วันสุดท้ายของ span) เรียงจากวันเก่าสุด
+func groupByDue(todos []Todo, loc *time.Location) []AgendaDay {
+	byDate := map[string]int{}
+	days := []AgendaDay{}
+	for _, t := range todos {
+		_, due := span(&t, loc)
+		i, ok := byDate[due.String()]
+		if !ok {
+			i = len(days)
+			byDate[due.String()] = i
+			days = append(days, AgendaDay{Date: due, Todos: []Todo{}})
+		}
+		days[i].Todos = append(days[i].Todos, t)
+	}
+
+	slices.SortStableFunc(days, func(a, b AgendaDay) int {
+		return a.Date.Compare(b.Date.Time)
+	})
+	return days
+}
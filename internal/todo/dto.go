@@ -1,23 +1,160 @@
 // dto.go
 package todo
 
-import "time"
+import (
+	"time"
+
+	"github.com/Nasaee/go-todo-backend/pkg/civil"
+	"github.com/Nasaee/go-todo-backend/pkg/nullable"
+)
 
 // ใช้ตอนสร้าง
+// todo ทั้งวัน: ส่ง date_start (+ date_end) เป็น YYYY-MM-DD
+// todo ที่มีเวลา: ส่ง start_at (+ end_at) เป็น RFC3339 ไม่ต้องส่ง date_start (คิดจาก start_at ตาม timezone)
 type CreateTodoInput struct {
-	Title       string     `json:"title"`
-	Description *string    `json:"description"`
-	DateStart   time.Time  `json:"date_start"`
-	DateEnd     *time.Time `json:"date_end"`
-	TodoGroupID int64      `json:"todo_group_id"`
+	Title       string      `json:"title"`
+	Description *string     `json:"description"`
+	DateStart   civil.Date  `json:"date_start"`
+	DateEnd     *civil.Date `json:"date_end"`
+	StartAt     *time.Time  `json:"start_at"`
+	EndAt       *time.Time  `json:"end_at"`
+	TodoGroupID int64       `json:"todo_group_id"` // ไม่ส่ง = inbox
+	AssigneeID  *int64      `json:"assignee_id"`
+
+	Priority     Priority `json:"priority"` // ไม่ส่ง = none
+	Important    bool     `json:"important"`
+	AutoComplete bool     `json:"auto_complete"` // ติ๊ก checklist ครบแล้วเสร็จเอง
+
+	TagIDs []int64 `json:"tag_ids"` // tag ของตัวเองเท่านั้น
+
+	// เกิดซ้ำ: RRULE ตาม RFC 5545 เช่น "FREQ=WEEKLY;BYDAY=MO,WE" (date_start = occurrence แรก)
+	RRule *string `json:"rrule"`
+	// IANA zone ของ start_at / end_at และที่ใช้นับวันของ rrule (ไม่ส่ง = timezone ของ user)
+	Timezone *string `json:"timezone"`
 }
 
-// ใช้ตอนแก้ไข
+// ใช้กับ PUT /todos/{id} = แทนที่ทั้งก้อน field ที่ไม่ส่งมาถูกล้างเป็นค่าว่าง / false / ไม่มีคนรับผิดชอบ
 type UpdateTodoInput struct {
-	Title       *string    `json:"title"`
-	Description *string    `json:"description"`
-	DateStart   *time.Time `json:"date_start"`
-	DateEnd     *time.Time `json:"date_end"`
-	IsSuccess   *bool      `json:"is_success"`
-	TodoGroupID *int64     `json:"todo_group_id"`
+	Title       string      `json:"title"` // ต้องมี
+	Description *string     `json:"description"`
+	DateStart   civil.Date  `json:"date_start"` // ต้องมี (ยกเว้นส่ง start_at)
+	DateEnd     *civil.Date `json:"date_end"`
+	StartAt     *time.Time  `json:"start_at"` // ไม่ส่ง = todo ทั้งวัน
+	EndAt       *time.Time  `json:"end_at"`
+	IsSuccess   bool        `json:"is_success"`
+	TodoGroupID int64       `json:"todo_group_id"` // ต้องมี
+	AssigneeID  *int64      `json:"assignee_id"`
+
+	Priority     Priority `json:"priority"`
+	Important    bool     `json:"important"`
+	AutoComplete bool     `json:"auto_complete"`
+
+	TagIDs []int64 `json:"tag_ids"` // ไม่ส่ง = เอา tag (ของตัวเอง) ออกหมด
+
+	RRule    *string `json:"rrule"` // ไม่ส่ง = เลิกเกิดซ้ำ
+	Timezone *string `json:"timezone"`
+}
+
+// ใช้กับ PATCH /todos/{id} (JSON Merge Patch, RFC 7396)
+// ไม่ส่ง key = ไม่แก้, ส่ง null = ล้างค่า (เฉพาะ field ที่ว่างได้), ส่งค่า = แก้เป็นค่านั้น
+type PatchTodoInput struct {
+	Title        nullable.Field[string]     `json:"title"`
+	Description  nullable.Field[string]     `json:"description"`
+	DateStart    nullable.Field[civil.Date] `json:"date_start"`
+	DateEnd      nullable.Field[civil.Date] `json:"date_end"`
+	StartAt      nullable.Field[time.Time]  `json:"start_at"` // null = เปลี่ยนเป็น todo ทั้งวัน
+	EndAt        nullable.Field[time.Time]  `json:"end_at"`
+	IsSuccess    nullable.Field[bool]       `json:"is_success"`
+	TodoGroupID  nullable.Field[int64]      `json:"todo_group_id"`
+	AssigneeID   nullable.Field[int64]      `json:"assignee_id"`
+	Priority     nullable.Field[Priority]   `json:"priority"` // null = none
+	Important    nullable.Field[bool]       `json:"important"`
+	AutoComplete nullable.Field[bool]       `json:"auto_complete"`
+	RRule        nullable.Field[string]     `json:"rrule"` // null = เลิกเกิดซ้ำ
+	Timezone     nullable.Field[string]     `json:"timezone"`
+
+	// tag_ids = แทนที่ทั้งชุด (null = เอาออกหมด) แล้วค่อยเพิ่ม / ลบตาม add_tag_ids / remove_tag_ids
+	TagIDs       nullable.Field[[]int64] `json:"tag_ids"`
+	AddTagIDs    []int64                 `json:"add_tag_ids"`
+	RemoveTagIDs []int64                 `json:"remove_tag_ids"`
+}
+
+// ใช้ตอนย้ายลำดับ (POST /todos/{id}/move)
+// ส่งตัวใดตัวหนึ่งหรือทั้งคู่ก็ได้ (ทั้งคู่ = ต้องเป็น todo ที่อยู่ติดกัน)
+type MoveTodoInput struct {
+	BeforeID *int64 `json:"before_id"` // วางไว้ก่อน todo นี้
+	AfterID  *int64 `json:"after_id"`  // วางไว้หลัง todo นี้
+}
+
+// ใช้กับ POST /todos/{id}/checklist (ต่อท้าย checklist เสมอ)
+type CreateChecklistItemInput struct {
+	Title  string `json:"title"`
+	IsDone bool   `json:"is_done"`
+}
+
+// ใช้กับ PATCH /todos/{id}/checklist/{itemID} (ไม่ส่ง = ไม่แก้)
+type PatchChecklistItemInput struct {
+	Title  nullable.Field[string] `json:"title"`
+	IsDone nullable.Field[bool]   `json:"is_done"`
+}
+
+// ListOptions ใช้กับ list endpoint ทั้งหมด (GET /todos, /today, /tomorrow, /this-week, /overdue, /upcoming, /range, /completed, /todo-groups/{id}/todos)
+type ListOptions struct {
+	IncludeArchived    bool // ?include=archived = รวม todos ใน group ที่ archive แล้ว
+	IncludeDescendants bool // ?include=descendants = รวม todos ใน group ลูกหลานด้วย (เฉพาะ /todo-groups/{id}/todos)
+	IncludeCompleted   bool // ?include=completed = รวม todos ที่เสร็จแล้ว (ไม่ส่ง = ซ่อน)
+
+	AssigneeID *int64 // ?assignee_id={id} หรือ ?assignee_id=me
+	Unassigned bool   // ?assignee_id=none = เฉพาะที่ยังไม่มีคนรับผิดชอบ
+
+	Priorities []Priority // ?priority=high,urgent (ว่าง = ทุกระดับ)
+	Important  *bool      // ?important=true|false
+
+	TagIDs  []int64 // ?tag_id=1,2 (tag ของตัวเอง)
+	AllTags bool    // ?tag_match=all = ต้องมีครบทุก tag (ไม่ส่ง / any = มีอันใดอันหนึ่ง)
+
+	// nil = ทุก workspace (route เดิม) service ใส่ให้จาก context ของ /workspaces/{wid}/...
+	WorkspaceID *int64
+}
+
+// ListQuery = filter / sort / cursor ของ GET /todos (ต่อจาก ListOptions ที่ใช้ร่วมกับทุก list)
+type ListQuery struct {
+	ListOptions
+
+	GroupID *int64 // ?group_id= (ใช้คู่กับ include=descendants ได้)
+	// service แปลง GroupID (+ ลูกหลาน) เป็นชุด id ให้ repo
+	GroupIDs []int64
+
+	IsSuccess *bool // ?is_success=true|false (ส่งมา = ไม่ต้องใส่ include=completed)
+
+	// ?date_from= / ?date_to= (YYYY-MM-DD) = todo ที่ช่วง date_start..date_end คาบเกี่ยวกับช่วงนี้
+	DateFrom *civil.Date
+	DateTo   *civil.Date
+
+	Text string // ?q= หาใน title / description
+
+	// ?created_from= / ?created_to= / ?updated_from= / ?updated_to= (RFC3339 หรือ YYYY-MM-DD, รวมปลายทั้งสองข้าง)
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+
+	Sort   string // ?sort=position|date_start|created_at|updated_at|title|priority|important (ไม่ส่ง = position)
+	Desc   bool   // ?order=desc
+	Limit  int    // ?limit= (ไม่ส่ง = DefaultListLimit, มากสุด MaxListLimit)
+	Cursor string // ?cursor= = next_cursor จากหน้าก่อน
+}
+
+// TodoPage ผลลัพธ์ทีละหน้า next_cursor = null คือหน้าสุดท้าย
+type TodoPage struct {
+	Items      []Todo  `json:"items"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+// SearchQuery ใช้กับ GET /todos/search (เรียงตามความเกี่ยวข้อง ไม่มี cursor)
+type SearchQuery struct {
+	ListOptions
+
+	Text  string // ?q=
+	Limit int    // ?limit= (ไม่ส่ง = DefaultSearchLimit, มากสุด MaxSearchLimit)
 }
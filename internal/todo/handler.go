@@ -4,37 +4,219 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Nasaee/go-todo-backend/internal/auth"
+	"github.com/Nasaee/go-todo-backend/internal/todogroup"
+	"github.com/Nasaee/go-todo-backend/pkg/civil"
+	"github.com/Nasaee/go-todo-backend/pkg/rrule"
 	"github.com/Nasaee/go-todo-backend/pkg/utils"
 	"github.com/go-chi/chi/v5"
 )
 
 // =============== context + helper =================
 
-// ตรงนี้ให้แก้ให้ match กับ auth middleware ของเพื่อน
-// สมมติ middleware set ctx.Value("userID") = int64(userID)
-type ctxKey string
-
-const userIDCtxKey ctxKey = "userID"
-
+// userID มาจาก auth.AuthMiddleware (ต้องใช้ key ของ package auth ตัวเดียวกัน)
 func userIDFromContext(ctx context.Context) (int64, error) {
-	val := ctx.Value(userIDCtxKey)
-	if val == nil {
+	userID, ok := auth.UserIDFromContext(ctx)
+	if !ok || userID == 0 {
 		return 0, errors.New("user not authenticated")
 	}
 
-	userID, ok := val.(int64)
-	if !ok {
-		return 0, errors.New("invalid user id type in context")
+	return userID, nil
+}
+
+// listOptionsFromRequest อ่าน query ที่ใช้ร่วมกันของทุก list endpoint
+// ?include=archived,descendants,completed  ?assignee_id={id}|me|none  ?priority=high,urgent  ?important=true|false
+// ?tag_id=1,2&tag_match=any|all
+func listOptionsFromRequest(r *http.Request, userID int64) (ListOptions, error) {
+	include := utils.QueryFlags(r, "include")
+	opts := ListOptions{
+		IncludeArchived:    include["archived"],
+		IncludeDescendants: include["descendants"],
+		IncludeCompleted:   include["completed"],
 	}
 
-	if userID == 0 {
-		return 0, errors.New("invalid user id")
+	switch v := r.URL.Query().Get("assignee_id"); v {
+	case "":
+	case "me":
+		opts.AssigneeID = &userID
+	case "none":
+		opts.Unassigned = true
+	default:
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return ListOptions{}, errors.New("invalid assignee_id")
+		}
+		opts.AssigneeID = &id
 	}
 
-	return userID, nil
+	if v := r.URL.Query().Get("priority"); v != "" {
+		for _, name := range strings.Split(v, ",") {
+			p, err := ParsePriority(name)
+			if err != nil {
+				return ListOptions{}, err
+			}
+			opts.Priorities = append(opts.Priorities, p)
+		}
+	}
+
+	if v := r.URL.Query().Get("important"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return ListOptions{}, errors.New("invalid important")
+		}
+		opts.Important = &b
+	}
+
+	if v := r.URL.Query().Get("tag_id"); v != "" {
+		seen := map[int64]bool{}
+		for _, s := range strings.Split(v, ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+			if err != nil {
+				return ListOptions{}, errors.New("invalid tag_id")
+			}
+			// ตัดตัวซ้ำ ไม่งั้น tag_match=all นับครบไม่ได้
+			if !seen[id] {
+				seen[id] = true
+				opts.TagIDs = append(opts.TagIDs, id)
+			}
+		}
+	}
+
+	switch r.URL.Query().Get("tag_match") {
+	case "", "any":
+	case "all":
+		opts.AllTags = true
+	default:
+		return ListOptions{}, errors.New("tag_match must be any or all")
+	}
+
+	return opts, nil
+}
+
+// listQueryFromRequest อ่าน filter / sort / cursor ของ GET /todos (ดู ListQuery)
+func listQueryFromRequest(r *http.Request, userID int64) (ListQuery, error) {
+	opts, err := listOptionsFromRequest(r, userID)
+	if err != nil {
+		return ListQuery{}, err
+	}
+
+	v := r.URL.Query()
+	q := ListQuery{
+		ListOptions: opts,
+		Text:        strings.TrimSpace(v.Get("q")),
+		Sort:        v.Get("sort"),
+		Cursor:      v.Get("cursor"),
+	}
+
+	if s := v.Get("group_id"); s != "" {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return ListQuery{}, errors.New("invalid group_id")
+		}
+		q.GroupID = &id
+	}
+
+	if s := v.Get("is_success"); s != "" {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return ListQuery{}, errors.New("invalid is_success")
+		}
+		q.IsSuccess = &b
+	}
+
+	if q.Sort != "" {
+		if _, ok := sortFields[q.Sort]; !ok {
+			return ListQuery{}, ErrInvalidSort
+		}
+	}
+
+	switch v.Get("order") {
+	case "", "asc":
+	case "desc":
+		q.Desc = true
+	default:
+		return ListQuery{}, errors.New("order must be asc or desc")
+	}
+
+	if s := v.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return ListQuery{}, errors.New("invalid limit")
+		}
+		q.Limit = n
+	}
+
+	dates := []struct {
+		key string
+		dst **civil.Date
+	}{
+		{"date_from", &q.DateFrom},
+		{"date_to", &q.DateTo},
+	}
+	for _, d := range dates {
+		if s := v.Get(d.key); s != "" {
+			t, err := civil.ParseDate(s)
+			if err != nil {
+				return ListQuery{}, errors.New("invalid " + d.key + " (use YYYY-MM-DD)")
+			}
+			*d.dst = &t
+		}
+	}
+
+	times := []struct {
+		key   string
+		dst   **time.Time
+		endOf bool
+	}{
+		{"created_from", &q.CreatedFrom, false},
+		{"created_to", &q.CreatedTo, true},
+		{"updated_from", &q.UpdatedFrom, false},
+		{"updated_to", &q.UpdatedTo, true},
+	}
+	for _, p := range times {
+		if s := v.Get(p.key); s != "" {
+			t, err := parseTimeParam(s, p.endOf)
+			if err != nil {
+				return ListQuery{}, errors.New("invalid " + p.key + " (use RFC3339 or YYYY-MM-DD)")
+			}
+			*p.dst = &t
+		}
+	}
+
+	return q, nil
+}
+
+// parseTimeParam รับ RFC3339 หรือ YYYY-MM-DD (UTC)
+// endOf = ใช้เป็นปลายช่วง วันเปล่า ๆ จึงนับถึงสิ้นวันนั้น
+func parseTimeParam(s string, endOf bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOf {
+		// timestamptz ละเอียดถึง microsecond
+		t = t.AddDate(0, 0, 1).Add(-time.Microsecond)
+	}
+	return t, nil
+}
+
+// writeDecodeError ค่า enum (priority) / วันที่ผิดรูปแบบ บอกให้ชัด ที่เหลือ = body พัง
+func writeDecodeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrInvalidPriority) || errors.Is(err, civil.ErrInvalidDate) {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	utils.WriteError(w, http.StatusBadRequest, "invalid json body")
 }
 
 type errorResponse struct {
@@ -51,7 +233,8 @@ func NewHandler(svc Service) *Handler {
 	return &Handler{svc: svc}
 }
 
-// GET /api/todos
+// GET /api/todos?group_id=&is_success=&date_from=&date_to=&q=&sort=&order=&limit=&cursor=
+// คืน {items, next_cursor} ส่ง next_cursor กลับมาเป็น ?cursor= เพื่อดึงหน้าถัดไป
 func (h *Handler) ListTodos(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -61,13 +244,59 @@ func (h *Handler) ListTodos(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	todos, err := h.svc.ListTodos(ctx, userID)
+	q, err := listQueryFromRequest(r, userID)
 	if err != nil {
-		utils.WriteJSON(w, http.StatusInternalServerError, "failed to list todos")
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	utils.WriteJSON(w, http.StatusOK, todos)
+	page, err := h.svc.ListTodos(ctx, userID, q)
+	if err != nil {
+		writeListError(w, err, "failed to list todos")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, page)
+}
+
+// GET /api/todos/search?q=&limit= (+ include / assignee_id เหมือน list อื่น)
+// คำค้นทุกคำ match แบบขึ้นต้น (prefix) หรือเป็นส่วนหนึ่งของ title / description
+func (h *Handler) SearchTodos(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		utils.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	opts, err := listOptionsFromRequest(r, userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	q := SearchQuery{ListOptions: opts, Text: r.URL.Query().Get("q")}
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			utils.WriteError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		q.Limit = n
+	}
+
+	results, err := h.svc.SearchTodos(ctx, userID, q)
+	if err != nil {
+		if errors.Is(err, ErrEmptySearch) {
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.WriteError(w, http.StatusInternalServerError, "failed to search todos")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, results)
 }
 
 // GET /api/todos/today
@@ -80,7 +309,13 @@ func (h *Handler) ListTodayTodos(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	todos, err := h.svc.ListTodayTodos(ctx, userID)
+	opts, err := listOptionsFromRequest(r, userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	todos, err := h.svc.ListTodayTodos(ctx, userID, opts)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, "failed to list today todos")
 		return
@@ -99,7 +334,13 @@ func (h *Handler) ListTomorrow(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	todos, err := h.svc.ListTomorrowTodos(ctx, userID)
+	opts, err := listOptionsFromRequest(r, userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	todos, err := h.svc.ListTomorrowTodos(ctx, userID, opts)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, "failed to list tomorrow todos")
 		return
@@ -118,7 +359,13 @@ func (h *Handler) ListThisWeek(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	todos, err := h.svc.ListThisWeekTodos(ctx, userID)
+	opts, err := listOptionsFromRequest(r, userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	todos, err := h.svc.ListThisWeekTodos(ctx, userID, opts)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, "failed to list this week todos")
 		return
@@ -127,6 +374,254 @@ func (h *Handler) ListThisWeek(w http.ResponseWriter, r *http.Request) {
 	utils.WriteJSON(w, http.StatusOK, todos)
 }
 
+// GET /api/todos/overdue = todos ที่ยังไม่เสร็จและเลยกำหนด จัดกลุ่มตามวันครบกำหนด
+func (h *Handler) ListOverdue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		utils.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	opts, err := listOptionsFromRequest(r, userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	agenda, err := h.svc.ListOverdue(ctx, userID, opts)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "failed to list overdue todos")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, agenda)
+}
+
+// GET /api/todos/upcoming?days=N = N วันนับจากวันนี้ จัดกลุ่มตามวัน
+func (h *Handler) ListUpcoming(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		utils.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	opts, err := listOptionsFromRequest(r, userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	q := UpcomingQuery{ListOptions: opts}
+	if s := r.URL.Query().Get("days"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			utils.WriteError(w, http.StatusBadRequest, "invalid days")
+			return
+		}
+		q.Days = n
+	}
+
+	agenda, err := h.svc.ListUpcoming(ctx, userID, q)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "failed to list upcoming todos")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, agenda)
+}
+
+// GET /api/todos/range?from=&to= = todos ที่คาบเกี่ยวกับช่วง จัดกลุ่มตามวัน
+func (h *Handler) ListRange(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		utils.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	opts, err := listOptionsFromRequest(r, userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	q, err := rangeQueryFromRequest(r, opts)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	agenda, err := h.svc.ListRange(ctx, userID, q)
+	if err != nil {
+		if errors.Is(err, ErrInvalidRange) {
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.WriteError(w, http.StatusInternalServerError, "failed to list todos in range")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, agenda)
+}
+
+// GET /api/todos/completed?from=&to= = logbook จัดกลุ่มตามวันที่ทำเสร็จ (ไม่ส่ง = 30 วันล่าสุด)
+func (h *Handler) ListCompleted(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		utils.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	opts, err := listOptionsFromRequest(r, userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	q, err := rangeQueryFromRequest(r, opts)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	agenda, err := h.svc.ListCompleted(ctx, userID, q)
+	if err != nil {
+		if errors.Is(err, ErrInvalidRange) {
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.WriteError(w, http.StatusInternalServerError, "failed to list completed todos")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, agenda)
+}
+
+// rangeQueryFromRequest อ่าน ?from= / ?to= (YYYY-MM-DD) ไม่ส่ง = zero ให้ service ตัดสินเอง
+func rangeQueryFromRequest(r *http.Request, opts ListOptions) (RangeQuery, error) {
+	q := RangeQuery{ListOptions: opts}
+	bounds := []struct {
+		key string
+		dst *civil.Date
+	}{
+		{"from", &q.From},
+		{"to", &q.To},
+	}
+	for _, b := range bounds {
+		if s := r.URL.Query().Get(b.key); s != "" {
+			d, err := civil.ParseDate(s)
+			if err != nil {
+				return RangeQuery{}, errors.New("invalid " + b.key + " (use YYYY-MM-DD)")
+			}
+			*b.dst = d
+		}
+	}
+	return q, nil
+}
+
+// GET /api/todos/assigned-to-me (รับ query เหมือน GET /api/todos ยกเว้น assignee_id)
+func (h *Handler) ListAssignedToMe(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		utils.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	q, err := listQueryFromRequest(r, userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	q.AssigneeID, q.Unassigned = &userID, false
+
+	page, err := h.svc.ListTodos(ctx, userID, q)
+	if err != nil {
+		writeListError(w, err, "failed to list assigned todos")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, page)
+}
+
+// GET /api/todo-groups/{id}/todos
+func (h *Handler) ListGroupTodos(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		utils.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	opts, err := listOptionsFromRequest(r, userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	todos, err := h.svc.ListGroupTodos(ctx, userID, groupID, opts)
+	if err != nil {
+		if errors.Is(err, todogroup.ErrNotFound) {
+			utils.WriteError(w, http.StatusNotFound, "todo group not found")
+			return
+		}
+		utils.WriteError(w, http.StatusInternalServerError, "failed to list group todos")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, todos)
+}
+
+// GET /api/todos/matrix?urgent_days=N (+ filter เดียวกับ list อื่น)
+func (h *Handler) ListMatrix(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		utils.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	opts, err := listOptionsFromRequest(r, userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	q := MatrixQuery{ListOptions: opts}
+	if s := r.URL.Query().Get("urgent_days"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			utils.WriteError(w, http.StatusBadRequest, "invalid urgent_days")
+			return
+		}
+		q.UrgentDays = n
+	}
+
+	m, err := h.svc.ListMatrix(ctx, userID, q)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "failed to build todo matrix")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, m)
+}
+
 // GET /api/todos/{id}
 func (h *Handler) GetTodoByID(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -174,16 +669,24 @@ func (h *Handler) CreateTodo(w http.ResponseWriter, r *http.Request) {
 
 	var in CreateTodoInput
 	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "invalid json body")
+		writeDecodeError(w, err)
 		return
 	}
 
 	todo, err := h.svc.CreateTodo(ctx, userID, in)
 	if err != nil {
 		switch {
-		case errors.Is(err, ErrInvalidInput), errors.Is(err, ErrInvalidDateRange):
+		case errors.Is(err, ErrInvalidInput), errors.Is(err, ErrInvalidDateRange), errors.Is(err, ErrGroupRequired),
+			errors.Is(err, rrule.ErrInvalidRule), errors.Is(err, ErrInvalidTimezone), errors.Is(err, ErrTooManyTags),
+			errors.Is(err, ErrInvalidTimeRange), errors.Is(err, ErrEndWithoutStart):
 			utils.WriteError(w, http.StatusBadRequest, err.Error())
 			return
+		case errors.Is(err, ErrGroupForbidden):
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		case errors.Is(err, ErrGroupNotFound), errors.Is(err, ErrAssigneeNotMember), errors.Is(err, ErrTagNotFound):
+			utils.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+			return
 		default:
 			utils.WriteError(w, http.StatusInternalServerError, "failed to create todo")
 			return
@@ -193,7 +696,7 @@ func (h *Handler) CreateTodo(w http.ResponseWriter, r *http.Request) {
 	utils.WriteJSON(w, http.StatusCreated, todo)
 }
 
-// PUT /api/todos/{id}
+// PUT /api/todos/{id}?scope=this|future (แทนที่ทั้งก้อน field ที่ไม่ส่งมาถูกล้าง)
 func (h *Handler) UpdateTodo(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -217,29 +720,94 @@ func (h *Handler) UpdateTodo(w http.ResponseWriter, r *http.Request) {
 
 	var in UpdateTodoInput
 	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "invalid json body")
+		writeDecodeError(w, err)
 		return
 	}
 
-	todo, err := h.svc.UpdateTodo(ctx, id, userID, in)
+	scope, err := ParseScope(r.URL.Query().Get("scope"))
 	if err != nil {
-		switch {
-		case errors.Is(err, ErrInvalidInput), errors.Is(err, ErrInvalidDateRange):
-			utils.WriteError(w, http.StatusBadRequest, err.Error())
-			return
-		case errors.Is(err, ErrNotFound):
-			utils.WriteError(w, http.StatusNotFound, "todo not found")
-			return
-		default:
-			utils.WriteError(w, http.StatusInternalServerError, "failed to update todo")
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	todo, err := h.svc.UpdateTodo(ctx, id, userID, in, scope)
+	if err != nil {
+		writeUpdateError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, todo)
+}
+
+// PATCH /api/todos/{id}?scope=this|future (JSON Merge Patch: ไม่ส่ง = ไม่แก้, null = ล้างค่า)
+func (h *Handler) PatchTodo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		utils.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	// รับทั้ง application/merge-patch+json และ application/json (ไม่ส่ง = ถือเป็น JSON)
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mt, _, err := mime.ParseMediaType(ct)
+		if err != nil || (mt != "application/merge-patch+json" && mt != "application/json") {
+			utils.WriteError(w, http.StatusUnsupportedMediaType, "content type must be application/merge-patch+json")
 			return
 		}
 	}
 
+	var in PatchTodoInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	scope, err := ParseScope(r.URL.Query().Get("scope"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	todo, err := h.svc.PatchTodo(ctx, id, userID, in, scope)
+	if err != nil {
+		writeUpdateError(w, err)
+		return
+	}
+
 	utils.WriteJSON(w, http.StatusOK, todo)
 }
 
-// DELETE /api/todos/{id}
+// writeUpdateError ใช้ร่วมกันระหว่าง PUT / PATCH
+func writeUpdateError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidInput), errors.Is(err, ErrInvalidDateRange),
+		errors.Is(err, rrule.ErrInvalidRule), errors.Is(err, ErrInvalidTimezone), errors.Is(err, ErrTimezoneNoRule),
+		errors.Is(err, ErrTooManyTags), errors.Is(err, ErrInvalidTimeRange), errors.Is(err, ErrEndWithoutStart),
+		errors.Is(err, ErrTimedDate):
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrGroupForbidden):
+		utils.WriteError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, ErrGroupNotFound), errors.Is(err, ErrGroupOwnerChange), errors.Is(err, ErrAssigneeNotMember),
+		errors.Is(err, ErrTagNotFound):
+		utils.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, ErrForbidden):
+		utils.WriteError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, ErrNotFound):
+		utils.WriteError(w, http.StatusNotFound, "todo not found")
+	default:
+		utils.WriteError(w, http.StatusInternalServerError, "failed to update todo")
+	}
+}
+
+// DELETE /api/todos/{id}?scope=this|future
 func (h *Handler) DeleteTodo(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -261,12 +829,22 @@ func (h *Handler) DeleteTodo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.svc.DeleteTodo(ctx, id, userID)
+	scope, err := ParseScope(r.URL.Query().Get("scope"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = h.svc.DeleteTodo(ctx, id, userID, scope)
 	if err != nil {
 		if errors.Is(err, ErrNotFound) {
 			utils.WriteError(w, http.StatusNotFound, "todo not found")
 			return
 		}
+		if errors.Is(err, ErrForbidden) {
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.WriteError(w, http.StatusInternalServerError, "failed to delete todo")
 		return
 	}
@@ -274,3 +852,228 @@ func (h *Handler) DeleteTodo(w http.ResponseWriter, r *http.Request) {
 	// ถ้าอยากให้ body ว่าง ๆ ใช้ StatusNoContent ก็ได้
 	utils.WriteJSON(w, http.StatusNoContent, nil)
 }
+
+// POST /api/todos/{id}/move
+func (h *Handler) MoveTodo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		utils.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var in MoveTodoInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid json body")
+		return
+	}
+
+	todo, err := h.svc.MoveTodo(ctx, id, userID, in)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidMove):
+			utils.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+		case errors.Is(err, ErrNotFound):
+			utils.WriteError(w, http.StatusNotFound, "todo not found")
+		case errors.Is(err, ErrForbidden):
+			utils.WriteError(w, http.StatusForbidden, err.Error())
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, "failed to move todo")
+		}
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, todo)
+}
+
+// writeListError cursor / sort ผิด = 400 ที่เหลือ 500
+func writeListError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrInvalidCursor), errors.Is(err, ErrInvalidSort):
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		utils.WriteError(w, http.StatusInternalServerError, fallback)
+	}
+}
+
+// ===== Checklist =====
+
+// checklistIDs อ่าน {id} ของ todo และ {itemID} (ถ้า route มี)
+func checklistIDs(r *http.Request) (todoID, itemID int64, err error) {
+	if todoID, err = strconv.ParseInt(chi.URLParam(r, "id"), 10, 64); err != nil {
+		return 0, 0, err
+	}
+	if s := chi.URLParam(r, "itemID"); s != "" {
+		if itemID, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return 0, 0, err
+		}
+	}
+	return todoID, itemID, nil
+}
+
+// writeChecklistError ใช้ร่วมกันทุก endpoint ของ checklist
+func writeChecklistError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrInvalidInput):
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrChecklistFull), errors.Is(err, ErrInvalidChecklistMove):
+		utils.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, ErrNotFound):
+		utils.WriteError(w, http.StatusNotFound, "todo not found")
+	case errors.Is(err, ErrChecklistItemNotFound):
+		utils.WriteError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrForbidden):
+		utils.WriteError(w, http.StatusForbidden, err.Error())
+	default:
+		utils.WriteError(w, http.StatusInternalServerError, fallback)
+	}
+}
+
+// GET /api/todos/{id}/checklist
+func (h *Handler) ListChecklist(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		utils.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	todoID, _, err := checklistIDs(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	items, err := h.svc.ListChecklist(ctx, todoID, userID)
+	if err != nil {
+		writeChecklistError(w, err, "failed to list checklist")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, items)
+}
+
+// POST /api/todos/{id}/checklist
+func (h *Handler) AddChecklistItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		utils.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	todoID, _, err := checklistIDs(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var in CreateChecklistItemInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid json body")
+		return
+	}
+
+	item, err := h.svc.AddChecklistItem(ctx, todoID, userID, in)
+	if err != nil {
+		writeChecklistError(w, err, "failed to add checklist item")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, item)
+}
+
+// PATCH /api/todos/{id}/checklist/{itemID}
+func (h *Handler) PatchChecklistItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		utils.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	todoID, itemID, err := checklistIDs(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var in PatchChecklistItemInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid json body")
+		return
+	}
+
+	item, err := h.svc.PatchChecklistItem(ctx, todoID, itemID, userID, in)
+	if err != nil {
+		writeChecklistError(w, err, "failed to update checklist item")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, item)
+}
+
+// DELETE /api/todos/{id}/checklist/{itemID}
+func (h *Handler) DeleteChecklistItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		utils.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	todoID, itemID, err := checklistIDs(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	if err := h.svc.DeleteChecklistItem(ctx, todoID, itemID, userID); err != nil {
+		writeChecklistError(w, err, "failed to delete checklist item")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+// POST /api/todos/{id}/checklist/{itemID}/move (body เหมือน /todos/{id}/move แต่อ้างถึง item ใน checklist เดียวกัน)
+func (h *Handler) MoveChecklistItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		utils.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	todoID, itemID, err := checklistIDs(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var in MoveTodoInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid json body")
+		return
+	}
+
+	item, err := h.svc.MoveChecklistItem(ctx, todoID, itemID, userID, in)
+	if err != nil {
+		writeChecklistError(w, err, "failed to move checklist item")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, item)
+}
@@ -1,18 +1,81 @@
 package todo
 
-import "time"
+import (
+	"time"
+
+	"github.com/Nasaee/go-todo-backend/pkg/civil"
+)
 
 type Todo struct {
-	ID          int64      `json:"id"`
-	Title       string     `json:"title"`
-	Description *string    `json:"description,omitempty"`
-	DateStart   time.Time  `json:"date_start"`
-	DateEnd     *time.Time `json:"date_end,omitempty"`
-	IsSuccess   bool       `json:"is_success"`
+	ID          int64       `json:"id"`
+	Title       string      `json:"title"`
+	Description *string     `json:"description,omitempty"`
+	DateStart   civil.Date  `json:"date_start"` // YYYY-MM-DD
+	DateEnd     *civil.Date `json:"date_end,omitempty"`
+	IsSuccess   bool        `json:"is_success"`
+	CompletedAt *time.Time  `json:"completed_at"` // เวลาที่ทำเสร็จ (ยังไม่เสร็จ = null) service เซ็ตตาม is_success
+	Position    string      `json:"position"`
+
+	// all_day = false คือ todo ที่มีเวลา: start_at / end_at เป็นเวลาจริง timezone เป็น IANA zone ของ todo
+	// date_start / date_end ของ todo ที่มีเวลา = วันของ start_at / end_at ตาม timezone นั้น
+	AllDay   bool       `json:"all_day"`
+	StartAt  *time.Time `json:"start_at,omitempty"`
+	EndAt    *time.Time `json:"end_at,omitempty"`
+	Timezone *string    `json:"timezone,omitempty"`
+
+	UserID      int64  `json:"user_id"`      // เจ้าของ group (ไม่ใช่คนสร้างเสมอไป ถ้า group ถูกแชร์)
+	WorkspaceID int64  `json:"workspace_id"` // ตาม group เสมอ (DB trigger เซ็ตให้)
+	TodoGroupID int64  `json:"todo_group_id"`
+	AssigneeID  *int64 `json:"assignee_id"` // คนรับผิดชอบ ต้องเข้าถึง group ได้
+
+	// todo ที่เกิดซ้ำ (ไม่เกิดซ้ำ = null ทั้งหมด)
+	SeriesID       *int64      `json:"series_id,omitempty"`
+	RRule          *string     `json:"rrule,omitempty"`
+	OccurrenceDate *civil.Date `json:"occurrence_date,omitempty"` // วันตาม rule (เลื่อน date_start เฉพาะอันนี้ได้ slot ไม่เปลี่ยน)
+
+	Priority  Priority `json:"priority"` // none / low / medium / high / urgent
+	Important bool     `json:"important"`
 
-	UserID      int64 `json:"user_id"`
-	TodoGroupID int64 `json:"todo_group_id"`
+	AutoComplete bool              `json:"auto_complete"` // ติ๊ก checklist ครบแล้วเสร็จเอง
+	Checklist    ChecklistProgress `json:"checklist"`
+
+	// tag ของคนที่ดึง todo (tag เป็นของ user แต่ละคน todo ที่แชร์กันจึงเห็นไม่เหมือนกัน)
+	Tags []TagRef `json:"tags"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// TagRef tag ที่ติดอยู่กับ todo (รายละเอียด / จำนวนที่ใช้ดูที่ GET /tags)
+type TagRef struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// ChecklistProgress ความคืบหน้าของ checklist (done / total เช่น 3/5)
+type ChecklistProgress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// ChecklistItem ขั้นตอนย่อยของ todo เรียงตาม position ภายใน todo เดียวกัน
+type ChecklistItem struct {
+	ID        int64     `json:"id"`
+	TodoID    int64     `json:"todo_id"`
+	Title     string    `json:"title"`
+	IsDone    bool      `json:"is_done"`
+	Position  string    `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SearchResult todo ที่ค้นเจอ + คะแนนและข้อความที่ highlight คำที่ตรงไว้ด้วย <mark>
+// title_highlight / snippet escape HTML แล้ว (มีแค่ <mark> ที่เป็น tag จริง)
+type SearchResult struct {
+	Todo
+
+	Rank           float64 `json:"rank"`
+	TitleHighlight string  `json:"title_highlight"`
+	Snippet        *string `json:"snippet,omitempty"` // ท่อนของ description (ไม่มี description = null)
+}
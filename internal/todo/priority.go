@@ -0,0 +1,124 @@
+package todo
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/Nasaee/go-todo-backend/pkg/civil"
+)
+
+var ErrInvalidPriority = errors.New("priority must be one of none, low, medium, high, urgent")
+
+// Priority เก็บเป็นตัวเลขใน DB (sort ได้ตรง ๆ) ส่งออก JSON เป็นชื่อ
+// ไม่มี String() เพราะ pgx เจอ fmt.Stringer แล้วจะส่งค่าเป็น text แทนตัวเลข
+type Priority int16
+
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+	PriorityUrgent
+)
+
+var priorityNames = [...]string{"none", "low", "medium", "high", "urgent"}
+
+func ParsePriority(s string) (Priority, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for i, name := range priorityNames {
+		if name == s {
+			return Priority(i), nil
+		}
+	}
+	return PriorityNone, ErrInvalidPriority
+}
+
+func (p Priority) Name() string {
+	if p < PriorityNone || p > PriorityUrgent {
+		return priorityNames[PriorityNone]
+	}
+	return priorityNames[p]
+}
+
+func (p Priority) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.Name())
+}
+
+func (p *Priority) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return ErrInvalidPriority
+	}
+	v, err := ParsePriority(s)
+	if err != nil {
+		return err
+	}
+	*p = v
+	return nil
+}
+
+// ===== Eisenhower matrix =====
+
+const (
+	// DefaultUrgentDays todo ที่ครบกำหนดภายในกี่วันนับจากวันนี้ถึงจะถือว่าด่วน (รวมที่เลยกำหนดแล้ว)
+	DefaultUrgentDays = 2
+	MaxUrgentDays     = 30
+)
+
+// MatrixQuery ใช้กับ GET /todos/matrix
+type MatrixQuery struct {
+	ListOptions
+
+	UrgentDays int // ?urgent_days= (ไม่ส่ง = DefaultUrgentDays)
+}
+
+// Matrix แบ่ง todos ที่ยังไม่เสร็จเป็น 4 ช่อง
+//   - ด่วน = priority urgent หรือครบกำหนด (date_end ไม่มีก็ date_start) ภายใน urgent_days วัน
+//   - สำคัญ = important หรือ priority ตั้งแต่ high ขึ้นไป
+//
+// วันนี้คิดตาม timezone ของ user
+type Matrix struct {
+	Date       string `json:"date"` // วันนี้ (YYYY-MM-DD)
+	Timezone   string `json:"timezone"`
+	UrgentDays int    `json:"urgent_days"`
+
+	DoFirst   []Todo `json:"do_first"`  // ด่วน + สำคัญ
+	Schedule  []Todo `json:"schedule"`  // สำคัญ ไม่ด่วน
+	Delegate  []Todo `json:"delegate"`  // ด่วน ไม่สำคัญ
+	Eliminate []Todo `json:"eliminate"` // ไม่ด่วน ไม่สำคัญ
+}
+
+// buildMatrix todos ต้องเรียงมาแล้ว ลำดับในแต่ละช่องเป็นตามนั้น
+func buildMatrix(todos []Todo, today civil.Date, urgentDays int) *Matrix {
+	m := &Matrix{
+		Date:       today.String(),
+		UrgentDays: urgentDays,
+		DoFirst:    []Todo{},
+		Schedule:   []Todo{},
+		Delegate:   []Todo{},
+		Eliminate:  []Todo{},
+	}
+	deadline := today.AddDays(urgentDays)
+
+	for _, t := range todos {
+		due := t.DateStart
+		if t.DateEnd != nil {
+			due = *t.DateEnd
+		}
+		urgent := t.Priority == PriorityUrgent || !due.After(deadline.Time)
+		important := t.Important || t.Priority >= PriorityHigh
+
+		switch {
+		case urgent && important:
+			m.DoFirst = append(m.DoFirst, t)
+		case important:
+			m.Schedule = append(m.Schedule, t)
+		case urgent:
+			m.Delegate = append(m.Delegate, t)
+		default:
+			m.Eliminate = append(m.Eliminate, t)
+		}
+	}
+	return m
+}
@@ -0,0 +1,204 @@
+package todo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Nasaee/go-todo-backend/pkg/civil"
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidSort   = errors.New("sort must be one of position, date_start, created_at, updated_at, title, priority, important")
+)
+
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 200
+)
+
+// sortField = column ที่ยอมให้ sort ได้ (ชื่อ column ไม่เคยมาจาก request ตรง ๆ)
+type sortField struct {
+	column string
+	// cast ของค่าใน cursor ตอนเทียบใน SQL
+	cast string
+	// value ค่าของ todo แถวสุดท้ายในหน้า (เก็บลง cursor เป็น string)
+	value func(t *Todo) string
+	// parse แปลงค่าใน cursor กลับเป็น type ของ column (ค่าพัง = ErrInvalidCursor ไม่ปล่อยให้ไปพังใน SQL)
+	parse func(v string) (any, error)
+}
+
+var sortFields = map[string]sortField{
+	"position": {"position", "TEXT", func(t *Todo) string {
+		return t.Position
+	}, parseCursorText},
+	"date_start": {"date_start", "DATE", func(t *Todo) string {
+		return t.DateStart.String()
+	}, func(v string) (any, error) {
+		return civil.ParseDate(v)
+	}},
+	"created_at": {"created_at", "TIMESTAMPTZ", func(t *Todo) string {
+		return t.CreatedAt.Format(time.RFC3339Nano)
+	}, parseCursorTime},
+	"updated_at": {"updated_at", "TIMESTAMPTZ", func(t *Todo) string {
+		return t.UpdatedAt.Format(time.RFC3339Nano)
+	}, parseCursorTime},
+	"title": {"title", "TEXT", func(t *Todo) string {
+		return t.Title
+	}, parseCursorText},
+	"priority": {"priority", "SMALLINT", func(t *Todo) string {
+		return strconv.Itoa(int(t.Priority))
+	}, func(v string) (any, error) {
+		n, err := strconv.ParseInt(v, 10, 16)
+		return int16(n), err
+	}},
+	"important": {"important", "BOOLEAN", func(t *Todo) string {
+		return strconv.FormatBool(t.Important)
+	}, func(v string) (any, error) {
+		return strconv.ParseBool(v)
+	}},
+}
+
+// TEXT ของ Postgres รับ NUL / UTF-8 พัง ๆ ไม่ได้
+func parseCursorText(v string) (any, error) {
+	if !utf8.ValidString(v) || strings.ContainsRune(v, 0) {
+		return nil, ErrInvalidCursor
+	}
+	return v, nil
+}
+
+func parseCursorTime(v string) (any, error) {
+	return time.Parse(time.RFC3339Nano, v)
+}
+
+// cursor = ตำแหน่งของแถวสุดท้ายในหน้าก่อน (keyset) ส่งให้ client เป็น base64 ที่ไม่ต้องอ่านข้างใน
+// เก็บ sort / order ไว้ด้วย กัน client เอา cursor ไปใช้กับการเรียงแบบอื่น
+type cursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d"`
+	Value string `json:"v"`
+	ID    int64  `json:"id"`
+
+	// value = Value ที่ parse ตาม sort field แล้ว (decodeCursor ใส่ให้)
+	value any
+}
+
+func encodeCursor(c cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeCursor อ่าน cursor ที่ต้องเป็นของการเรียงแบบเดียวกัน (sortName / desc) และค่าตรงกับ type ของ column
+func decodeCursor(s, sortName string, desc bool) (cursor, error) {
+	var c cursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, ErrInvalidCursor
+	}
+	if c.Sort != sortName || c.Desc != desc {
+		return c, ErrInvalidCursor
+	}
+
+	f, ok := sortFields[c.Sort]
+	if !ok {
+		return c, ErrInvalidCursor
+	}
+	if c.value, err = f.parse(c.Value); err != nil {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// queryBuilder ต่อเงื่อนไข WHERE ทีละอัน ค่าทุกตัวส่งเป็น argument ($n) ไม่ต่อ string ลง SQL
+type queryBuilder struct {
+	conds []string
+	args  []any
+}
+
+// newQueryBuilder เริ่มจาก listFilter ($1..$10)
+func newQueryBuilder(userID int64, opts ListOptions) *queryBuilder {
+	return &queryBuilder{
+		conds: []string{listFilter},
+		args:  listArgs(userID, opts),
+	}
+}
+
+// arg เพิ่มค่าแล้วคืน placeholder ของมัน
+func (b *queryBuilder) arg(v any) string {
+	b.args = append(b.args, v)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+func (b *queryBuilder) where(cond string) {
+	b.conds = append(b.conds, cond)
+}
+
+func (b *queryBuilder) whereClause() string {
+	return strings.Join(b.conds, "\n\t\tAND ")
+}
+
+// escapeLike กัน % / _ ที่ user พิมพ์มาไม่ให้กลายเป็น wildcard
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// applyFilters แปลง ListQuery เป็นเงื่อนไข (ไม่รวม cursor)
+func (b *queryBuilder) applyFilters(q ListQuery) {
+	if q.GroupIDs != nil {
+		b.where("todo_group_id = ANY(" + b.arg(q.GroupIDs) + ")")
+	}
+	if q.IsSuccess != nil {
+		b.where("is_success = " + b.arg(*q.IsSuccess))
+	}
+	// ช่วงวันของ todo (date_start..date_end) คาบเกี่ยวกับช่วงที่ขอ
+	if q.DateFrom != nil {
+		b.where("COALESCE(date_end, date_start) >= " + b.arg(*q.DateFrom) + "::DATE")
+	}
+	if q.DateTo != nil {
+		b.where("date_start <= " + b.arg(*q.DateTo) + "::DATE")
+	}
+	if q.Text != "" {
+		p := b.arg("%" + escapeLike(q.Text) + "%")
+		b.where("(title ILIKE " + p + " OR description ILIKE " + p + ")")
+	}
+	if q.CreatedFrom != nil {
+		b.where("created_at >= " + b.arg(*q.CreatedFrom))
+	}
+	if q.CreatedTo != nil {
+		b.where("created_at <= " + b.arg(*q.CreatedTo))
+	}
+	if q.UpdatedFrom != nil {
+		b.where("updated_at >= " + b.arg(*q.UpdatedFrom))
+	}
+	if q.UpdatedTo != nil {
+		b.where("updated_at <= " + b.arg(*q.UpdatedTo))
+	}
+}
+
+// applyCursor เอาเฉพาะแถวที่อยู่ถัดจาก cursor ตามลำดับ (sort column, id)
+func (b *queryBuilder) applyCursor(f sortField, desc bool, c cursor) {
+	op := ">"
+	if desc {
+		op = "<"
+	}
+	// ค่าผ่าน decodeCursor มาแล้ว ส่งเป็น type ของ column ตรง ๆ
+	value := b.arg(c.value) + "::" + f.cast
+	b.where("(" + f.column + ", id) " + op + " (" + value + ", " + b.arg(c.ID) + ")")
+}
+
+// orderBy id ต่อท้ายเสมอ ให้ลำดับนิ่งแม้ค่าใน sort column ซ้ำกัน
+func orderBy(f sortField, desc bool) string {
+	dir := "ASC"
+	if desc {
+		dir = "DESC"
+	}
+	return f.column + " " + dir + ", id " + dir
+}
@@ -5,6 +5,9 @@ import (
 	"errors"
 	"time"
 
+	"github.com/Nasaee/go-todo-backend/internal/db/postgres"
+	"github.com/Nasaee/go-todo-backend/pkg/civil"
+	"github.com/Nasaee/go-todo-backend/pkg/rank"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -12,7 +15,21 @@ import (
 
 // ================== Error กลาง ==================
 
-var ErrNotFound = errors.New("todo not found")
+var (
+	ErrNotFound    = errors.New("todo not found")
+	ErrInvalidMove = errors.New("before_id / after_id must reference other todos you own, in order")
+)
+
+// FK (todo_group_id, user_id) ไม่ผ่าน = group ไม่มีอยู่ หรือไม่ใช่ของ user นี้
+// ปกติ service เช็คไปก่อนแล้ว อันนี้กันเคสถูกลบระหว่างทาง
+// (assignee เช็คที่ service อย่างเดียว สมาชิก workspace ไม่มีแถวให้ FK อ้างถึง)
+func mapGroupFKError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" && pgErr.ConstraintName == "fk_todos_todo_group_owner" {
+		return ErrGroupNotFound
+	}
+	return err
+}
 
 // ใช้ร่วมกับ UPDATE / DELETE เพื่อตรวจว่าโดนแก้ไขจริงกี่แถว
 func checkRowsAffectedOne(cmdTag pgconn.CommandTag) error {
@@ -22,24 +39,79 @@ func checkRowsAffectedOne(cmdTag pgconn.CommandTag) error {
 	return nil
 }
 
+// column ที่ SELECT ทุกครั้ง (ลำดับต้องตรงกับ todoDest)
+// $1 ต้องเป็น user ที่ดึง (tags เอาเฉพาะของคนนั้น)
+const todoColumns = `
+	id,
+	title,
+	description,
+	date_start,
+	date_end,
+	(start_at IS NULL),
+	start_at,
+	end_at,
+	timezone,
+	is_success,
+	completed_at,
+	user_id,
+	workspace_id,
+	todo_group_id,
+	assignee_id,
+	series_id,
+	(SELECT rrule FROM todo_series WHERE todo_series.id = todos.series_id),
+	occurrence_date,
+	priority,
+	important,
+	auto_complete,
+	(SELECT COUNT(*) FILTER (WHERE is_done) FROM checklist_items c WHERE c.todo_id = todos.id),
+	(SELECT COUNT(*) FROM checklist_items c WHERE c.todo_id = todos.id),
+	(SELECT COALESCE(json_agg(json_build_object('id', tg.id, 'name', tg.name, 'color', tg.color) ORDER BY lower(tg.name)), '[]')
+	 FROM todo_tags tt JOIN tags tg ON tg.id = tt.tag_id
+	 WHERE tt.todo_id = todos.id AND tg.user_id = $1),
+	position,
+	created_at,
+	updated_at
+`
+
+func todoDest(t *Todo) []any {
+	return []any{
+		&t.ID,
+		&t.Title,
+		&t.Description,
+		&t.DateStart,
+		&t.DateEnd,
+		&t.AllDay,
+		&t.StartAt,
+		&t.EndAt,
+		&t.Timezone,
+		&t.IsSuccess,
+		&t.CompletedAt,
+		&t.UserID,
+		&t.WorkspaceID,
+		&t.TodoGroupID,
+		&t.AssigneeID,
+		&t.SeriesID,
+		&t.RRule,
+		&t.OccurrenceDate,
+		&t.Priority,
+		&t.Important,
+		&t.AutoComplete,
+		&t.Checklist.Done,
+		&t.Checklist.Total,
+		&t.Tags,
+		&t.Position,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	}
+}
+
 func scanTodos(rows pgx.Rows) ([]Todo, error) {
 	defer rows.Close()
 
 	var todos []Todo
 	for rows.Next() {
 		var t Todo
-		if err := rows.Scan(
-			&t.ID,
-			&t.Title,
-			&t.Description,
-			&t.DateStart,
-			&t.DateEnd,
-			&t.IsSuccess,
-			&t.UserID,
-			&t.TodoGroupID,
-			&t.CreatedAt,
-			&t.UpdatedAt,
-		); err != nil {
+		if err := rows.Scan(todoDest(&t)...); err != nil {
 			return nil, err
 		}
 		todos = append(todos, t)
@@ -52,16 +124,121 @@ func scanTodos(rows pgx.Rows) ([]Todo, error) {
 	return todos, nil
 }
 
+// visibleFilter = todo ใน group ที่ user ($1) มีสิทธิ์ (สมาชิกของ group หรือของ team workspace)
+// แล้วตัด todos ใน group ที่ archive ทิ้ง ถ้าไม่ได้ขอ include=archived ($2)
+const visibleFilter = `
+	todo_group_id IN (SELECT todo_group_id FROM todo_group_access WHERE user_id = $1)
+	AND ($2 OR NOT EXISTS (
+		SELECT 1 FROM todo_groups g
+		WHERE g.id = todos.todo_group_id AND g.archived_at IS NOT NULL
+	))
+`
+
+// assigneeFilter ($3 = assignee id หรือ NULL, $4 = เอาเฉพาะที่ยังไม่มีคนรับผิดชอบ)
+const assigneeFilter = `
+	AND ($3::BIGINT IS NULL OR assignee_id = $3)
+	AND (NOT $4 OR assignee_id IS NULL)
+`
+
+// workspaceFilter ($5 = workspace id หรือ NULL = ทุก workspace)
+const workspaceFilter = `
+	AND ($5::BIGINT IS NULL OR workspace_id = $5)
+`
+
+// priorityFilter ($6 = ระดับที่เอา หรือ NULL = ทุกระดับ, $7 = important หรือ NULL = ไม่กรอง)
+const priorityFilter = `
+	AND ($6::SMALLINT[] IS NULL OR priority = ANY($6))
+	AND ($7::BOOLEAN IS NULL OR important = $7)
+`
+
+// tagFilter ($8 = tag ของ user $1 หรือ NULL = ไม่กรอง, $9 = ต้องมีครบทุก tag)
+const tagFilter = `
+	AND ($8::BIGINT[] IS NULL OR (
+		SELECT COUNT(*)
+		FROM todo_tags tt JOIN tags tg ON tg.id = tt.tag_id
+		WHERE tt.todo_id = todos.id AND tt.tag_id = ANY($8) AND tg.user_id = $1
+	) >= CASE WHEN $9 THEN cardinality($8) ELSE 1 END)
+`
+
+// completedFilter ($10 = รวม todos ที่เสร็จแล้ว)
+const completedFilter = `
+	AND ($10 OR NOT is_success)
+`
+
+// optionFilter = filter จาก ListOptions (ไม่รวม visibleFilter)
+const optionFilter = assigneeFilter + workspaceFilter + priorityFilter + tagFilter + completedFilter
+
+// listFilter = เงื่อนไขที่ใช้ร่วมกันของทุก list (argument ดู listArgs)
+const listFilter = visibleFilter + optionFilter
+
+// listArgs = argument $1..$10 ของ listFilter
+func listArgs(userID int64, opts ListOptions) []any {
+	var tagIDs []int64
+	if len(opts.TagIDs) > 0 {
+		tagIDs = opts.TagIDs
+	}
+	return []any{
+		userID, opts.IncludeArchived, opts.AssigneeID, opts.Unassigned, opts.WorkspaceID,
+		priorityArg(opts.Priorities), opts.Important,
+		tagIDs, opts.AllTags,
+		opts.IncludeCompleted,
+	}
+}
+
+// priorityArg ส่งเป็น []int16 (nil = NULL)
+func priorityArg(ps []Priority) []int16 {
+	if len(ps) == 0 {
+		return nil
+	}
+	out := make([]int16, len(ps))
+	for i, p := range ps {
+		out[i] = int16(p)
+	}
+	return out
+}
+
 // ================== Interface ==================
 type TodoRepository interface {
-	Create(ctx context.Context, t *Todo) error
+	// Create / Update เขียน tag (ถ้าส่ง tags มา) ใน transaction เดียวกับตัว todo tag ไม่ผ่าน = todo ไม่ถูกบันทึกด้วย
+	Create(ctx context.Context, t *Todo, tags *TagSet) error
+	CreateOccurrence(ctx context.Context, t *Todo) (bool, error)
 	GetByID(ctx context.Context, id, userID int64) (*Todo, error)
-	ListByUser(ctx context.Context, userID int64) ([]Todo, error)
-	Update(ctx context.Context, t *Todo) error
-	Delete(ctx context.Context, id, userID int64) error
-	ListToday(ctx context.Context, userID int64) ([]Todo, error)
-	ListTomorrow(ctx context.Context, userID int64) ([]Todo, error)
-	ListThisWeek(ctx context.Context, userID int64) ([]Todo, error)
+	List(ctx context.Context, userID int64, q ListQuery) (*TodoPage, error)
+	Search(ctx context.Context, userID int64, q SearchQuery) ([]SearchResult, error)
+	// Update / Delete / Move scope ด้วยเจ้าของ (todos.user_id) สิทธิ์ของคนแก้ให้ service เช็คก่อน
+	Update(ctx context.Context, t *Todo, tags *TagSet) error
+	Delete(ctx context.Context, id, ownerID int64) error
+	Move(ctx context.Context, id, ownerID int64, beforeID, afterID *int64) (*Todo, error)
+	// ListDays todos ที่คาบเกี่ยวกับช่วงวันนี้ (today / tomorrow / this-week / upcoming / range)
+	ListDays(ctx context.Context, userID int64, w DayWindow, opts ListOptions) ([]Todo, error)
+	// ListCompleted todos ที่เสร็จในช่วง [from, to) ล่าสุดก่อน
+	ListCompleted(ctx context.Context, userID int64, from, to time.Time, opts ListOptions) ([]Todo, error)
+	// ListOverdue todos ที่ยังไม่เสร็จและเลยกำหนดแล้ว (ทั้งวัน = ก่อน today, มีเวลา = ก่อน now)
+	ListOverdue(ctx context.Context, userID int64, today civil.Date, now time.Time, opts ListOptions) ([]Todo, error)
+	ListByGroups(ctx context.Context, userID int64, groupIDs []int64, opts ListOptions) ([]Todo, error)
+	ListOpen(ctx context.Context, userID int64, opts ListOptions) ([]Todo, error)
+
+	// series ของ todo ที่เกิดซ้ำ (สิทธิ์ให้ service เช็คจาก todo ก่อน)
+	CreateSeries(ctx context.Context, s *Series) error
+	GetSeries(ctx context.Context, id int64) (*Series, error)
+	UpdateSeries(ctx context.Context, s *Series) error
+	DeleteSeries(ctx context.Context, id int64) error
+	// UserTimezone timezone ของ user ("UTC" ถ้าไม่ได้ตั้ง)
+	UserTimezone(ctx context.Context, userID int64) (string, error)
+
+	// checklist ของ todo (สิทธิ์ให้ service เช็คจาก todo ก่อน) item ที่ไม่ได้อยู่ใน todoID = ErrChecklistItemNotFound
+	ListChecklist(ctx context.Context, todoID int64) ([]ChecklistItem, error)
+	GetChecklistItem(ctx context.Context, id, todoID int64) (*ChecklistItem, error)
+	CreateChecklistItem(ctx context.Context, item *ChecklistItem) error
+	UpdateChecklistItem(ctx context.Context, item *ChecklistItem) error
+	DeleteChecklistItem(ctx context.Context, id, todoID int64) error
+	MoveChecklistItem(ctx context.Context, id, todoID int64, beforeID, afterID *int64) (*ChecklistItem, error)
+	// OwnedTags tag ของ user ตาม id ที่ขอ (มีอันไหนไม่ใช่ของ user = ErrTagNotFound)
+	OwnedTags(ctx context.Context, userID int64, tagIDs []int64) ([]TagRef, error)
+	// CopyTags คัด tag ของทุกคนไป todo ใหม่ (occurrence ถัดไป)
+	CopyTags(ctx context.Context, fromTodoID, toTodoID int64) error
+	// CopyChecklist คัด checklist ไป todo ใหม่ (ยังไม่ติ๊กทุกข้อ) ใช้กับ occurrence ถัดไปของ todo ที่เกิดซ้ำ
+	CopyChecklist(ctx context.Context, fromTodoID, toTodoID int64) error
 }
 
 type PostgresRepo struct {
@@ -72,7 +249,17 @@ func NewRepository(db *pgxpool.Pool) TodoRepository {
 	return &PostgresRepo{db: db}
 }
 
-func (r *PostgresRepo) Create(ctx context.Context, t *Todo) error {
+func (r *PostgresRepo) Create(ctx context.Context, t *Todo, tags *TagSet) error {
+	_, err := r.insert(ctx, t, false, tags)
+	return err
+}
+
+// CreateOccurrence สร้าง occurrence ของ series (มี occurrence วันนั้นอยู่แล้ว = false ไม่ถือเป็น error)
+func (r *PostgresRepo) CreateOccurrence(ctx context.Context, t *Todo) (bool, error) {
+	return r.insert(ctx, t, true, nil)
+}
+
+func (r *PostgresRepo) insert(ctx context.Context, t *Todo, skipDuplicate bool, tags *TagSet) (bool, error) {
 	query := `
 		INSERT INTO todos (
 			title,
@@ -81,63 +268,106 @@ func (r *PostgresRepo) Create(ctx context.Context, t *Todo) error {
 			date_end,
 			is_success,
 			user_id,
-			todo_group_id
+			todo_group_id,
+			assignee_id,
+			position,
+			series_id,
+			occurrence_date,
+			auto_complete,
+			priority,
+			important,
+			start_at,
+			end_at,
+			timezone
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, created_at, updated_at
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+	`
+	if skipDuplicate {
+		query += `
+		ON CONFLICT (series_id, occurrence_date) WHERE series_id IS NOT NULL DO NOTHING
+		`
+	}
+	query += `
+		RETURNING id, workspace_id, created_at, updated_at
 	`
 	// กันเคสลืมเซ็ต date_start (ถึง DB บังคับ NOT NULL แล้ว แต่ช่วย set ให้ตรงนี้ด้วย)
 	if t.DateStart.IsZero() {
-		t.DateStart = time.Now().UTC()
-	}
-
-	return r.db.QueryRow(
-		ctx,
-		query,
-		t.Title,
-		t.Description,
-		t.DateStart,
-		t.DateEnd,
-		t.IsSuccess,
-		t.UserID,
-		t.TodoGroupID,
-	).Scan(
-		&t.ID,
-		&t.CreatedAt,
-		&t.UpdatedAt,
-	)
+		t.DateStart = civil.Today(time.UTC)
+	}
+
+	// todo ใหม่ต่อท้าย list ของ user เสมอ
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := postgres.LockUserOrdering(ctx, tx, t.UserID); err != nil {
+			return err
+		}
+
+		var last string
+		if err := tx.QueryRow(ctx, `
+			SELECT COALESCE(MAX(position), '')
+			FROM todos
+			WHERE user_id = $1
+		`, t.UserID).Scan(&last); err != nil {
+			return err
+		}
+
+		pos, err := rank.After(last)
+		if err != nil {
+			return err
+		}
+		t.Position = pos
+
+		if err := tx.QueryRow(
+			ctx,
+			query,
+			t.Title,
+			t.Description,
+			t.DateStart,
+			t.DateEnd,
+			t.IsSuccess,
+			t.UserID,
+			t.TodoGroupID,
+			t.AssigneeID,
+			t.Position,
+			t.SeriesID,
+			t.OccurrenceDate,
+			t.AutoComplete,
+			int16(t.Priority),
+			t.Important,
+			t.StartAt,
+			t.EndAt,
+			t.Timezone,
+		).Scan(
+			&t.ID,
+			&t.WorkspaceID,
+			&t.CreatedAt,
+			&t.UpdatedAt,
+		); err != nil {
+			return err
+		}
+
+		return setTags(ctx, tx, t.ID, tags)
+	})
+	// ON CONFLICT DO NOTHING ไม่คืนแถว
+	if skipDuplicate && errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, mapGroupFKError(err)
+	}
+
+	return true, nil
 }
 
 func (r *PostgresRepo) GetByID(ctx context.Context, id, userID int64) (*Todo, error) {
 	query := `
-		SELECT
-			id,
-			title,
-			description,
-			date_start,
-			date_end,
-			is_success,
-			user_id,
-			todo_group_id,
-			created_at,
-			updated_at
+		SELECT ` + todoColumns + `
 		FROM todos
-		WHERE id = $1 AND user_id = $2
+		WHERE id = $2
+		  AND todo_group_id IN (SELECT todo_group_id FROM todo_group_access WHERE user_id = $1)
 	`
 
 	var t Todo
-	err := r.db.QueryRow(ctx, query, id, userID).Scan(
-		&t.ID,
-		&t.Title,
-		&t.Description,
-		&t.DateStart,
-		&t.DateEnd,
-		&t.IsSuccess,
-		&t.UserID,
-		&t.TodoGroupID,
-		&t.CreatedAt,
-		&t.UpdatedAt,
-	)
+	err := r.db.QueryRow(ctx, query, userID, id).Scan(todoDest(&t)...)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrNotFound
@@ -148,87 +378,178 @@ func (r *PostgresRepo) GetByID(ctx context.Context, id, userID int64) (*Todo, er
 	return &t, nil
 }
 
-func (r *PostgresRepo) ListByUser(ctx context.Context, userID int64) ([]Todo, error) {
+// List ดึง todos ทีละหน้าแบบ keyset (ต่อจากแถวใน cursor) ตาม filter / sort ใน q
+// q.Limit ต้อง normalize มาแล้ว (service ทำให้)
+func (r *PostgresRepo) List(ctx context.Context, userID int64, q ListQuery) (*TodoPage, error) {
+	sortName := q.Sort
+	if sortName == "" {
+		sortName = "position"
+	}
+	f, ok := sortFields[sortName]
+	if !ok {
+		return nil, ErrInvalidSort
+	}
+
+	b := newQueryBuilder(userID, q.ListOptions)
+	b.applyFilters(q)
+
+	if q.Cursor != "" {
+		c, err := decodeCursor(q.Cursor, sortName, q.Desc)
+		if err != nil {
+			return nil, err
+		}
+		b.applyCursor(f, q.Desc, c)
+	}
+
+	// ขอเกินมา 1 แถว ไว้รู้ว่ามีหน้าถัดไปไหม
 	query := `
-		SELECT
-			id,
-			title,
-			description,
-			date_start,
-			date_end,
-			is_success,
-			user_id,
-			todo_group_id,
-			created_at,
-			updated_at
+		SELECT ` + todoColumns + `
 		FROM todos
-		WHERE user_id = $1
-		ORDER BY date_start, id
-	`
+		WHERE ` + b.whereClause() + `
+		ORDER BY ` + orderBy(f, q.Desc) + `
+		LIMIT ` + b.arg(q.Limit+1)
 
-	rows, err := r.db.Query(ctx, query, userID)
+	rows, err := r.db.Query(ctx, query, b.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	todos, err := scanTodos(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &TodoPage{Items: todos}
+	if page.Items == nil {
+		page.Items = []Todo{}
+	}
+	if len(todos) > q.Limit {
+		page.Items = todos[:q.Limit]
+		last := &page.Items[q.Limit-1]
+		next := encodeCursor(cursor{Sort: sortName, Desc: q.Desc, Value: f.value(last), ID: last.ID})
+		page.NextCursor = &next
+	}
+
+	return page, nil
+}
+
+// Search หาจาก search_vector (คำขึ้นต้นตรงกัน) หรือ substring ใน title / description (ภาษาไทย)
+// q.Limit ต้อง normalize มาแล้ว
+func (r *PostgresRepo) Search(ctx context.Context, userID int64, q SearchQuery) ([]SearchResult, error) {
+	tsq := prefixTSQuery(q.Text)
+	if tsq == "" {
+		return nil, ErrEmptySearch
+	}
+
+	b := newQueryBuilder(userID, q.ListOptions)
+	tsqArg, text := b.arg(tsq), b.arg(q.Text)
+	like := b.arg("%" + escapeLike(q.Text) + "%")
+	b.where("(search_vector @@ query OR title ILIKE " + like + " OR description ILIKE " + like + ")")
+
+	// ตัวคั่น highlight ที่อยู่ในข้อความอยู่แล้วตัดทิ้งก่อน ไม่ให้กลายเป็น <mark> ปลอม
+	sentinels := b.arg(highlightStart + highlightStop)
+
+	// คะแนน = ts_rank (คำตรง, title หนักกว่า) + ความคล้ายแบบ trigram ของ title
+	sql := `
+		SELECT ` + todoColumns + `,
+			ts_rank(search_vector, query) + word_similarity(` + text + `, title) AS rank,
+			ts_headline('simple', translate(title, ` + sentinels + `, ''), query, ` + b.arg(headlineOptions) + `),
+			CASE WHEN description IS NULL THEN NULL
+			     ELSE ts_headline('simple', translate(description, ` + sentinels + `, ''), query, ` + b.arg(snippetOptions) + `)
+			END
+		FROM todos, to_tsquery('simple', ` + tsqArg + `) AS query
+		WHERE ` + b.whereClause() + `
+		ORDER BY rank DESC, updated_at DESC, id DESC
+		LIMIT ` + b.arg(q.Limit)
+
+	rows, err := r.db.Query(ctx, sql, b.args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var todos []Todo
+	results := []SearchResult{}
 	for rows.Next() {
-		var t Todo
-		if err := rows.Scan(
-			&t.ID,
-			&t.Title,
-			&t.Description,
-			&t.DateStart,
-			&t.DateEnd,
-			&t.IsSuccess,
-			&t.UserID,
-			&t.TodoGroupID,
-			&t.CreatedAt,
-			&t.UpdatedAt,
-		); err != nil {
+		var res SearchResult
+		dest := append(todoDest(&res.Todo), &res.Rank, &res.TitleHighlight, &res.Snippet)
+		if err := rows.Scan(dest...); err != nil {
 			return nil, err
 		}
-		todos = append(todos, t)
+		res.TitleHighlight = highlightHTML(res.TitleHighlight)
+		if res.Snippet != nil {
+			snippet := highlightHTML(*res.Snippet)
+			res.Snippet = &snippet
+		}
+		results = append(results, res)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
-	return todos, nil
+	return results, nil
 }
 
-func (r *PostgresRepo) Update(ctx context.Context, t *Todo) error {
+func (r *PostgresRepo) Update(ctx context.Context, t *Todo, tags *TagSet) error {
 	query := `
 		UPDATE todos
-		SET 
+		SET
 			title = $1,
 			description = $2,
 			date_start = $3,
 			date_end = $4,
 			is_success = $5,
-			todo_group_id = $6
-		WHERE id = $7 AND user_id = $8	
+			todo_group_id = $6,
+			assignee_id = $7,
+			series_id = $10,
+			occurrence_date = $11,
+			auto_complete = $12,
+			priority = $13,
+			important = $14,
+			start_at = $15,
+			end_at = $16,
+			timezone = $17,
+			completed_at = $18
+		WHERE id = $8 AND user_id = $9
+		RETURNING workspace_id, updated_at
 	`
-	// cmdTag -> สรุปผลลัพธ์ของคำสั่ง SQL ที่รันไป” เช่น: UPDATE ไปกี่แถว, DELETE ไปกี่แถว, INSERT แต่ไม่ RETURNING ได้ผลอะไรมั้ย, คำสั่ง SQL ประเภทไหน
-	cmdTag, err := r.db.Exec(
-		ctx,
-		query,
-		t.Title,
-		t.Description,
-		t.DateStart,
-		t.DateEnd,
-		t.IsSuccess,
-		t.TodoGroupID,
-		t.ID,
-		t.UserID,
-	)
+	// ย้าย group แล้ว workspace อาจเปลี่ยนตาม (trigger เซ็ตให้) จึงอ่านค่ากลับมาด้วย
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(
+			ctx,
+			query,
+			t.Title,
+			t.Description,
+			t.DateStart,
+			t.DateEnd,
+			t.IsSuccess,
+			t.TodoGroupID,
+			t.AssigneeID,
+			t.ID,
+			t.UserID,
+			t.SeriesID,
+			t.OccurrenceDate,
+			t.AutoComplete,
+			int16(t.Priority),
+			t.Important,
+			t.StartAt,
+			t.EndAt,
+			t.Timezone,
+			t.CompletedAt,
+		).Scan(&t.WorkspaceID, &t.UpdatedAt); err != nil {
+			return err
+		}
+
+		return setTags(ctx, tx, t.ID, tags)
+	})
 	if err != nil {
-		return err
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return mapGroupFKError(err)
 	}
 
-	return checkRowsAffectedOne(cmdTag)
+	return nil
 }
 
 func (r *PostgresRepo) Delete(ctx context.Context, id, userID int64) error {
@@ -245,53 +566,88 @@ func (r *PostgresRepo) Delete(ctx context.Context, id, userID int64) error {
 	return checkRowsAffectedOne(cmdTag)
 }
 
-func (r *PostgresRepo) ListToday(ctx context.Context, userID int64) ([]Todo, error) {
+// ListDays todo ที่ช่วงของมันคาบเกี่ยวกับช่วงวัน w
+// todo ทั้งวันเทียบ date_start..date_end กับวันในช่วง
+// todo ที่มีเวลาเทียบ start_at..end_at กับช่วงเวลาจริงของวันเหล่านั้นใน timezone ของคนดู
+// (date_start ของ todo ที่มีเวลาเป็นวันตาม timezone ของ todo เอง ซึ่งอาจไม่ตรงกับของคนดู)
+// จบเที่ยงคืนพอดีไม่นับวันถัดไป
+func (r *PostgresRepo) ListDays(ctx context.Context, userID int64, w DayWindow, opts ListOptions) ([]Todo, error) {
+	b := newQueryBuilder(userID, opts)
+	fromAt, toAt := b.arg(w.From.StartIn(w.Loc)), b.arg(w.To.StartIn(w.Loc))
+	b.where(`CASE WHEN start_at IS NULL
+			THEN date_start < ` + b.arg(w.To) + `::DATE AND COALESCE(date_end, date_start) >= ` + b.arg(w.From) + `::DATE
+			ELSE start_at < ` + toAt + ` AND CASE WHEN end_at > start_at THEN end_at > ` + fromAt + ` ELSE start_at >= ` + fromAt + ` END
+		END`)
+
 	query := `
-		SELECT
-			id,
-			title,
-			description,
-			date_start,
-			date_end,
-			is_success,
-			user_id,
-			todo_group_id,
-			created_at,
-			updated_at
+		SELECT ` + todoColumns + `
 		FROM todos
-		WHERE user_id = $1
-		  AND date_start = CURRENT_DATE
-		ORDER BY title, id
+		WHERE ` + b.whereClause() + `
+		ORDER BY date_start, position
 	`
+	rows, err := r.db.Query(ctx, query, b.args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanTodos(rows)
+}
+
+func (r *PostgresRepo) ListCompleted(ctx context.Context, userID int64, from, to time.Time, opts ListOptions) ([]Todo, error) {
+	b := newQueryBuilder(userID, opts)
+	b.where("is_success")
+	b.where("completed_at >= " + b.arg(from))
+	b.where("completed_at < " + b.arg(to))
 
-	rows, err := r.db.Query(ctx, query, userID)
+	query := `
+		SELECT ` + todoColumns + `
+		FROM todos
+		WHERE ` + b.whereClause() + `
+		ORDER BY completed_at DESC, id DESC
+	`
+	rows, err := r.db.Query(ctx, query, b.args...)
 	if err != nil {
 		return nil, err
 	}
+	return scanTodos(rows)
+}
+
+// ListOverdue เรียงตามวันครบกำหนด (date_end ถ้ามี ไม่งั้น date_start) เก่าสุดก่อน
+func (r *PostgresRepo) ListOverdue(ctx context.Context, userID int64, today civil.Date, now time.Time, opts ListOptions) ([]Todo, error) {
+	b := newQueryBuilder(userID, opts)
+	b.where("NOT is_success")
+	b.where(`CASE WHEN start_at IS NULL
+			THEN COALESCE(date_end, date_start) < ` + b.arg(today) + `::DATE
+			ELSE COALESCE(end_at, start_at) < ` + b.arg(now) + `
+		END`)
 
+	query := `
+		SELECT ` + todoColumns + `
+		FROM todos
+		WHERE ` + b.whereClause() + `
+		ORDER BY COALESCE(date_end, date_start), position
+	`
+	rows, err := r.db.Query(ctx, query, b.args...)
+	if err != nil {
+		return nil, err
+	}
 	return scanTodos(rows)
 }
 
-func (r *PostgresRepo) ListTomorrow(ctx context.Context, userID int64) ([]Todo, error) {
+// ListByGroups ไม่กรอง group ที่ archive เพราะ service เลือก groupIDs มาให้แล้ว
+func (r *PostgresRepo) ListByGroups(ctx context.Context, userID int64, groupIDs []int64, opts ListOptions) ([]Todo, error) {
 	query := `
-		SELECT
-			id,
-			title,
-			description,
-			date_start,
-			date_end,
-			is_success,
-			user_id,
-			todo_group_id,
-			created_at,
-			updated_at
+		SELECT ` + todoColumns + `
 		FROM todos
-		WHERE user_id = $1
-		  AND date_start = CURRENT_DATE + INTERVAL '1 day'
-		ORDER BY title, id
+		WHERE todo_group_id IN (SELECT todo_group_id FROM todo_group_access WHERE user_id = $1)
+		  AND todo_group_id = ANY($2)
+		  ` + optionFilter + `
+		ORDER BY position
 	`
 
-	rows, err := r.db.Query(ctx, query, userID)
+	// $2 เป็น groupIDs แทน include=archived ที่เหลือเหมือน listArgs
+	args := listArgs(userID, opts)
+	args[1] = groupIDs
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -299,32 +655,466 @@ func (r *PostgresRepo) ListTomorrow(ctx context.Context, userID int64) ([]Todo,
 	return scanTodos(rows)
 }
 
-func (r *PostgresRepo) ListThisWeek(ctx context.Context, userID int64) ([]Todo, error) {
-	/*
-		AND date_start >= date_trunc('week', CURRENT_DATE)::date -> result is monday of this week
-		AND date_start < (date_trunc('week', CURRENT_DATE) + INTERVAL '1 week')::date -> 7 days from Monday–Sunday
-	*/
+// Move เปลี่ยน position ของ todo แถวเดียว ให้ไปอยู่ก่อน beforeID และ/หรือหลัง afterID
+func (r *PostgresRepo) Move(ctx context.Context, id, userID int64, beforeID, afterID *int64) (*Todo, error) {
+	if beforeID == nil && afterID == nil {
+		return nil, ErrInvalidMove
+	}
+	if (beforeID != nil && *beforeID == id) || (afterID != nil && *afterID == id) {
+		return nil, ErrInvalidMove
+	}
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := postgres.LockUserOrdering(ctx, tx, userID); err != nil {
+			return err
+		}
+
+		lower, upper, err := postgres.NeighborPositions(ctx, tx, "todos", id, userID, beforeID, afterID)
+		if err != nil {
+			if errors.Is(err, postgres.ErrAnchorNotFound) {
+				return ErrInvalidMove
+			}
+			return err
+		}
+
+		pos, err := rank.Between(lower, upper)
+		if err != nil {
+			return ErrInvalidMove
+		}
+
+		cmdTag, err := tx.Exec(ctx, `
+			UPDATE todos
+			SET position = $1
+			WHERE id = $2 AND user_id = $3
+		`, pos, id, userID)
+		if err != nil {
+			return err
+		}
+
+		return checkRowsAffectedOne(cmdTag)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, id, userID)
+}
+
+// ListOpen todos ที่ยังไม่เสร็จทั้งหมด (ใช้กับ matrix) สำคัญ / ใกล้กำหนดก่อน
+func (r *PostgresRepo) ListOpen(ctx context.Context, userID int64, opts ListOptions) ([]Todo, error) {
 	query := `
-		SELECT
-			id,
-			title,
-			description,
-			date_start,
-			date_end,
-			is_success,
-			user_id,
-			todo_group_id,
-			created_at,
-			updated_at
+		SELECT ` + todoColumns + `
 		FROM todos
-		WHERE user_id = $1
-		  AND date_start >= date_trunc('week', CURRENT_DATE)::date
-		  AND date_start < (date_trunc('week', CURRENT_DATE) + INTERVAL '1 week')::date
-		ORDER BY date_start, title
+		WHERE ` + listFilter + `
+		  AND NOT is_success
+		ORDER BY priority DESC, COALESCE(date_end, date_start), position
 	`
-	rows, err := r.db.Query(ctx, query, userID)
+
+	rows, err := r.db.Query(ctx, query, listArgs(userID, opts)...)
 	if err != nil {
 		return nil, err
 	}
 	return scanTodos(rows)
 }
+
+// ================== Series ==================
+
+const seriesColumns = `
+	id,
+	rrule,
+	timezone,
+	dtstart,
+	title,
+	description,
+	duration_days,
+	todo_group_id,
+	assignee_id,
+	start_minute,
+	duration_minutes
+`
+
+func (r *PostgresRepo) CreateSeries(ctx context.Context, s *Series) error {
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO todo_series (rrule, timezone, dtstart, title, description, duration_days, todo_group_id, assignee_id, start_minute, duration_minutes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`, s.RRule, s.Timezone, s.DTStart, s.Title, s.Description, s.DurationDays, s.TodoGroupID, s.AssigneeID, s.StartMinute, s.DurationMinutes).Scan(&s.ID)
+	return mapGroupFKError(err)
+}
+
+func (r *PostgresRepo) GetSeries(ctx context.Context, id int64) (*Series, error) {
+	var s Series
+	err := r.db.QueryRow(ctx, `
+		SELECT `+seriesColumns+`
+		FROM todo_series
+		WHERE id = $1
+	`, id).Scan(
+		&s.ID,
+		&s.RRule,
+		&s.Timezone,
+		&s.DTStart,
+		&s.Title,
+		&s.Description,
+		&s.DurationDays,
+		&s.TodoGroupID,
+		&s.AssigneeID,
+		&s.StartMinute,
+		&s.DurationMinutes,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func (r *PostgresRepo) UpdateSeries(ctx context.Context, s *Series) error {
+	cmdTag, err := r.db.Exec(ctx, `
+		UPDATE todo_series
+		SET
+			rrule = $1,
+			timezone = $2,
+			dtstart = $3,
+			title = $4,
+			description = $5,
+			duration_days = $6,
+			todo_group_id = $7,
+			assignee_id = $8,
+			start_minute = $10,
+			duration_minutes = $11
+		WHERE id = $9
+	`, s.RRule, s.Timezone, s.DTStart, s.Title, s.Description, s.DurationDays, s.TodoGroupID, s.AssigneeID, s.ID, s.StartMinute, s.DurationMinutes)
+	if err != nil {
+		return mapGroupFKError(err)
+	}
+
+	return checkRowsAffectedOne(cmdTag)
+}
+
+// DeleteSeries todo ที่สร้างไปแล้วยังอยู่ (series_id กลายเป็น NULL)
+func (r *PostgresRepo) DeleteSeries(ctx context.Context, id int64) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM todo_series WHERE id = $1`, id)
+	return err
+}
+
+func (r *PostgresRepo) UserTimezone(ctx context.Context, userID int64) (string, error) {
+	var tz string
+	err := r.db.QueryRow(ctx, `SELECT timezone FROM users WHERE id = $1`, userID).Scan(&tz)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "UTC", nil
+	}
+	return tz, err
+}
+
+// ================== Checklist ==================
+
+var (
+	ErrChecklistItemNotFound = errors.New("checklist item not found")
+	ErrChecklistFull         = errors.New("checklist has too many items")
+	ErrInvalidChecklistMove  = errors.New("before_id / after_id must reference other items in the same checklist, in order")
+)
+
+// MaxChecklistItems จำนวนข้อสูงสุดต่อ todo
+const MaxChecklistItems = 100
+
+const checklistColumns = `
+	id,
+	todo_id,
+	title,
+	is_done,
+	position,
+	created_at,
+	updated_at
+`
+
+func checklistDest(c *ChecklistItem) []any {
+	return []any{
+		&c.ID,
+		&c.TodoID,
+		&c.Title,
+		&c.IsDone,
+		&c.Position,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	}
+}
+
+// lockChecklist ให้การเพิ่ม / ย้ายข้อใน todo เดียวกันทำทีละคำขอ (กัน position ชน)
+func lockChecklist(ctx context.Context, tx pgx.Tx, todoID int64) error {
+	cmdTag, err := tx.Exec(ctx, `SELECT 1 FROM todos WHERE id = $1 FOR NO KEY UPDATE`, todoID)
+	if err != nil {
+		return err
+	}
+	return checkRowsAffectedOne(cmdTag)
+}
+
+func (r *PostgresRepo) ListChecklist(ctx context.Context, todoID int64) ([]ChecklistItem, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+checklistColumns+`
+		FROM checklist_items
+		WHERE todo_id = $1
+		ORDER BY position
+	`, todoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []ChecklistItem{}
+	for rows.Next() {
+		var c ChecklistItem
+		if err := rows.Scan(checklistDest(&c)...); err != nil {
+			return nil, err
+		}
+		items = append(items, c)
+	}
+	return items, rows.Err()
+}
+
+func (r *PostgresRepo) GetChecklistItem(ctx context.Context, id, todoID int64) (*ChecklistItem, error) {
+	var c ChecklistItem
+	err := r.db.QueryRow(ctx, `
+		SELECT `+checklistColumns+`
+		FROM checklist_items
+		WHERE id = $1 AND todo_id = $2
+	`, id, todoID).Scan(checklistDest(&c)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrChecklistItemNotFound
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+// CreateChecklistItem ต่อท้าย checklist ของ todo เสมอ
+func (r *PostgresRepo) CreateChecklistItem(ctx context.Context, item *ChecklistItem) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := lockChecklist(ctx, tx, item.TodoID); err != nil {
+			return err
+		}
+
+		var (
+			count int
+			last  string
+		)
+		if err := tx.QueryRow(ctx, `
+			SELECT COUNT(*), COALESCE(MAX(position), '')
+			FROM checklist_items
+			WHERE todo_id = $1
+		`, item.TodoID).Scan(&count, &last); err != nil {
+			return err
+		}
+		if count >= MaxChecklistItems {
+			return ErrChecklistFull
+		}
+
+		pos, err := rank.After(last)
+		if err != nil {
+			return err
+		}
+		item.Position = pos
+
+		return tx.QueryRow(ctx, `
+			INSERT INTO checklist_items (todo_id, title, is_done, position)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, created_at, updated_at
+		`, item.TodoID, item.Title, item.IsDone, item.Position).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt)
+	})
+}
+
+func (r *PostgresRepo) UpdateChecklistItem(ctx context.Context, item *ChecklistItem) error {
+	err := r.db.QueryRow(ctx, `
+		UPDATE checklist_items
+		SET title = $1, is_done = $2
+		WHERE id = $3 AND todo_id = $4
+		RETURNING updated_at
+	`, item.Title, item.IsDone, item.ID, item.TodoID).Scan(&item.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrChecklistItemNotFound
+	}
+	return err
+}
+
+func (r *PostgresRepo) DeleteChecklistItem(ctx context.Context, id, todoID int64) error {
+	cmdTag, err := r.db.Exec(ctx, `
+		DELETE FROM checklist_items
+		WHERE id = $1 AND todo_id = $2
+	`, id, todoID)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrChecklistItemNotFound
+	}
+	return nil
+}
+
+func (r *PostgresRepo) MoveChecklistItem(ctx context.Context, id, todoID int64, beforeID, afterID *int64) (*ChecklistItem, error) {
+	if beforeID == nil && afterID == nil {
+		return nil, ErrInvalidChecklistMove
+	}
+	if (beforeID != nil && *beforeID == id) || (afterID != nil && *afterID == id) {
+		return nil, ErrInvalidChecklistMove
+	}
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := lockChecklist(ctx, tx, todoID); err != nil {
+			return err
+		}
+
+		lower, upper, err := postgres.NeighborPositionsIn(ctx, tx, "checklist_items", "todo_id", todoID, id, beforeID, afterID)
+		if err != nil {
+			if errors.Is(err, postgres.ErrAnchorNotFound) {
+				return ErrInvalidChecklistMove
+			}
+			return err
+		}
+
+		pos, err := rank.Between(lower, upper)
+		if err != nil {
+			return ErrInvalidChecklistMove
+		}
+
+		cmdTag, err := tx.Exec(ctx, `
+			UPDATE checklist_items
+			SET position = $1
+			WHERE id = $2 AND todo_id = $3
+		`, pos, id, todoID)
+		if err != nil {
+			return err
+		}
+		if cmdTag.RowsAffected() == 0 {
+			return ErrChecklistItemNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetChecklistItem(ctx, id, todoID)
+}
+
+// CopyChecklist position เดิมใช้ต่อได้เลย (unique แค่ภายใน todo เดียวกัน)
+func (r *PostgresRepo) CopyChecklist(ctx context.Context, fromTodoID, toTodoID int64) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO checklist_items (todo_id, title, position)
+		SELECT $2, title, position
+		FROM checklist_items
+		WHERE todo_id = $1
+	`, fromTodoID, toTodoID)
+	return err
+}
+
+// ================== Tags ==================
+
+var (
+	ErrTagNotFound = errors.New("tag_ids must reference your own tags")
+	ErrTooManyTags = errors.New("a todo can have at most 20 tags")
+)
+
+// MaxTodoTags จำนวน tag สูงสุดต่อ todo (ต่อ user)
+const MaxTodoTags = 20
+
+func (r *PostgresRepo) OwnedTags(ctx context.Context, userID int64, tagIDs []int64) ([]TagRef, error) {
+	tags := []TagRef{}
+	if len(tagIDs) == 0 {
+		return tags, nil
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, name, color
+		FROM tags
+		WHERE user_id = $1 AND id = ANY($2)
+		ORDER BY lower(name)
+	`, userID, tagIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t TagRef
+		if err := rows.Scan(&t.ID, &t.Name, &t.Color); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// tagIDs ไม่ซ้ำกันแล้ว (service ตัดให้)
+	if len(tags) != len(tagIDs) {
+		return nil, ErrTagNotFound
+	}
+	return tags, nil
+}
+
+// TagSet tag ชุดใหม่ของ user คนหนึ่งบน todo (tag เป็นของคนที่แก้ ไม่ใช่ของเจ้าของ todo)
+type TagSet struct {
+	UserID int64
+	IDs    []int64 // ไม่ซ้ำกัน (service ตัดให้)
+}
+
+// setTags แทนที่ tag ของ user บน todo ด้วยชุดนี้ (tag ของคนอื่นบน todo เดียวกันไม่โดน) nil = ไม่แตะ
+// tag ที่ไม่ใช่ของ user (ถูกลบระหว่างทาง) = ErrTagNotFound ทั้ง transaction ยกเลิก
+func setTags(ctx context.Context, tx pgx.Tx, todoID int64, tags *TagSet) error {
+	if tags == nil {
+		return nil
+	}
+	// nil = NULL ทำให้ NOT (... = ANY(NULL)) ไม่ลบอะไรเลย
+	ids := tags.IDs
+	if ids == nil {
+		ids = []int64{}
+	}
+
+	// lock tag ไว้กันถูกลบก่อน commit
+	var owned int
+	if err := tx.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM (
+			SELECT id FROM tags
+			WHERE user_id = $1 AND id = ANY($2)
+			FOR SHARE
+		) t
+	`, tags.UserID, ids).Scan(&owned); err != nil {
+		return err
+	}
+	if owned != len(ids) {
+		return ErrTagNotFound
+	}
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM todo_tags tt
+		USING tags tg
+		WHERE tt.tag_id = tg.id
+		  AND tt.todo_id = $1
+		  AND tg.user_id = $2
+		  AND NOT (tt.tag_id = ANY($3))
+	`, todoID, tags.UserID, ids); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO todo_tags (todo_id, tag_id)
+		SELECT $1, unnest($2::BIGINT[])
+		ON CONFLICT DO NOTHING
+	`, todoID, ids)
+	return err
+}
+
+func (r *PostgresRepo) CopyTags(ctx context.Context, fromTodoID, toTodoID int64) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO todo_tags (todo_id, tag_id)
+		SELECT $2, tag_id
+		FROM todo_tags
+		WHERE todo_id = $1
+		ON CONFLICT DO NOTHING
+	`, fromTodoID, toTodoID)
+	return err
+}
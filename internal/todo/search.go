@@ -0,0 +1,50 @@
+package todo
+
+import (
+	"errors"
+	"html"
+	"strings"
+	"unicode"
+)
+
+var ErrEmptySearch = errors.New("q is required")
+
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+// ts_headline ไม่ escape HTML ให้ ถ้าใส่ <mark> ตรง ๆ title ที่มี <script> ก็หลุดออกไปทั้งอย่างนั้น
+// จึงให้ ts_headline คั่นคำที่ตรงด้วยตัวอักษร private use (ตัดออกจากข้อความก่อน) แล้วค่อย escape + ใส่ <mark> ใน Go
+const (
+	highlightStart = "\uE000"
+	highlightStop  = "\uE001"
+)
+
+const headlineOptions = `StartSel=` + highlightStart + `, StopSel=` + highlightStop + `, HighlightAll=true`
+const snippetOptions = `StartSel=` + highlightStart + `, StopSel=` + highlightStop + `, MaxWords=20, MinWords=5, MaxFragments=2, FragmentDelimiter=" … "`
+
+var highlightReplacer = strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>")
+
+// highlightHTML escape ผลของ ts_headline แล้วเปลี่ยนตัวคั่นเป็น <mark> (ผลลัพธ์เป็น HTML ที่ใส่หน้าเว็บได้เลย)
+func highlightHTML(s string) string {
+	return highlightReplacer.Replace(html.EscapeString(s))
+}
+
+// prefixTSQuery แปลงคำค้นเป็น tsquery แบบ prefix ทุกคำ เช่น "buy mil" -> "buy:* & mil:*"
+// เก็บแค่ตัวอักษร / ตัวเลข / สระวรรณยุกต์ (mark) ตัด operator ของ tsquery ทิ้งหมด
+func prefixTSQuery(q string) string {
+	var terms []string
+	for _, word := range strings.Fields(q) {
+		term := strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) {
+				return unicode.ToLower(r)
+			}
+			return -1
+		}, word)
+		if term != "" {
+			terms = append(terms, term+":*")
+		}
+	}
+	return strings.Join(terms, " & ")
+}
@@ -0,0 +1,204 @@
+package todo
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Nasaee/go-todo-backend/pkg/civil"
+	"github.com/Nasaee/go-todo-backend/pkg/rrule"
+)
+
+var (
+	ErrInvalidScope    = errors.New("scope must be this or future")
+	ErrInvalidTimezone = errors.New("timezone must be an IANA name such as Asia/Bangkok")
+	ErrTimezoneNoRule  = errors.New("timezone only applies to timed or recurring todos (send start_at or rrule too)")
+)
+
+// Scope ของการแก้ / ลบ todo ที่เกิดซ้ำ (?scope=)
+type Scope string
+
+const (
+	ScopeThis   Scope = "this"   // เฉพาะ occurrence นี้ (ค่าเริ่มต้น)
+	ScopeFuture Scope = "future" // occurrence นี้และที่จะเกิดต่อจากนี้
+)
+
+func ParseScope(s string) (Scope, error) {
+	switch Scope(strings.ToLower(strings.TrimSpace(s))) {
+	case "", ScopeThis:
+		return ScopeThis, nil
+	case ScopeFuture:
+		return ScopeFuture, nil
+	}
+	return "", ErrInvalidScope
+}
+
+// Series = todo ที่เกิดซ้ำตาม RRULE มีแถวจริงทีละ occurrence
+// ทำ occurrence ปัจจุบันเสร็จ (หรือลบเฉพาะอันนี้) แล้วค่อยสร้างอันถัดไปจากต้นแบบใน Series
+type Series struct {
+	ID       int64
+	RRule    string
+	Timezone string
+	DTStart  civil.Date // occurrence แรก (COUNT นับจากตรงนี้)
+
+	// ต้นแบบของ occurrence ถัดไป
+	Title        string
+	Description  *string
+	DurationDays *int // date_end - date_start (nil = ไม่มี date_end)
+	TodoGroupID  int64
+	AssigneeID   *int64
+
+	// todo ที่มีเวลา: เวลาเริ่มเป็นนาทีนับจากเที่ยงคืนตาม Timezone (nil = todo ทั้งวัน)
+	// ข้าม DST แล้วยังเริ่มเวลาเดิมของวันนั้น
+	StartMinute     *int
+	DurationMinutes *int // end_at - start_at (nil = ไม่มี end_at)
+}
+
+// normalizeRRule ตรวจ rule แล้วคืนรูปแบบมาตรฐาน ("" = ไม่เกิดซ้ำ)
+func normalizeRRule(s string) (string, error) {
+	if strings.TrimSpace(s) == "" {
+		return "", nil
+	}
+	rule, err := rrule.Parse(s)
+	if err != nil {
+		return "", err
+	}
+	return rule.String(), nil
+}
+
+func loadLocation(tz string) (*time.Location, error) {
+	// "Local" คือ timezone ของ server ไม่ใช่ของ user
+	if tz == "" || tz == "Local" {
+		return nil, ErrInvalidTimezone
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, ErrInvalidTimezone
+	}
+	return loc, nil
+}
+
+// seriesFrom ใช้ todo (ค่าหลังแก้) เป็นต้นแบบ และเป็น occurrence แรกของ series ใหม่
+func seriesFrom(t *Todo, rule, tz string) *Series {
+	s := &Series{
+		RRule:    rule,
+		Timezone: tz,
+		DTStart:  t.DateStart,
+	}
+	s.copyTemplate(t)
+	return s
+}
+
+// copyTemplate เอาค่าของ todo มาเป็นต้นแบบของ occurrence ถัดไป
+func (s *Series) copyTemplate(t *Todo) {
+	s.Title = t.Title
+	s.Description = t.Description
+	s.TodoGroupID = t.TodoGroupID
+	s.AssigneeID = t.AssigneeID
+	s.DurationDays = nil
+	if t.DateEnd != nil {
+		d := t.DateEnd.DaysSince(t.DateStart)
+		s.DurationDays = &d
+	}
+
+	// todo ที่มีเวลาอยู่ใน timezone เดียวกับ series เสมอ (service ดูแลให้)
+	s.StartMinute, s.DurationMinutes = nil, nil
+	if t.StartAt != nil {
+		start := t.StartAt
+		if loc, err := loadLocation(s.Timezone); err == nil {
+			local := start.In(loc)
+			start = &local
+		}
+		m := start.Hour()*60 + start.Minute()
+		s.StartMinute = &m
+		if t.EndAt != nil {
+			d := int(t.EndAt.Sub(*t.StartAt).Minutes())
+			s.DurationMinutes = &d
+		}
+	}
+}
+
+// nextAfter occurrence ถัดจากวัน after (false = rule จบแล้ว)
+// คิดวันใน timezone ของ series ข้าม DST แล้ววันยังตรงตาม rule
+func (s *Series) nextAfter(after civil.Date) (civil.Date, bool, error) {
+	rule, err := rrule.Parse(s.RRule)
+	if err != nil {
+		return civil.Date{}, false, err
+	}
+	loc, err := loadLocation(s.Timezone)
+	if err != nil {
+		return civil.Date{}, false, err
+	}
+
+	next, ok := rule.Next(s.DTStart.StartIn(loc), after.StartIn(loc))
+	if !ok {
+		return civil.Date{}, false, nil
+	}
+	return civil.DateOf(next), true, nil
+}
+
+// remainingRule ลด COUNT ของ rule ด้วยจำนวน occurrence ของ s ที่อยู่ก่อนวัน slot (ใช้ตอนแยก series ใหม่ที่ slot)
+// series ที่แยกออกมาจึงไม่ได้ COUNT เต็มใหม่ false = COUNT หมดแล้ว ไม่ต้องเกิดซ้ำอีก
+func (s *Series) remainingRule(rule string, slot civil.Date) (string, bool, error) {
+	r, err := rrule.Parse(rule)
+	if err != nil {
+		return "", false, err
+	}
+	if r.Count == 0 {
+		return rule, true, nil
+	}
+
+	cur, err := rrule.Parse(s.RRule)
+	if err != nil {
+		return "", false, err
+	}
+	loc, err := loadLocation(s.Timezone)
+	if err != nil {
+		return "", false, err
+	}
+
+	dtstart := s.DTStart.StartIn(loc)
+	emitted := len(cur.Between(dtstart, dtstart, slot.StartIn(loc).Add(-time.Nanosecond), r.Count))
+	if emitted >= r.Count {
+		return "", false, nil
+	}
+	r.Count -= emitted
+	return r.String(), true, nil
+}
+
+// occurrence สร้าง todo ของวัน date จากต้นแบบ
+func (s *Series) occurrence(ownerID int64, date civil.Date) (*Todo, error) {
+	t := &Todo{
+		Title:          s.Title,
+		Description:    s.Description,
+		DateStart:      date,
+		AllDay:         true,
+		UserID:         ownerID,
+		TodoGroupID:    s.TodoGroupID,
+		AssigneeID:     s.AssigneeID,
+		SeriesID:       &s.ID,
+		RRule:          &s.RRule,
+		OccurrenceDate: &date,
+	}
+	if s.DurationDays != nil {
+		end := date.AddDays(*s.DurationDays)
+		t.DateEnd = &end
+	}
+
+	if s.StartMinute != nil {
+		loc, err := loadLocation(s.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		start := time.Date(date.Year(), date.Month(), date.Day(), 0, *s.StartMinute, 0, 0, loc)
+		t.StartAt = &start
+		if s.DurationMinutes != nil {
+			end := start.Add(time.Duration(*s.DurationMinutes) * time.Minute)
+			t.EndAt = &end
+		}
+		tz := s.Timezone
+		t.Timezone = &tz
+		setTimedDates(t, loc)
+	}
+	return t, nil
+}
@@ -3,48 +3,212 @@ package todo
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
 	"time"
+
+	"github.com/Nasaee/go-todo-backend/internal/auth"
+	"github.com/Nasaee/go-todo-backend/internal/event"
+	"github.com/Nasaee/go-todo-backend/internal/todogroup"
+	"github.com/Nasaee/go-todo-backend/pkg/civil"
 )
 
 var (
-	ErrInvalidInput     = errors.New("invalid input")
-	ErrInvalidDateRange = errors.New("date_end cannot be before date_start")
+	ErrInvalidInput      = errors.New("invalid input")
+	ErrInvalidDateRange  = errors.New("date_end cannot be before date_start")
+	ErrGroupNotFound     = errors.New("todo group does not exist")
+	ErrGroupForbidden    = errors.New("you cannot add todos to this todo group")
+	ErrForbidden         = errors.New("you do not have permission to modify this todo")
+	ErrGroupOwnerChange  = errors.New("todos can only move between groups with the same owner")
+	ErrAssigneeNotMember = errors.New("assignee must be a member of the todo's group")
+	ErrGroupRequired     = errors.New("todo_group_id is required outside your personal workspace")
 )
 
+// EventTodoAssigned ส่งออกไปทุกครั้งที่ todo ได้คนรับผิดชอบคนใหม่ (เอาไปทำแจ้งเตือน)
+const EventTodoAssigned = "todo.assigned"
+
+type AssignedEvent struct {
+	TodoID      int64  `json:"todo_id"`
+	TodoGroupID int64  `json:"todo_group_id"`
+	Title       string `json:"title"`
+	AssigneeID  int64  `json:"assignee_id"`
+	AssignedBy  int64  `json:"assigned_by"`
+}
+
 // Service คือ business logic layer
 type Service interface {
 	CreateTodo(ctx context.Context, userID int64, in CreateTodoInput) (*Todo, error)
 	GetTodo(ctx context.Context, id, userID int64) (*Todo, error)
-	ListTodos(ctx context.Context, userID int64) ([]Todo, error)
-	ListTodayTodos(ctx context.Context, userID int64) ([]Todo, error)
-	ListTomorrowTodos(ctx context.Context, userID int64) ([]Todo, error)
-	ListThisWeekTodos(ctx context.Context, userID int64) ([]Todo, error)
-	UpdateTodo(ctx context.Context, id, userID int64, in UpdateTodoInput) (*Todo, error)
-	DeleteTodo(ctx context.Context, id, userID int64) error
+	// ListTodos คืนทีละหน้า (filter / sort / cursor ดู ListQuery)
+	ListTodos(ctx context.Context, userID int64, q ListQuery) (*TodoPage, error)
+	// SearchTodos ค้นใน title / description ของ todos ที่ user มองเห็น เรียงตามความเกี่ยวข้อง
+	SearchTodos(ctx context.Context, userID int64, q SearchQuery) ([]SearchResult, error)
+	ListTodayTodos(ctx context.Context, userID int64, opts ListOptions) ([]Todo, error)
+	ListTomorrowTodos(ctx context.Context, userID int64, opts ListOptions) ([]Todo, error)
+	ListThisWeekTodos(ctx context.Context, userID int64, opts ListOptions) ([]Todo, error)
+	// ListOverdue / ListUpcoming / ListRange จัดกลุ่มตามวัน (ดู Agenda)
+	ListOverdue(ctx context.Context, userID int64, opts ListOptions) (*Agenda, error)
+	ListUpcoming(ctx context.Context, userID int64, q UpcomingQuery) (*Agenda, error)
+	ListRange(ctx context.Context, userID int64, q RangeQuery) (*Agenda, error)
+	// ListCompleted logbook: todos ที่ทำเสร็จในช่วง from..to (ไม่ส่ง = DefaultCompletedDays วันล่าสุด)
+	ListCompleted(ctx context.Context, userID int64, q RangeQuery) (*Agenda, error)
+	ListGroupTodos(ctx context.Context, userID, groupID int64, opts ListOptions) ([]Todo, error)
+	// ListMatrix แบ่ง todos ที่ยังไม่เสร็จเป็น 4 ช่องแบบ Eisenhower (ดู Matrix)
+	ListMatrix(ctx context.Context, userID int64, q MatrixQuery) (*Matrix, error)
+	// UpdateTodo (PUT) แทนที่ทั้งก้อน, PatchTodo (PATCH) แก้เฉพาะ field ที่ส่งมา
+	// scope มีผลกับ todo ที่เกิดซ้ำ: this = เฉพาะ occurrence นี้, future = ต้นแบบของ occurrence ถัดๆ ไปด้วย
+	UpdateTodo(ctx context.Context, id, userID int64, in UpdateTodoInput, scope Scope) (*Todo, error)
+	PatchTodo(ctx context.Context, id, userID int64, in PatchTodoInput, scope Scope) (*Todo, error)
+	// DeleteTodo scope=this ข้ามไป occurrence ถัดไป, scope=future หยุดการเกิดซ้ำทั้ง series
+	DeleteTodo(ctx context.Context, id, userID int64, scope Scope) error
+	MoveTodo(ctx context.Context, id, userID int64, in MoveTodoInput) (*Todo, error)
+
+	// checklist ของ todo (ดูได้ = มองเห็น todo, แก้ได้ = แก้ todo ได้)
+	// ติ๊กครบทุกข้อแล้ว todo ที่ตั้ง auto_complete ไว้เสร็จเอง
+	ListChecklist(ctx context.Context, todoID, userID int64) ([]ChecklistItem, error)
+	AddChecklistItem(ctx context.Context, todoID, userID int64, in CreateChecklistItemInput) (*ChecklistItem, error)
+	PatchChecklistItem(ctx context.Context, todoID, itemID, userID int64, in PatchChecklistItemInput) (*ChecklistItem, error)
+	DeleteChecklistItem(ctx context.Context, todoID, itemID, userID int64) error
+	MoveChecklistItem(ctx context.Context, todoID, itemID, userID int64, in MoveTodoInput) (*ChecklistItem, error)
 }
 
 type service struct {
 	repo TodoRepository
-	// ถ้าอนาคตอยากเช็คว่า todo_group เป็นของ user จริง
-	// สามารถ inject groupRepo เพิ่มตรงนี้ได้
+	// ใช้เช็คสิทธิ์ของ user ใน todo_group ก่อนเขียน todo
+	groupRepo todogroup.TodoGroupRepository
+	events    event.Publisher
 }
 
-func NewService(repo TodoRepository) Service {
-	return &service{repo: repo}
+func NewService(repo TodoRepository, groupRepo todogroup.TodoGroupRepository, events event.Publisher) Service {
+	return &service{repo: repo, groupRepo: groupRepo, events: events}
 }
 
 // ===== helper validate =====
 
-func validateDateRange(start time.Time, end *time.Time) error {
+func validateDateRange(start civil.Date, end *civil.Date) error {
 	if end == nil {
 		return nil
 	}
-	if end.Before(start) {
+	if end.Before(start.Time) {
 		return ErrInvalidDateRange
 	}
 	return nil
 }
 
+// applyTiming start_at = nil คือ todo ทั้งวัน (ไม่มี end_at / timezone)
+// todo ที่มีเวลาใช้ timezone ของ todo (ไม่มี = ของ user) แล้วคิด date_start / date_end จาก start_at / end_at
+func (s *service) applyTiming(ctx context.Context, userID int64, t *Todo) error {
+	if t.StartAt == nil {
+		if t.EndAt != nil {
+			return ErrEndWithoutStart
+		}
+		t.AllDay, t.Timezone = true, nil
+		return nil
+	}
+	if t.EndAt != nil && t.EndAt.Before(*t.StartAt) {
+		return ErrInvalidTimeRange
+	}
+
+	tz, err := s.todoTimezone(ctx, userID, t.Timezone)
+	if err != nil {
+		return err
+	}
+	loc, err := loadLocation(tz)
+	if err != nil {
+		return err
+	}
+
+	start := t.StartAt.UTC()
+	t.StartAt, t.Timezone = &start, &tz
+	if t.EndAt != nil {
+		end := t.EndAt.UTC()
+		t.EndAt = &end
+	}
+	setTimedDates(t, loc)
+	return nil
+}
+
+// checkGroupWrite กัน user เอา todo ไปผูกกับ group ที่ตัวเองไม่มีสิทธิ์เขียน (เดา id เอา / เป็นแค่ viewer)
+// คืน id เจ้าของ group เพราะ todos.user_id ต้องเป็นเจ้าของ group เสมอ (composite FK)
+// ถึงคนเพิ่มจะเป็น editor ที่ได้รับแชร์มาก็ตาม
+// group ต้องอยู่ใน workspace ของ route ด้วย (ถ้ามาทาง /workspaces/{wid}/...)
+func (s *service) checkGroupWrite(ctx context.Context, groupID, userID int64) (int64, error) {
+	access, err := s.groupRepo.GetAccess(ctx, groupID, userID)
+	if err != nil {
+		if errors.Is(err, todogroup.ErrNotFound) {
+			return 0, ErrGroupNotFound
+		}
+		return 0, err
+	}
+	if !auth.InWorkspace(ctx, access.WorkspaceID) {
+		return 0, ErrGroupNotFound
+	}
+
+	if !access.Role.AtLeast(todogroup.RoleEditor) {
+		return 0, ErrGroupForbidden
+	}
+
+	return access.OwnerID, nil
+}
+
+// checkAssignee คนรับผิดชอบต้องเข้าถึง group ได้ (แชร์ให้ตรง ๆ หรือเป็นสมาชิก team workspace, role ไหนก็ได้ viewer ก็รับงานได้)
+// DB ไม่มี FK เช็คให้แล้ว เสียสิทธิ์ทีหลัง trigger เอา assignment ออกเอง (ดู migration 00015)
+func (s *service) checkAssignee(ctx context.Context, groupID, assigneeID int64) error {
+	access, err := s.groupRepo.GetAccess(ctx, groupID, assigneeID)
+	if err != nil {
+		return err
+	}
+	if access.Role == "" {
+		return ErrAssigneeNotMember
+	}
+	return nil
+}
+
+// publishAssigned ส่ง event ไม่ผ่านก็แค่ log ไว้ ไม่ให้ request ที่บันทึกสำเร็จแล้วพัง
+func (s *service) publishAssigned(ctx context.Context, t *Todo, assignedBy int64) {
+	e := event.New(EventTodoAssigned, AssignedEvent{
+		TodoID:      t.ID,
+		TodoGroupID: t.TodoGroupID,
+		Title:       t.Title,
+		AssigneeID:  *t.AssigneeID,
+		AssignedBy:  assignedBy,
+	})
+	if err := s.events.Publish(ctx, e); err != nil {
+		slog.Error("failed to publish event", "type", e.Type, "todo_id", t.ID, "error", err)
+	}
+}
+
+// getVisible ดึง todo ที่ user มองเห็น (อยู่คนละ workspace กับ route = ไม่เจอ)
+func (s *service) getVisible(ctx context.Context, id, userID int64) (*Todo, error) {
+	t, err := s.repo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !auth.InWorkspace(ctx, t.WorkspaceID) {
+		return nil, ErrNotFound
+	}
+	return t, nil
+}
+
+// getWritable ดึง todo ที่ user มองเห็น แล้วเช็คว่าแก้ได้ (เป็น owner / editor ของ group)
+func (s *service) getWritable(ctx context.Context, id, userID int64) (*Todo, error) {
+	t, err := s.getVisible(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	access, err := s.groupRepo.GetAccess(ctx, t.TodoGroupID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !access.Role.AtLeast(todogroup.RoleEditor) {
+		return nil, ErrForbidden
+	}
+
+	return t, nil
+}
+
 // ===== Create =====
 
 func (s *service) CreateTodo(ctx context.Context, userID int64, in CreateTodoInput) (*Todo, error) {
@@ -53,122 +217,942 @@ func (s *service) CreateTodo(ctx context.Context, userID int64, in CreateTodoInp
 		return nil, ErrInvalidInput
 	}
 
-	// date_start required (ห้าม zero)
-	if in.DateStart.IsZero() {
-		return nil, ErrInvalidInput
-	}
-
-	// todo_group_id required (เพราะใช้ identity เริ่มจาก 1)
-	if in.TodoGroupID == 0 {
+	// date_start required (ห้าม zero) ยกเว้น todo ที่มีเวลา (คิดจาก start_at)
+	if in.StartAt == nil && in.DateStart.IsZero() {
 		return nil, ErrInvalidInput
 	}
 
-	// validate date range (date_end optional)
-	if err := validateDateRange(in.DateStart, in.DateEnd); err != nil {
-		return nil, err
-	}
-
 	todo := &Todo{
 		Title:       in.Title,
 		Description: in.Description,
 		DateStart:   in.DateStart,
 		DateEnd:     in.DateEnd, // nil OK
+		StartAt:     in.StartAt,
+		EndAt:       in.EndAt,
+		Timezone:    in.Timezone,
 		IsSuccess:   false,
-		UserID:      userID,
-		TodoGroupID: in.TodoGroupID,
+		AssigneeID:  in.AssigneeID,
+
+		Priority:     in.Priority,
+		Important:    in.Important,
+		AutoComplete: in.AutoComplete,
+	}
+	if err := s.applyTiming(ctx, userID, todo); err != nil {
+		return nil, err
 	}
 
-	if err := s.repo.Create(ctx, todo); err != nil {
+	// validate date range (date_end optional)
+	if err := validateDateRange(todo.DateStart, todo.DateEnd); err != nil {
 		return nil, err
 	}
 
+	// ไม่ส่ง todo_group_id มา (0 เพราะ identity เริ่มจาก 1) = ใส่ inbox ของ user
+	// inbox อยู่ใน personal workspace ถ้า route เป็น team workspace ต้องระบุ group เอง
+	inbox := in.TodoGroupID == 0
+	if inbox {
+		inboxID, err := s.groupRepo.GetInboxID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, todogroup.ErrNotFound) {
+				return nil, ErrGroupNotFound
+			}
+			return nil, err
+		}
+		in.TodoGroupID = inboxID
+	}
+
+	ownerID, err := s.checkGroupWrite(ctx, in.TodoGroupID, userID)
+	if err != nil {
+		if inbox && errors.Is(err, ErrGroupNotFound) {
+			return nil, ErrGroupRequired
+		}
+		return nil, err
+	}
+
+	if in.AssigneeID != nil {
+		if err := s.checkAssignee(ctx, in.TodoGroupID, *in.AssigneeID); err != nil {
+			return nil, err
+		}
+	}
+
+	todo.UserID = ownerID
+	todo.TodoGroupID = in.TodoGroupID
+
+	if todo.Tags, err = tagRefs(in.TagIDs); err != nil {
+		return nil, err
+	}
+	if len(todo.Tags) > 0 {
+		if todo.Tags, err = s.repo.OwnedTags(ctx, userID, tagIDs(todo.Tags)); err != nil {
+			return nil, err
+		}
+	}
+
+	if in.RRule != nil {
+		rule, err := normalizeRRule(*in.RRule)
+		if err != nil {
+			return nil, err
+		}
+		if rule != "" {
+			// todo ที่มีเวลาใช้ timezone เดียวกับ series
+			tz, err := s.todoTimezone(ctx, userID, todo.Timezone)
+			if err != nil {
+				return nil, err
+			}
+			if err := s.startSeries(ctx, todo, rule, tz); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var tags *TagSet
+	if len(todo.Tags) > 0 {
+		tags = &TagSet{UserID: userID, IDs: tagIDs(todo.Tags)}
+	}
+
+	if err := s.repo.Create(ctx, todo, tags); err != nil {
+		// series ที่สร้างไว้ยังไม่มี todo ผูก ลบทิ้ง
+		if todo.SeriesID != nil {
+			_ = s.repo.DeleteSeries(ctx, *todo.SeriesID)
+		}
+		return nil, err
+	}
+
+	if todo.AssigneeID != nil {
+		s.publishAssigned(ctx, todo, userID)
+	}
+
 	return todo, nil
 }
 
 // ===== Get / List =====
 
 func (s *service) GetTodo(ctx context.Context, id, userID int64) (*Todo, error) {
-	return s.repo.GetByID(ctx, id, userID)
+	return s.getVisible(ctx, id, userID)
+}
+
+// list ทุกตัว scope ด้วย workspace ของ route (ถ้ามี)
+
+func (s *service) ListTodos(ctx context.Context, userID int64, q ListQuery) (*TodoPage, error) {
+	q.WorkspaceID = auth.WorkspaceScope(ctx)
+	// กรอง is_success เองแล้ว = ไม่ต้องซ่อนที่เสร็จแล้วซ้ำ
+	if q.IsSuccess != nil {
+		q.IncludeCompleted = true
+	}
+
+	switch {
+	case q.Limit <= 0:
+		q.Limit = DefaultListLimit
+	case q.Limit > MaxListLimit:
+		q.Limit = MaxListLimit
+	}
+
+	q.GroupIDs = nil
+	if q.GroupID != nil {
+		q.GroupIDs = []int64{*q.GroupID}
+		if q.IncludeDescendants {
+			ids, err := s.groupRepo.GetSubtreeIDs(ctx, *q.GroupID, userID, q.IncludeArchived)
+			// มองไม่เห็น group ตั้งต้น = ไม่มีอะไรให้ดู (เหมือน filter group เดียวที่มองไม่เห็น)
+			if errors.Is(err, todogroup.ErrNotFound) {
+				return &TodoPage{Items: []Todo{}}, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			q.GroupIDs = ids
+		}
+	}
+
+	return s.repo.List(ctx, userID, q)
+}
+
+func (s *service) SearchTodos(ctx context.Context, userID int64, q SearchQuery) ([]SearchResult, error) {
+	q.Text = strings.TrimSpace(q.Text)
+	if q.Text == "" {
+		return nil, ErrEmptySearch
+	}
+
+	q.WorkspaceID = auth.WorkspaceScope(ctx)
+	// ค้นหาไม่ใช่ list ที่กำลังทำอยู่ ค้นเจอที่เสร็จแล้วด้วยเสมอ
+	q.IncludeCompleted = true
+	switch {
+	case q.Limit <= 0:
+		q.Limit = DefaultSearchLimit
+	case q.Limit > MaxSearchLimit:
+		q.Limit = MaxSearchLimit
+	}
+
+	return s.repo.Search(ctx, userID, q)
+}
+
+// today / tomorrow / this-week นับวันตาม timezone ของ user (สัปดาห์เริ่มวันจันทร์)
+// todo หลายวันอยู่ในทุกวันที่มันคาบเกี่ยว
+
+func (s *service) ListTodayTodos(ctx context.Context, userID int64, opts ListOptions) ([]Todo, error) {
+	return s.listDays(ctx, userID, opts, func(today civil.Date) civil.Date { return today }, 1)
+}
+
+func (s *service) ListTomorrowTodos(ctx context.Context, userID int64, opts ListOptions) ([]Todo, error) {
+	return s.listDays(ctx, userID, opts, func(today civil.Date) civil.Date { return today.AddDays(1) }, 1)
+}
+
+func (s *service) ListThisWeekTodos(ctx context.Context, userID int64, opts ListOptions) ([]Todo, error) {
+	return s.listDays(ctx, userID, opts, weekStart, 7)
+}
+
+// listDays todos ที่คาบเกี่ยวกับช่วง days วันนับจาก from(วันนี้ของ user)
+func (s *service) listDays(ctx context.Context, userID int64, opts ListOptions, from func(today civil.Date) civil.Date, days int) ([]Todo, error) {
+	opts.WorkspaceID = auth.WorkspaceScope(ctx)
+
+	_, loc, err := s.userLocation(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListDays(ctx, userID, daysFrom(from(civil.Today(loc)), days, loc), opts)
+}
+
+func (s *service) ListOverdue(ctx context.Context, userID int64, opts ListOptions) (*Agenda, error) {
+	opts.WorkspaceID = auth.WorkspaceScope(ctx)
+
+	tz, loc, err := s.userLocation(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	todos, err := s.repo.ListOverdue(ctx, userID, civil.DateOf(now.In(loc)), now, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Agenda{Timezone: tz, Days: groupByDue(todos, loc)}, nil
+}
+
+func (s *service) ListUpcoming(ctx context.Context, userID int64, q UpcomingQuery) (*Agenda, error) {
+	switch {
+	case q.Days <= 0:
+		q.Days = DefaultUpcomingDays
+	case q.Days > MaxAgendaDays:
+		q.Days = MaxAgendaDays
+	}
+
+	_, loc, err := s.userLocation(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.listCalendar(ctx, userID, daysFrom(civil.Today(loc), q.Days, loc), q.ListOptions)
+}
+
+func (s *service) ListRange(ctx context.Context, userID int64, q RangeQuery) (*Agenda, error) {
+	if q.From.IsZero() || q.To.IsZero() || q.To.Before(q.From.Time) {
+		return nil, ErrInvalidRange
+	}
+	days := q.To.DaysSince(q.From) + 1
+	if days > MaxAgendaDays {
+		return nil, ErrRangeTooLong
+	}
+
+	_, loc, err := s.userLocation(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.listCalendar(ctx, userID, daysFrom(q.From, days, loc), q.ListOptions)
+}
+
+func (s *service) ListCompleted(ctx context.Context, userID int64, q RangeQuery) (*Agenda, error) {
+	tz, loc, err := s.userLocation(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if q.To.IsZero() {
+		q.To = civil.Today(loc)
+	}
+	if q.From.IsZero() {
+		q.From = q.To.AddDays(1 - DefaultCompletedDays)
+	}
+	if q.To.Before(q.From.Time) {
+		return nil, ErrInvalidRange
+	}
+	if q.To.DaysSince(q.From)+1 > MaxAgendaDays {
+		return nil, ErrRangeTooLong
+	}
+
+	q.WorkspaceID = auth.WorkspaceScope(ctx)
+	q.IncludeCompleted = true
+	todos, err := s.repo.ListCompleted(ctx, userID, q.From.StartIn(loc), q.To.AddDays(1).StartIn(loc), q.ListOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Agenda{Timezone: tz, Days: groupByCompleted(todos, loc)}, nil
 }
 
-func (s *service) ListTodos(ctx context.Context, userID int64) ([]Todo, error) {
-	return s.repo.ListByUser(ctx, userID)
+// listCalendar todos ที่คาบเกี่ยวกับ w จัดลงทุกวันในช่วง
+func (s *service) listCalendar(ctx context.Context, userID int64, w DayWindow, opts ListOptions) (*Agenda, error) {
+	opts.WorkspaceID = auth.WorkspaceScope(ctx)
+
+	todos, err := s.repo.ListDays(ctx, userID, w, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Agenda{Timezone: w.Loc.String(), Days: buildCalendar(todos, w)}, nil
 }
 
-func (s *service) ListTodayTodos(ctx context.Context, userID int64) ([]Todo, error) {
-	return s.repo.ListToday(ctx, userID)
+// userLocation timezone ของ user
+func (s *service) userLocation(ctx context.Context, userID int64) (string, *time.Location, error) {
+	tz, err := s.repo.UserTimezone(ctx, userID)
+	if err != nil {
+		return "", nil, err
+	}
+	loc, err := loadLocation(tz)
+	if err != nil {
+		// timezone ใน DB ผ่านการ validate มาแล้ว ถ้ายังโหลดไม่ได้ใช้ UTC ไปก่อน
+		return "UTC", time.UTC, nil
+	}
+	return tz, loc, nil
 }
 
-func (s *service) ListTomorrowTodos(ctx context.Context, userID int64) ([]Todo, error) {
-	return s.repo.ListTomorrow(ctx, userID)
+// ListGroupTodos คืน todos ใน group (opts.IncludeDescendants = รวม group ลูกหลานด้วย)
+// group ที่ไม่ได้เป็นสมาชิกได้ todogroup.ErrNotFound เหมือน group ที่ไม่มีอยู่
+func (s *service) ListGroupTodos(ctx context.Context, userID, groupID int64, opts ListOptions) ([]Todo, error) {
+	g, err := s.groupRepo.GetByID(ctx, groupID, userID)
+	if err != nil {
+		return nil, err
+	}
+	// group ลูกหลานอยู่ workspace เดียวกับแม่เสมอ (FK) เช็คแค่ตัวตั้งต้นพอ
+	if !auth.InWorkspace(ctx, g.WorkspaceID) {
+		return nil, todogroup.ErrNotFound
+	}
+
+	groupIDs := []int64{groupID}
+	if opts.IncludeDescendants {
+		if groupIDs, err = s.groupRepo.GetSubtreeIDs(ctx, groupID, userID, opts.IncludeArchived); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.repo.ListByGroups(ctx, userID, groupIDs, opts)
 }
 
-func (s *service) ListThisWeekTodos(ctx context.Context, userID int64) ([]Todo, error) {
-	return s.repo.ListThisWeek(ctx, userID)
+func (s *service) ListMatrix(ctx context.Context, userID int64, q MatrixQuery) (*Matrix, error) {
+	q.WorkspaceID = auth.WorkspaceScope(ctx)
+	switch {
+	case q.UrgentDays <= 0:
+		q.UrgentDays = DefaultUrgentDays
+	case q.UrgentDays > MaxUrgentDays:
+		q.UrgentDays = MaxUrgentDays
+	}
+
+	tz, loc, err := s.userLocation(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	todos, err := s.repo.ListOpen(ctx, userID, q.ListOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	m := buildMatrix(todos, civil.Today(loc), q.UrgentDays)
+	m.Timezone = tz
+	return m, nil
 }
 
 // ===== Update =====
 
-func (s *service) UpdateTodo(ctx context.Context, id, userID int64, in UpdateTodoInput) (*Todo, error) {
-	// ดึงข้อมูลเดิมมาก่อน
-	existing, err := s.repo.GetByID(ctx, id, userID)
+func (s *service) UpdateTodo(ctx context.Context, id, userID int64, in UpdateTodoInput, scope Scope) (*Todo, error) {
+	// ดึงข้อมูลเดิมมาก่อน (viewer แก้ไม่ได้)
+	existing, err := s.getWritable(ctx, id, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	// title (optional แต่ถ้าส่งมา ห้ามค่าว่าง)
-	if in.Title != nil {
-		if *in.Title == "" {
-			return nil, ErrInvalidInput
+	if in.Title == "" {
+		return nil, fmt.Errorf("%w: title is required", ErrInvalidInput)
+	}
+	if in.StartAt == nil && in.DateStart.IsZero() {
+		return nil, fmt.Errorf("%w: date_start is required", ErrInvalidInput)
+	}
+	if in.TodoGroupID == 0 {
+		return nil, fmt.Errorf("%w: todo_group_id is required", ErrInvalidInput)
+	}
+
+	next := *existing
+	next.Title = in.Title
+	next.Description = in.Description
+	next.DateStart = in.DateStart
+	next.DateEnd = in.DateEnd
+	next.StartAt = in.StartAt
+	next.EndAt = in.EndAt
+	if in.Timezone != nil {
+		next.Timezone = in.Timezone
+	}
+	next.IsSuccess = in.IsSuccess
+	next.TodoGroupID = in.TodoGroupID
+	next.AssigneeID = in.AssigneeID
+	next.Priority = in.Priority
+	next.Important = in.Important
+	next.AutoComplete = in.AutoComplete
+	if next.Tags, err = tagRefs(in.TagIDs); err != nil {
+		return nil, err
+	}
+
+	rec := recurrence{set: true, timezone: in.Timezone}
+	if in.RRule != nil {
+		rec.rule = *in.RRule
+	}
+
+	return s.saveTodo(ctx, userID, existing, &next, rec, scope)
+}
+
+func (s *service) PatchTodo(ctx context.Context, id, userID int64, in PatchTodoInput, scope Scope) (*Todo, error) {
+	existing, err := s.getWritable(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	next := *existing
+
+	// title / date_start / is_success / todo_group_id ล้างค่าไม่ได้ (ส่ง null = ผิด)
+	if in.Title.Set {
+		if !in.Title.Valid || in.Title.Value == "" {
+			return nil, fmt.Errorf("%w: title cannot be empty", ErrInvalidInput)
+		}
+		next.Title = in.Title.Value
+	}
+
+	if in.Description.Set {
+		next.Description = in.Description.Ptr()
+	}
+
+	if in.DateStart.Set {
+		if !in.DateStart.Valid || in.DateStart.Value.IsZero() {
+			return nil, fmt.Errorf("%w: date_start cannot be empty", ErrInvalidInput)
+		}
+		next.DateStart = in.DateStart.Value
+	}
+
+	if in.DateEnd.Set {
+		next.DateEnd = in.DateEnd.Ptr()
+	}
+
+	// start_at: null = เปลี่ยนเป็น todo ทั้งวัน (end_at หายไปด้วย วันเดิมยังอยู่)
+	if in.StartAt.Set {
+		next.StartAt = in.StartAt.Ptr()
+		if next.StartAt == nil {
+			next.EndAt = nil
 		}
-		existing.Title = *in.Title
 	}
 
-	// description
-	if in.Description != nil {
-		existing.Description = in.Description
+	if in.EndAt.Set {
+		next.EndAt = in.EndAt.Ptr()
 	}
 
-	// date_start (ถ้าส่งมา ต้องไม่ zero)
-	if in.DateStart != nil {
-		if in.DateStart.IsZero() {
-			return nil, ErrInvalidInput
+	if in.Timezone.Set {
+		next.Timezone = in.Timezone.Ptr() // null = ของ user
+	}
+
+	// วันของ todo ที่มีเวลาตาม start_at / end_at แก้ date_start / date_end ตรง ๆ ไม่ได้
+	if next.StartAt != nil && !in.StartAt.Set && (in.DateStart.Set || in.DateEnd.Set) {
+		return nil, ErrTimedDate
+	}
+
+	if in.IsSuccess.Set {
+		if !in.IsSuccess.Valid {
+			return nil, fmt.Errorf("%w: is_success cannot be null", ErrInvalidInput)
+		}
+		next.IsSuccess = in.IsSuccess.Value
+	}
+
+	if in.TodoGroupID.Set {
+		if !in.TodoGroupID.Valid || in.TodoGroupID.Value == 0 {
+			return nil, fmt.Errorf("%w: todo_group_id cannot be empty", ErrInvalidInput)
+		}
+		next.TodoGroupID = in.TodoGroupID.Value
+	}
+
+	if in.AssigneeID.Set {
+		next.AssigneeID = in.AssigneeID.Ptr()
+	}
+
+	if in.Priority.Set {
+		next.Priority = in.Priority.Value // null = none
+	}
+
+	if in.Important.Set {
+		if !in.Important.Valid {
+			return nil, fmt.Errorf("%w: important cannot be null", ErrInvalidInput)
+		}
+		next.Important = in.Important.Value
+	}
+
+	if in.AutoComplete.Set {
+		if !in.AutoComplete.Valid {
+			return nil, fmt.Errorf("%w: auto_complete cannot be null", ErrInvalidInput)
+		}
+		next.AutoComplete = in.AutoComplete.Value
+	}
+
+	if in.TagIDs.Set || len(in.AddTagIDs) > 0 || len(in.RemoveTagIDs) > 0 {
+		ids := tagIDs(existing.Tags)
+		if in.TagIDs.Set {
+			ids = in.TagIDs.Value
+		}
+		ids = append(ids, in.AddTagIDs...)
+
+		remove := make(map[int64]bool, len(in.RemoveTagIDs))
+		for _, id := range in.RemoveTagIDs {
+			remove[id] = true
+		}
+		kept := []int64{}
+		for _, id := range ids {
+			if !remove[id] {
+				kept = append(kept, id)
+			}
+		}
+
+		if next.Tags, err = tagRefs(kept); err != nil {
+			return nil, err
+		}
+	}
+
+	// ส่ง timezone อย่างเดียว = ใช้ rrule เดิมกับ timezone ใหม่
+	var rec recurrence
+	switch {
+	case in.RRule.Set:
+		rec = recurrence{set: true, rule: in.RRule.Value, timezone: in.Timezone.Ptr()}
+	case in.Timezone.Set && existing.RRule != nil:
+		rec = recurrence{set: true, rule: *existing.RRule, timezone: in.Timezone.Ptr()}
+	case in.Timezone.Set && next.StartAt == nil:
+		return nil, ErrTimezoneNoRule
+	}
+
+	return s.saveTodo(ctx, userID, existing, &next, rec, scope)
+}
+
+// recurrence = rrule / timezone ที่ request ส่งมา (set = false คือไม่แตะเรื่องการเกิดซ้ำ)
+type recurrence struct {
+	set      bool
+	rule     string  // "" = เลิกเกิดซ้ำ
+	timezone *string // nil = ใช้ของ series เดิม (ไม่มี = ของ user)
+}
+
+// saveTodo validate ค่าใหม่ (next) เทียบกับค่าเดิม (prev) แล้วบันทึก ใช้ร่วมกันทั้ง PUT / PATCH
+func (s *service) saveTodo(ctx context.Context, userID int64, prev, next *Todo, rec recurrence, scope Scope) (*Todo, error) {
+	if next.TodoGroupID != prev.TodoGroupID {
+		ownerID, err := s.checkGroupWrite(ctx, next.TodoGroupID, userID)
+		if err != nil {
+			return nil, err
+		}
+		// ย้ายข้ามเจ้าของไม่ได้ (user_id / position ของ todo ผูกกับเจ้าของ group)
+		if ownerID != prev.UserID {
+			return nil, ErrGroupOwnerChange
 		}
-		existing.DateStart = *in.DateStart
 	}
 
-	// date_end (optional, แต่ validate ตอนท้าย)
-	if in.DateEnd != nil {
-		existing.DateEnd = in.DateEnd // อนุญาตให้แก้เป็น nil ได้ด้วย
+	if err := s.applyTiming(ctx, userID, next); err != nil {
+		return nil, err
 	}
 
-	// is_success
-	if in.IsSuccess != nil {
-		existing.IsSuccess = *in.IsSuccess
+	// completed_at ตาม is_success: เพิ่งเสร็จ = ตอนนี้, ยกเลิก = ล้าง, ไม่เปลี่ยน = ค่าเดิม
+	switch {
+	case next.IsSuccess && !prev.IsSuccess:
+		now := time.Now().UTC()
+		next.CompletedAt = &now
+	case !next.IsSuccess:
+		next.CompletedAt = nil
+	}
+	if err := validateDateRange(next.DateStart, next.DateEnd); err != nil {
+		return nil, err
+	}
+
+	// เช็คสมาชิกใหม่ทุกครั้งที่ assignee หรือ group เปลี่ยน (ย้าย group แล้วคนเดิมอาจไม่ได้อยู่ใน group ใหม่)
+	assigneeChanged := !sameID(prev.AssigneeID, next.AssigneeID)
+	if next.AssigneeID != nil && (assigneeChanged || next.TodoGroupID != prev.TodoGroupID) {
+		if err := s.checkAssignee(ctx, next.TodoGroupID, *next.AssigneeID); err != nil {
+			return nil, err
+		}
 	}
 
-	// todo_group_id (ถ้าส่งมา ห้ามเป็น 0)
-	if in.TodoGroupID != nil {
-		if *in.TodoGroupID == 0 {
-			return nil, ErrInvalidInput
+	// tag เป็นของคนที่แก้ (userID) เช็คเจ้าของก่อนบันทึก แล้วได้ชื่อ / สีมาด้วย
+	var tags *TagSet
+	if !sameTags(prev.Tags, next.Tags) {
+		owned, err := s.repo.OwnedTags(ctx, userID, tagIDs(next.Tags))
+		if err != nil {
+			return nil, err
 		}
-		existing.TodoGroupID = *in.TodoGroupID
+		next.Tags = owned
+		tags = &TagSet{UserID: userID, IDs: tagIDs(owned)}
 	}
 
-	// validate date range หลัง merge
-	if err := validateDateRange(existing.DateStart, existing.DateEnd); err != nil {
+	if err := s.applyRecurrence(ctx, userID, prev, next, rec, scope); err != nil {
 		return nil, err
 	}
 
-	if err := s.repo.Update(ctx, existing); err != nil {
+	if err := s.repo.Update(ctx, next, tags); err != nil {
 		return nil, err
 	}
 
-	return existing, nil
+	if assigneeChanged && next.AssigneeID != nil {
+		s.publishAssigned(ctx, next, userID)
+	}
+
+	// เพิ่งทำ occurrence นี้เสร็จ = สร้างอันถัดไป
+	if next.SeriesID != nil && next.IsSuccess && !prev.IsSuccess {
+		s.spawnNext(ctx, next)
+	}
+
+	return next, nil
+}
+
+// tagRefs แปลง tag_ids (ตัดตัวซ้ำ) เป็น TagRef ที่มีแค่ id ชื่อ / สีเติมจาก OwnedTags
+func tagRefs(ids []int64) ([]TagRef, error) {
+	seen := make(map[int64]bool, len(ids))
+	tags := []TagRef{}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			tags = append(tags, TagRef{ID: id})
+		}
+	}
+	if len(tags) > MaxTodoTags {
+		return nil, ErrTooManyTags
+	}
+	return tags, nil
+}
+
+func tagIDs(tags []TagRef) []int64 {
+	ids := make([]int64, len(tags))
+	for i, t := range tags {
+		ids[i] = t.ID
+	}
+	return ids
+}
+
+// sameTags เทียบเป็นเซ็ต (ไม่สนลำดับ)
+func sameTags(a, b []TagRef) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	ids := make(map[int64]bool, len(a))
+	for _, t := range a {
+		ids[t.ID] = true
+	}
+	for _, t := range b {
+		if !ids[t.ID] {
+			return false
+		}
+	}
+	return true
+}
+
+func sameID(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
 }
 
 // ===== Delete =====
 
-func (s *service) DeleteTodo(ctx context.Context, id, userID int64) error {
-	return s.repo.Delete(ctx, id, userID)
+func (s *service) DeleteTodo(ctx context.Context, id, userID int64, scope Scope) error {
+	t, err := s.getWritable(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if t.SeriesID != nil && scope == ScopeThis && !t.IsSuccess {
+		// ลบ occurrence ที่ยังไม่เสร็จ = ข้ามไปอันถัดไป series ยังเดินต่อ
+		s.spawnNext(ctx, t)
+	}
+
+	if err := s.repo.Delete(ctx, id, t.UserID); err != nil {
+		return err
+	}
+
+	// occurrence ที่เสร็จไปแล้วยังอยู่ (series_id กลายเป็น NULL)
+	if t.SeriesID != nil && scope == ScopeFuture {
+		return s.repo.DeleteSeries(ctx, *t.SeriesID)
+	}
+	return nil
+}
+
+// ===== Recurrence =====
+
+// todoTimezone timezone ที่ส่งมา (ของ todo ที่มีเวลา / series) ไม่ส่ง = timezone ของ user
+func (s *service) todoTimezone(ctx context.Context, userID int64, tz *string) (string, error) {
+	if tz != nil && *tz != "" {
+		if _, err := loadLocation(*tz); err != nil {
+			return "", err
+		}
+		return *tz, nil
+	}
+	return s.repo.UserTimezone(ctx, userID)
+}
+
+// startSeries สร้าง series ใหม่โดยให้ t เป็น occurrence แรก
+func (s *service) startSeries(ctx context.Context, t *Todo, rule, tz string) error {
+	series := seriesFrom(t, rule, tz)
+	if err := s.repo.CreateSeries(ctx, series); err != nil {
+		return err
+	}
+	date := series.DTStart
+	t.SeriesID = &series.ID
+	t.RRule = &series.RRule
+	t.OccurrenceDate = &date
+	return nil
+}
+
+// splitSeries แยก series ใหม่ให้ next ต่อจาก current ที่วันของ prev
+// COUNT ของ rule นับรวม occurrence ที่ current สร้างไปแล้ว ครบแล้ว = next กลายเป็น todo ธรรมดา
+func (s *service) splitSeries(ctx context.Context, prev, next *Todo, current *Series, rule, tz string) error {
+	slot := prev.DateStart
+	if prev.OccurrenceDate != nil {
+		slot = *prev.OccurrenceDate
+	}
+
+	rule, ok, err := current.remainingRule(rule, slot)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		next.SeriesID, next.RRule, next.OccurrenceDate = nil, nil, nil
+		return nil
+	}
+	return s.startSeries(ctx, next, rule, tz)
+}
+
+// applyRecurrence ตัดสินว่าการแก้นี้กระทบ series ยังไง (เรียกก่อนบันทึก next)
+//   - rrule / timezone เปลี่ยน หรือ scope=future แล้วเลื่อนวัน = แยกเป็น series ใหม่เริ่มที่ occurrence นี้
+//     occurrence ที่ผ่านไปแล้วยังผูกกับ series เดิม (COUNT ลดตามจำนวนที่ series เดิมสร้างไปแล้ว)
+//   - rrule = "" / null = เลิกเกิดซ้ำ todo นี้กลายเป็น todo ธรรมดา
+//   - scope=future อย่างอื่น = แก้ต้นแบบของ series เดิม
+//   - scope=this = แก้เฉพาะแถวนี้ occurrence_date เดิมยังจองวันไว้ series ไม่สร้างวันนั้นซ้ำ
+func (s *service) applyRecurrence(ctx context.Context, userID int64, prev, next *Todo, rec recurrence, scope Scope) error {
+	var current *Series
+	if prev.SeriesID != nil {
+		var err error
+		current, err = s.repo.GetSeries(ctx, *prev.SeriesID)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+
+	if rec.set {
+		rule, err := normalizeRRule(rec.rule)
+		if err != nil {
+			return err
+		}
+		if rule == "" {
+			next.SeriesID, next.RRule, next.OccurrenceDate = nil, nil, nil
+			return nil
+		}
+
+		var tz string
+		switch {
+		case next.StartAt != nil:
+			// todo ที่มีเวลาใช้ timezone เดียวกับ series (applyTiming เลือกไว้แล้ว)
+			tz = *next.Timezone
+		case rec.timezone != nil && *rec.timezone != "":
+			tz = *rec.timezone
+			if _, err := loadLocation(tz); err != nil {
+				return err
+			}
+		case current != nil:
+			tz = current.Timezone
+		default:
+			if tz, err = s.repo.UserTimezone(ctx, userID); err != nil {
+				return err
+			}
+		}
+
+		if current == nil {
+			return s.startSeries(ctx, next, rule, tz)
+		}
+		if rule != current.RRule || tz != current.Timezone {
+			return s.splitSeries(ctx, prev, next, current, rule, tz)
+		}
+	}
+
+	if current == nil || scope != ScopeFuture {
+		return nil
+	}
+
+	if !next.DateStart.Equal(prev.DateStart.Time) {
+		return s.splitSeries(ctx, prev, next, current, current.RRule, current.Timezone)
+	}
+	current.copyTemplate(next)
+	return s.repo.UpdateSeries(ctx, current)
+}
+
+// spawnNext สร้าง occurrence ถัดจาก t ไม่ผ่านก็แค่ log ไว้ (todo ที่บันทึกแล้วไม่ควรพังเพราะเรื่องนี้)
+func (s *service) spawnNext(ctx context.Context, t *Todo) {
+	if err := s.createNextOccurrence(ctx, t); err != nil {
+		slog.Error("failed to create next occurrence", "todo_id", t.ID, "series_id", *t.SeriesID, "error", err)
+	}
+}
+
+func (s *service) createNextOccurrence(ctx context.Context, t *Todo) error {
+	series, err := s.repo.GetSeries(ctx, *t.SeriesID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	// นับต่อจากวันที่ occurrence นี้จองไว้ (ย้ายวันเฉพาะอันนี้ไม่ทำให้ series เลื่อน)
+	slot := t.DateStart
+	if t.OccurrenceDate != nil {
+		slot = *t.OccurrenceDate
+	}
+	date, ok, err := series.nextAfter(slot)
+	if err != nil || !ok {
+		return err
+	}
+
+	occ, err := series.occurrence(t.UserID, date)
+	if err != nil {
+		return err
+	}
+	occ.Priority = t.Priority
+	occ.Important = t.Important
+	occ.AutoComplete = t.AutoComplete
+	// คนรับผิดชอบออกจาก group ไปแล้ว = occurrence ใหม่ไม่มีคนรับผิดชอบ
+	if occ.AssigneeID != nil {
+		if err := s.checkAssignee(ctx, occ.TodoGroupID, *occ.AssigneeID); err != nil {
+			if !errors.Is(err, ErrAssigneeNotMember) {
+				return err
+			}
+			occ.AssigneeID = nil
+		}
+	}
+
+	created, err := s.repo.CreateOccurrence(ctx, occ)
+	if err != nil || !created {
+		return err
+	}
+
+	// occurrence ใหม่ได้ checklist ชุดเดิม (ยังไม่ติ๊ก) และ tag เดิมของทุกคน
+	if t.Checklist.Total > 0 {
+		if err := s.repo.CopyChecklist(ctx, t.ID, occ.ID); err != nil {
+			return err
+		}
+	}
+	return s.repo.CopyTags(ctx, t.ID, occ.ID)
+}
+
+// ===== Move =====
+
+// MoveTodo ลำดับของ todo อยู่ใน list ของเจ้าของ group ดังนั้น before/after ต้องเป็น todo ของเจ้าของคนเดียวกัน
+func (s *service) MoveTodo(ctx context.Context, id, userID int64, in MoveTodoInput) (*Todo, error) {
+	t, err := s.getWritable(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.Move(ctx, id, t.UserID, in.BeforeID, in.AfterID)
+}
+
+// ===== Checklist =====
+
+func (s *service) ListChecklist(ctx context.Context, todoID, userID int64) ([]ChecklistItem, error) {
+	if _, err := s.getVisible(ctx, todoID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListChecklist(ctx, todoID)
+}
+
+func (s *service) AddChecklistItem(ctx context.Context, todoID, userID int64, in CreateChecklistItemInput) (*ChecklistItem, error) {
+	t, err := s.getWritable(ctx, todoID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	title := strings.TrimSpace(in.Title)
+	if title == "" {
+		return nil, fmt.Errorf("%w: title is required", ErrInvalidInput)
+	}
+
+	item := &ChecklistItem{TodoID: t.ID, Title: title, IsDone: in.IsDone}
+	if err := s.repo.CreateChecklistItem(ctx, item); err != nil {
+		return nil, err
+	}
+
+	s.autoComplete(ctx, userID, t.ID)
+	return item, nil
+}
+
+func (s *service) PatchChecklistItem(ctx context.Context, todoID, itemID, userID int64, in PatchChecklistItemInput) (*ChecklistItem, error) {
+	if _, err := s.getWritable(ctx, todoID, userID); err != nil {
+		return nil, err
+	}
+
+	item, err := s.repo.GetChecklistItem(ctx, itemID, todoID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Title.Set {
+		title := strings.TrimSpace(in.Title.Value)
+		if !in.Title.Valid || title == "" {
+			return nil, fmt.Errorf("%w: title cannot be empty", ErrInvalidInput)
+		}
+		item.Title = title
+	}
+
+	if in.IsDone.Set {
+		if !in.IsDone.Valid {
+			return nil, fmt.Errorf("%w: is_done cannot be null", ErrInvalidInput)
+		}
+		item.IsDone = in.IsDone.Value
+	}
+
+	if err := s.repo.UpdateChecklistItem(ctx, item); err != nil {
+		return nil, err
+	}
+
+	s.autoComplete(ctx, userID, todoID)
+	return item, nil
+}
+
+// DeleteChecklistItem ลบข้อที่ยังไม่ติ๊กออกอาจทำให้ที่เหลือครบพอดี จึงเช็ค auto_complete ด้วย
+func (s *service) DeleteChecklistItem(ctx context.Context, todoID, itemID, userID int64) error {
+	if _, err := s.getWritable(ctx, todoID, userID); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteChecklistItem(ctx, itemID, todoID); err != nil {
+		return err
+	}
+
+	s.autoComplete(ctx, userID, todoID)
+	return nil
+}
+
+func (s *service) MoveChecklistItem(ctx context.Context, todoID, itemID, userID int64, in MoveTodoInput) (*ChecklistItem, error) {
+	if _, err := s.getWritable(ctx, todoID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.MoveChecklistItem(ctx, itemID, todoID, in.BeforeID, in.AfterID)
+}
+
+// autoComplete ปิด todo ที่ตั้ง auto_complete ไว้เมื่อ checklist ครบทุกข้อ (checklist ว่างไม่นับ)
+// ผ่าน saveTodo เหมือนกดเสร็จเอง (todo ที่เกิดซ้ำได้ occurrence ถัดไปด้วย)
+// ไม่ผ่านก็แค่ log ไว้ ข้อใน checklist บันทึกไปแล้ว
+func (s *service) autoComplete(ctx context.Context, userID, todoID int64) {
+	t, err := s.repo.GetByID(ctx, todoID, userID)
+	if err != nil {
+		slog.Error("failed to auto-complete todo", "todo_id", todoID, "error", err)
+		return
+	}
+	if !t.AutoComplete || t.IsSuccess || t.Checklist.Total == 0 || t.Checklist.Done < t.Checklist.Total {
+		return
+	}
+
+	next := *t
+	next.IsSuccess = true
+	if _, err := s.saveTodo(ctx, userID, t, &next, recurrence{}, ScopeThis); err != nil {
+		slog.Error("failed to auto-complete todo", "todo_id", todoID, "error", err)
+	}
 }
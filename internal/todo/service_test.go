@@ -0,0 +1,146 @@
+package todo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Nasaee/go-todo-backend/internal/event"
+	"github.com/Nasaee/go-todo-backend/internal/todogroup"
+	"github.com/Nasaee/go-todo-backend/pkg/civil"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testPool ต่อ Postgres จริงจาก TEST_DATABASE_URL (ไม่ตั้งไว้ = ข้าม)
+// สร้าง schema ใหม่ให้ทุก test แล้วรัน migration ฝั่ง Up ทั้งหมด ลบทิ้งตอนจบ
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	ctx := context.Background()
+
+	admin, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = admin.Exec(ctx, "DROP SCHEMA "+schema+" CASCADE")
+		_ = admin.Close(ctx)
+	})
+
+	cfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		t.Fatalf("parse dsn: %v", err)
+	}
+	// extension (pg_trgm) อยู่ที่ public ได้
+	cfg.ConnConfig.RuntimeParams["search_path"] = schema + ", public"
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		t.Fatalf("pool: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	files, err := filepath.Glob("../db/postgres/migrations/*.sql")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("migrations not found: %v", err)
+	}
+	sort.Strings(files)
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatalf("read %s: %v", f, err)
+		}
+		up, _, _ := strings.Cut(string(b), "-- +goose Down")
+		// ไม่มี argument = simple protocol รันหลาย statement ในครั้งเดียวได้
+		if _, err := pool.Exec(ctx, up); err != nil {
+			t.Fatalf("migrate %s: %v", filepath.Base(f), err)
+		}
+	}
+
+	return pool
+}
+
+func insertUser(t *testing.T, pool *pgxpool.Pool, email string) int64 {
+	t.Helper()
+
+	var id int64
+	err := pool.QueryRow(context.Background(), `
+		INSERT INTO users (first_name, last_name, email, password)
+		VALUES ('Test', 'User', $1, 'x')
+		RETURNING id
+	`, email).Scan(&id)
+	if err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	return id
+}
+
+// สมาชิก team workspace เข้าถึงทุก group ใน workspace ผ่าน todo_group_access โดยไม่มีแถวใน todo_group_members
+// ต้อง assign ได้ และออกจาก workspace แล้ว assignment หลุด
+func TestAssignWorkspaceOnlyMember(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+
+	ownerID := insertUser(t, pool, "owner@example.com")
+	teammateID := insertUser(t, pool, "teammate@example.com")
+
+	var workspaceID int64
+	if err := pool.QueryRow(ctx, `
+		INSERT INTO workspaces (name, kind) VALUES ('Team', 'team') RETURNING id
+	`).Scan(&workspaceID); err != nil {
+		t.Fatalf("insert workspace: %v", err)
+	}
+	if _, err := pool.Exec(ctx, `
+		INSERT INTO workspace_members (workspace_id, user_id, role)
+		VALUES ($1, $2, 'owner'), ($1, $3, 'member')
+	`, workspaceID, ownerID, teammateID); err != nil {
+		t.Fatalf("insert workspace members: %v", err)
+	}
+
+	groupRepo := todogroup.NewRepository(pool)
+	g := &todogroup.TodoGroup{Name: "Sprint", Color: "#1c7ed6", UserID: ownerID, WorkspaceID: workspaceID}
+	if err := groupRepo.Create(ctx, g); err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+
+	svc := NewService(NewRepository(pool), groupRepo, event.LogPublisher{})
+	created, err := svc.CreateTodo(ctx, ownerID, CreateTodoInput{
+		Title:       "Review PR",
+		DateStart:   civil.DateOf(time.Now()),
+		TodoGroupID: g.ID,
+		AssigneeID:  &teammateID,
+	})
+	if err != nil {
+		t.Fatalf("assign workspace-only member: %v", err)
+	}
+	if created.AssigneeID == nil || *created.AssigneeID != teammateID {
+		t.Fatalf("assignee = %v, want %d", created.AssigneeID, teammateID)
+	}
+
+	if _, err := pool.Exec(ctx, `
+		DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2
+	`, workspaceID, teammateID); err != nil {
+		t.Fatalf("remove workspace member: %v", err)
+	}
+
+	got, err := svc.GetTodo(ctx, created.ID, ownerID)
+	if err != nil {
+		t.Fatalf("get todo: %v", err)
+	}
+	if got.AssigneeID != nil {
+		t.Fatalf("assignee = %d after leaving the workspace, want none", *got.AssigneeID)
+	}
+}
@@ -0,0 +1,43 @@
+package todo
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Nasaee/go-todo-backend/pkg/civil"
+)
+
+var (
+	ErrInvalidTimeRange = errors.New("end_at cannot be before start_at")
+	ErrEndWithoutStart  = errors.New("end_at requires start_at (all-day todos use date_end)")
+	ErrTimedDate        = errors.New("dates of a timed todo follow start_at / end_at (send start_at, or start_at: null to make it all-day)")
+)
+
+// setTimedDates เซ็ต date_start / date_end ของ todo ที่มีเวลาเป็นวันของ start_at / end_at ตาม loc
+// (todo ทั้งวันกับที่มีเวลาจึง sort / filter ด้วย date_start ร่วมกันได้)
+func setTimedDates(t *Todo, loc *time.Location) {
+	t.AllDay = false
+	t.DateStart = civil.DateOf(t.StartAt.In(loc))
+	t.DateEnd = nil
+	if t.EndAt != nil {
+		end := civil.DateOf(t.EndAt.In(loc))
+		t.DateEnd = &end
+	}
+}
+
+// DayWindow ช่วงวัน [From, To) ตามปฏิทินของ Loc (timezone ของ user ที่ดู)
+// todo ทั้งวันเทียบด้วย date_start..date_end, todo ที่มีเวลาเทียบ start_at..end_at กับเที่ยงคืนของแต่ละฝั่งใน Loc
+type DayWindow struct {
+	From civil.Date
+	To   civil.Date
+	Loc  *time.Location
+}
+
+func daysFrom(from civil.Date, days int, loc *time.Location) DayWindow {
+	return DayWindow{From: from, To: from.AddDays(days), Loc: loc}
+}
+
+// weekStart วันจันทร์ของสัปดาห์ที่มี d (ตรงกับ date_trunc('week') ของ Postgres)
+func weekStart(d civil.Date) civil.Date {
+	return d.AddDays(-((int(d.Weekday()) + 6) % 7))
+}
@@ -0,0 +1,90 @@
+package todogroup
+
+import (
+	"time"
+
+	"github.com/Nasaee/go-todo-backend/pkg/civil"
+)
+
+// TodoSeed ข้อมูล todo ที่ใช้สร้างซ้ำ (clone group / สร้างจาก template)
+type TodoSeed struct {
+	Title       string
+	Description *string
+	DateStart   civil.Date
+	DateEnd     *civil.Date
+
+	// todo ที่มีเวลา (StartAt = nil คือทั้งวัน) date_start / date_end = วันของ start_at / end_at ตาม Timezone
+	StartAt  *time.Time
+	EndAt    *time.Time
+	Timezone *string
+
+	Priority  int16 // ค่าเดียวกับ todo.Priority (import todo ตรง ๆ ไม่ได้ วน import)
+	Important bool
+}
+
+// ใช้กับ POST /todo-groups/{id}/clone
+type CloneInput struct {
+	Name *string `json:"name"` // ไม่ส่ง = "<ชื่อเดิม> (copy)"
+	// วันเริ่มของ todo ที่เร็วที่สุดใน group ใหม่ todo อื่นเลื่อนตามระยะห่างเดิม (ไม่ส่ง = วันเดิม)
+	AnchorDate *civil.Date `json:"anchor_date"`
+}
+
+// EarliestStart วันเริ่มที่เร็วที่สุดใน seeds (ใช้เป็นจุดอ้างอิงตอนเลื่อนวัน)
+func EarliestStart(seeds []TodoSeed) (civil.Date, bool) {
+	if len(seeds) == 0 {
+		return civil.Date{}, false
+	}
+
+	earliest := seeds[0].DateStart
+	for _, t := range seeds[1:] {
+		if t.DateStart.Before(earliest.Time) {
+			earliest = t.DateStart
+		}
+	}
+	return earliest, true
+}
+
+// SeedLocation timezone ของ todo ที่มีเวลา (โหลดไม่ได้ใช้ UTC timezone ผ่านการ validate ตอนบันทึกมาแล้ว)
+func SeedLocation(t *TodoSeed) *time.Location {
+	if t.Timezone == nil {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(*t.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// ShiftDates เลื่อนวันของทุก todo ให้ todo ที่เริ่มเร็วที่สุดตรงกับ anchor (ระยะห่างระหว่างกันเท่าเดิม)
+// todo ที่มีเวลายังเริ่มเวลาเดิมตาม timezone ของมัน (ข้าม DST แล้วไม่เพี้ยน)
+func ShiftDates(seeds []TodoSeed, anchor civil.Date) {
+	earliest, ok := EarliestStart(seeds)
+	if !ok {
+		return
+	}
+
+	days := anchor.DaysSince(earliest)
+	for i := range seeds {
+		t := &seeds[i]
+		t.DateStart = t.DateStart.AddDays(days)
+		if t.DateEnd != nil {
+			end := t.DateEnd.AddDays(days)
+			t.DateEnd = &end
+		}
+
+		if t.StartAt == nil {
+			continue
+		}
+		loc := SeedLocation(t)
+		start := t.StartAt.In(loc).AddDate(0, 0, days)
+		if t.EndAt != nil {
+			end := start.Add(t.EndAt.Sub(*t.StartAt))
+			t.EndAt = &end
+			endDate := civil.DateOf(end.In(loc))
+			t.DateEnd = &endDate
+		}
+		t.StartAt = &start
+		t.DateStart = civil.DateOf(start)
+	}
+}
@@ -0,0 +1,63 @@
+package todogroup
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var ErrInvalidColor = errors.New("color must be a hex value (#RGB or #RRGGBB) or a palette key")
+
+// DefaultColor ตรงกับ default ของ column todo_groups.color
+const DefaultColor = "#1c7ed6"
+
+type PaletteColor struct {
+	Key string `json:"key"`
+	Hex string `json:"hex"`
+}
+
+// Palette คือสีที่ UI เสนอให้เลือก (เรียงตามที่จะแสดง) migration 00007 มีตาราง key -> hex ชุดเดียวกันไว้แปลงข้อมูลเก่า
+var Palette = []PaletteColor{
+	{Key: "blue", Hex: "#1c7ed6"},
+	{Key: "cyan", Hex: "#1098ad"},
+	{Key: "teal", Hex: "#0ca678"},
+	{Key: "green", Hex: "#37b24d"},
+	{Key: "lime", Hex: "#74b816"},
+	{Key: "yellow", Hex: "#f59f00"},
+	{Key: "orange", Hex: "#f76707"},
+	{Key: "red", Hex: "#f03e3e"},
+	{Key: "pink", Hex: "#d6336c"},
+	{Key: "grape", Hex: "#ae3ec9"},
+	{Key: "violet", Hex: "#7048e8"},
+	{Key: "indigo", Hex: "#4263eb"},
+	{Key: "gray", Hex: "#495057"},
+}
+
+var hexColorRe = regexp.MustCompile(`^#([0-9a-f]{3}|[0-9a-f]{6})$`)
+
+// NormalizeColor แปลงสีที่รับมาให้อยู่ในรูปเดียวกันก่อนเก็บลง DB: "#rrggbb" ตัวพิมพ์เล็ก
+//   - "" -> DefaultColor
+//   - palette key ("blue") -> hex ของ key นั้น
+//   - "#ABC" -> "#aabbcc"
+func NormalizeColor(color string) (string, error) {
+	c := strings.ToLower(strings.TrimSpace(color))
+	if c == "" {
+		return DefaultColor, nil
+	}
+
+	for _, p := range Palette {
+		if c == p.Key {
+			return p.Hex, nil
+		}
+	}
+
+	if !hexColorRe.MatchString(c) {
+		return "", ErrInvalidColor
+	}
+
+	if len(c) == 4 {
+		c = "#" + strings.Repeat(c[1:2], 2) + strings.Repeat(c[2:3], 2) + strings.Repeat(c[3:4], 2)
+	}
+
+	return c, nil
+}
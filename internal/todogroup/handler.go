@@ -2,11 +2,15 @@ package todogroup
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/Nasaee/go-todo-backend/internal/auth"
 	"github.com/Nasaee/go-todo-backend/pkg/utils"
+	"github.com/go-chi/chi/v5"
 )
 
 type Handler struct {
@@ -43,6 +47,18 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 			})
 			return
 		}
+		if err == ErrInvalidColor {
+			utils.WriteJSON(w, http.StatusBadRequest, map[string]string{
+				"message": err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, ErrInvalidParent) || errors.Is(err, ErrParentCycle) || errors.Is(err, ErrTooDeep) {
+			utils.WriteJSON(w, http.StatusUnprocessableEntity, map[string]string{
+				"message": err.Error(),
+			})
+			return
+		}
 
 		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{
 			"message": "could not create todo group",
@@ -60,7 +76,27 @@ func (h *Handler) GetAll(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	groups, err := h.svc.GetAll(r.Context(), userID)
+	opts := ListOptions{
+		IncludeArchived: utils.QueryFlags(r, "include")["archived"],
+		WithStats:       utils.QueryFlags(r, "with")["stats"],
+	}
+
+	// ?view=tree = คืนเป็นต้นไม้ (children ซ้อนกัน) แทน list แบน ๆ
+	if r.URL.Query().Get("view") == "tree" {
+		tree, err := h.svc.GetTree(r.Context(), userID, opts)
+		if err != nil {
+			fmt.Println(err)
+			utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "could not fetch todo groups",
+			})
+			return
+		}
+
+		utils.WriteJSON(w, http.StatusOK, tree)
+		return
+	}
+
+	groups, err := h.svc.GetAll(r.Context(), userID, opts)
 	if err != nil {
 		fmt.Println(err)
 		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{
@@ -71,3 +107,302 @@ func (h *Handler) GetAll(w http.ResponseWriter, r *http.Request) {
 
 	utils.WriteJSON(w, http.StatusOK, groups)
 }
+
+// GET /todo-groups/palette
+func (h *Handler) Palette(w http.ResponseWriter, r *http.Request) {
+	utils.WriteJSON(w, http.StatusOK, map[string]any{
+		"default": DefaultColor,
+		"colors":  Palette,
+	})
+}
+
+// GET /todo-groups/{id}
+func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id, ok := groupIDFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	g, err := h.svc.Get(r.Context(), id, userID)
+	if err != nil {
+		writeServiceError(w, err, "could not fetch todo group")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, g)
+}
+
+// PUT /todo-groups/{id}
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id, ok := groupIDFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var input UpdateTodoGroupInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{
+			"message": "invalid body",
+		})
+		return
+	}
+
+	g, err := h.svc.Update(r.Context(), id, userID, input)
+	if err != nil {
+		writeServiceError(w, err, "could not update todo group")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, g)
+}
+
+// PATCH /todo-groups/{id}
+func (h *Handler) Patch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id, ok := groupIDFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var input PatchTodoGroupInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{
+			"message": "invalid body",
+		})
+		return
+	}
+
+	g, err := h.svc.Patch(r.Context(), id, userID, input)
+	if err != nil {
+		writeServiceError(w, err, "could not update todo group")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, g)
+}
+
+// DELETE /todo-groups/{id}
+// ?move_todos_to={groupID} = ย้าย todos ไป group อื่นก่อนลบ (ไม่ส่ง = ลบ todos ทิ้งด้วย)
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id, ok := groupIDFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var moveTodosTo *int64
+	if v := r.URL.Query().Get("move_todos_to"); v != "" {
+		target, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			utils.WriteJSON(w, http.StatusBadRequest, map[string]string{
+				"message": "invalid move_todos_to",
+			})
+			return
+		}
+		moveTodosTo = &target
+	}
+
+	if err := h.svc.Delete(r.Context(), id, userID, moveTodosTo); err != nil {
+		writeServiceError(w, err, "could not delete todo group")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+// POST /todo-groups/{id}/move
+func (h *Handler) Move(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id, ok := groupIDFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var input MoveTodoGroupInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{
+			"message": "invalid body",
+		})
+		return
+	}
+
+	g, err := h.svc.Move(r.Context(), id, userID, input)
+	if err != nil {
+		writeServiceError(w, err, "could not move todo group")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, g)
+}
+
+// POST /todo-groups/{id}/clone (body ไม่ส่งก็ได้)
+func (h *Handler) Clone(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id, ok := groupIDFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var input CloneInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{
+			"message": "invalid body",
+		})
+		return
+	}
+
+	g, err := h.svc.Clone(r.Context(), id, userID, input)
+	if err != nil {
+		writeServiceError(w, err, "could not clone todo group")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, g)
+}
+
+// POST /todo-groups/{id}/archive
+func (h *Handler) Archive(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id, ok := groupIDFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	g, err := h.svc.Archive(r.Context(), id, userID)
+	if err != nil {
+		writeServiceError(w, err, "could not archive todo group")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, g)
+}
+
+// POST /todo-groups/{id}/unarchive
+func (h *Handler) Unarchive(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id, ok := groupIDFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	g, err := h.svc.Unarchive(r.Context(), id, userID)
+	if err != nil {
+		writeServiceError(w, err, "could not unarchive todo group")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, g)
+}
+
+func groupIDFromURL(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	return int64FromURL(w, r, "id")
+}
+
+func int64FromURL(w http.ResponseWriter, r *http.Request, param string) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, param), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{
+			"message": "invalid " + param,
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// map error จาก service -> HTTP status (fallback = 500 + message ที่ส่งมา)
+func writeServiceError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		utils.WriteJSON(w, http.StatusNotFound, map[string]string{
+			"message": "todo group not found",
+		})
+	case errors.Is(err, ErrEmptyName):
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{
+			"message": "name is required",
+		})
+	case errors.Is(err, ErrInvalidColor):
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	case errors.Is(err, ErrInboxUndeletable), errors.Is(err, ErrInboxArchive):
+		utils.WriteJSON(w, http.StatusConflict, map[string]string{
+			"message": err.Error(),
+		})
+	case errors.Is(err, ErrInvalidMove):
+		utils.WriteJSON(w, http.StatusUnprocessableEntity, map[string]string{
+			"message": err.Error(),
+		})
+	case errors.Is(err, ErrInvalidMoveTarget):
+		utils.WriteJSON(w, http.StatusUnprocessableEntity, map[string]string{
+			"message": err.Error(),
+		})
+	case errors.Is(err, ErrInvalidParent), errors.Is(err, ErrParentCycle), errors.Is(err, ErrTooDeep):
+		utils.WriteJSON(w, http.StatusUnprocessableEntity, map[string]string{
+			"message": err.Error(),
+		})
+	case errors.Is(err, ErrForbidden):
+		utils.WriteJSON(w, http.StatusForbidden, map[string]string{
+			"message": err.Error(),
+		})
+	case errors.Is(err, ErrInvitationNotFound), errors.Is(err, ErrMemberNotFound):
+		utils.WriteJSON(w, http.StatusNotFound, map[string]string{
+			"message": err.Error(),
+		})
+	case errors.Is(err, ErrInvalidRole), errors.Is(err, ErrInvalidEmail):
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	case errors.Is(err, ErrAlreadyMember), errors.Is(err, ErrAlreadyInvited),
+		errors.Is(err, ErrInboxShare), errors.Is(err, ErrOwnerMember):
+		utils.WriteJSON(w, http.StatusConflict, map[string]string{
+			"message": err.Error(),
+		})
+	default:
+		fmt.Println(err)
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{
+			"message": fallback,
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package todogroup
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrForbidden          = errors.New("you do not have permission to do this in this todo group")
+	ErrInvalidRole        = errors.New("role must be editor or viewer")
+	ErrInvalidEmail       = errors.New("a valid email is required")
+	ErrAlreadyMember      = errors.New("user is already a member of this todo group")
+	ErrAlreadyInvited     = errors.New("this email already has a pending invitation to this todo group")
+	ErrInvitationNotFound = errors.New("invitation not found")
+	ErrMemberNotFound     = errors.New("member not found")
+	ErrInboxShare         = errors.New("inbox group cannot be shared")
+	ErrOwnerMember        = errors.New("the owner cannot be removed or change role")
+)
+
+// Role สิทธิ์ของสมาชิกใน group
+//   - owner  = เจ้าของ จัดการ group / สมาชิก / คำเชิญได้ทั้งหมด
+//   - editor = เพิ่ม แก้ ลบ todos ใน group ได้
+//   - viewer = ดูได้อย่างเดียว
+type Role string
+
+const (
+	RoleOwner  Role = "owner"
+	RoleEditor Role = "editor"
+	RoleViewer Role = "viewer"
+)
+
+func (r Role) level() int {
+	switch r {
+	case RoleOwner:
+		return 3
+	case RoleEditor:
+		return 2
+	case RoleViewer:
+		return 1
+	}
+	return 0
+}
+
+// AtLeast = role นี้มีสิทธิ์เท่ากับหรือมากกว่า min ("" = ไม่ได้เป็นสมาชิก ไม่ผ่านเสมอ)
+func (r Role) AtLeast(min Role) bool {
+	return r.level() > 0 && r.level() >= min.level()
+}
+
+// parseMemberRole รับเฉพาะ role ที่ให้คนอื่นได้ (owner โอนให้กันไม่ได้)
+func parseMemberRole(s string) (Role, error) {
+	switch r := Role(strings.ToLower(strings.TrimSpace(s))); r {
+	case RoleEditor, RoleViewer:
+		return r, nil
+	}
+	return "", ErrInvalidRole
+}
+
+type Member struct {
+	UserID    int64     `json:"user_id"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Email     string    `json:"email"`
+	Role      Role      `json:"role"`
+	JoinedAt  time.Time `json:"joined_at"`
+}
+
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+	InvitationRevoked  = "revoked"
+)
+
+type Invitation struct {
+	ID          int64      `json:"id"`
+	TodoGroupID int64      `json:"todo_group_id"`
+	GroupName   string     `json:"group_name"`
+	Email       string     `json:"email"`
+	Role        Role       `json:"role"`
+	InvitedBy   int64      `json:"invited_by"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+}
+
+// ใช้กับ POST /todo-groups/{id}/invitations
+type InviteInput struct {
+	Email string `json:"email"`
+	Role  string `json:"role"` // editor / viewer (ไม่ส่ง = viewer)
+}
+
+// ใช้กับ POST /invitations/{invitationID}/accept และ /decline
+type RespondInvitationInput struct {
+	Token string `json:"token"` // token ที่ส่งไปทาง email ตอนเชิญ
+}
+
+// ใช้กับ PATCH /todo-groups/{id}/members/{userID}
+type UpdateMemberInput struct {
+	Role string `json:"role"`
+}
@@ -0,0 +1,225 @@
+package todogroup
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Nasaee/go-todo-backend/internal/auth"
+	"github.com/Nasaee/go-todo-backend/pkg/utils"
+)
+
+type MemberHandler struct {
+	svc MemberService
+}
+
+func NewMemberHandler(svc MemberService) *MemberHandler {
+	return &MemberHandler{svc: svc}
+}
+
+// GET /todo-groups/{id}/members
+func (h *MemberHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	groupID, ok := groupIDFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	members, err := h.svc.ListMembers(r.Context(), groupID, userID)
+	if err != nil {
+		writeServiceError(w, err, "could not fetch members")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, members)
+}
+
+// PATCH /todo-groups/{id}/members/{userID}
+func (h *MemberHandler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	groupID, ok := groupIDFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	memberID, ok := int64FromURL(w, r, "userID")
+	if !ok {
+		return
+	}
+
+	var input UpdateMemberInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{
+			"message": "invalid body",
+		})
+		return
+	}
+
+	m, err := h.svc.UpdateMember(r.Context(), groupID, userID, memberID, input)
+	if err != nil {
+		writeServiceError(w, err, "could not update member")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, m)
+}
+
+// DELETE /todo-groups/{id}/members/{userID} (ใส่ id ตัวเอง = ออกจาก group)
+func (h *MemberHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	groupID, ok := groupIDFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	memberID, ok := int64FromURL(w, r, "userID")
+	if !ok {
+		return
+	}
+
+	if err := h.svc.RemoveMember(r.Context(), groupID, userID, memberID); err != nil {
+		writeServiceError(w, err, "could not remove member")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+// POST /todo-groups/{id}/invitations
+func (h *MemberHandler) Invite(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	groupID, ok := groupIDFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var input InviteInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{
+			"message": "invalid body",
+		})
+		return
+	}
+
+	inv, err := h.svc.Invite(r.Context(), groupID, userID, input)
+	if err != nil {
+		writeServiceError(w, err, "could not create invitation")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, inv)
+}
+
+// GET /todo-groups/{id}/invitations (เฉพาะที่ยัง pending)
+func (h *MemberHandler) ListGroupInvitations(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	groupID, ok := groupIDFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	invitations, err := h.svc.ListGroupInvitations(r.Context(), groupID, userID)
+	if err != nil {
+		writeServiceError(w, err, "could not fetch invitations")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, invitations)
+}
+
+// DELETE /todo-groups/{id}/invitations/{invitationID}
+func (h *MemberHandler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	groupID, ok := groupIDFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	invitationID, ok := int64FromURL(w, r, "invitationID")
+	if !ok {
+		return
+	}
+
+	if err := h.svc.RevokeInvitation(r.Context(), groupID, invitationID, userID); err != nil {
+		writeServiceError(w, err, "could not revoke invitation")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+// POST /invitations/{invitationID}/accept
+func (h *MemberHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	h.respondInvitation(w, r, true)
+}
+
+// POST /invitations/{invitationID}/decline
+func (h *MemberHandler) DeclineInvitation(w http.ResponseWriter, r *http.Request) {
+	h.respondInvitation(w, r, false)
+}
+
+func (h *MemberHandler) respondInvitation(w http.ResponseWriter, r *http.Request, accept bool) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	invitationID, ok := int64FromURL(w, r, "invitationID")
+	if !ok {
+		return
+	}
+
+	var input RespondInvitationInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{
+			"message": "invalid body",
+		})
+		return
+	}
+
+	var (
+		inv *Invitation
+		err error
+	)
+	if accept {
+		inv, err = h.svc.AcceptInvitation(r.Context(), invitationID, userID, input)
+	} else {
+		inv, err = h.svc.DeclineInvitation(r.Context(), invitationID, userID, input)
+	}
+	if err != nil {
+		writeServiceError(w, err, "could not respond to invitation")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, inv)
+}
@@ -0,0 +1,268 @@
+package todogroup
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// column ของคำเชิญ (ลำดับต้องตรงกับ invitationDest)
+const invitationColumns = `i.id, i.todo_group_id, g.name, i.email, i.role, i.invited_by, i.status, i.created_at, i.responded_at`
+
+func invitationDest(inv *Invitation) []any {
+	return []any{
+		&inv.ID,
+		&inv.TodoGroupID,
+		&inv.GroupName,
+		&inv.Email,
+		&inv.Role,
+		&inv.InvitedBy,
+		&inv.Status,
+		&inv.CreatedAt,
+		&inv.RespondedAt,
+	}
+}
+
+type MemberRepository interface {
+	ListMembers(ctx context.Context, groupID int64) ([]Member, error)
+	UpdateMemberRole(ctx context.Context, groupID, userID int64, role Role) (*Member, error)
+	RemoveMember(ctx context.Context, groupID, userID int64) error
+
+	// CreateInvitation เก็บแค่ hash ของ token ตัวจริงส่งไปทาง email เท่านั้น
+	CreateInvitation(ctx context.Context, inv *Invitation, tokenHash []byte) error
+	ListGroupInvitations(ctx context.Context, groupID int64) ([]Invitation, error)
+	RevokeInvitation(ctx context.Context, groupID, invitationID int64) error
+	// RespondInvitation ตอบคำเชิญที่ token ตรง (ถือ token = เข้าถึง email ที่ถูกเชิญได้)
+	// accept = เพิ่ม userID เป็นสมาชิกใน transaction เดียวกัน
+	RespondInvitation(ctx context.Context, invitationID int64, tokenHash []byte, userID int64, accept bool) (*Invitation, error)
+}
+
+type memberRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewMemberRepository(db *pgxpool.Pool) MemberRepository {
+	return &memberRepo{db: db}
+}
+
+func (r *memberRepo) ListMembers(ctx context.Context, groupID int64) ([]Member, error) {
+	query := `
+		SELECT u.id, u.first_name, u.last_name, u.email, m.role, m.created_at
+		FROM todo_group_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.todo_group_id = $1
+		ORDER BY m.role = 'owner' DESC, m.created_at, u.id
+	`
+
+	rows, err := r.db.Query(ctx, query, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []Member{}
+	for rows.Next() {
+		var m Member
+		if err := rows.Scan(&m.UserID, &m.FirstName, &m.LastName, &m.Email, &m.Role, &m.JoinedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return members, nil
+}
+
+// UpdateMemberRole แก้ role ของสมาชิกที่ไม่ใช่ owner
+func (r *memberRepo) UpdateMemberRole(ctx context.Context, groupID, userID int64, role Role) (*Member, error) {
+	query := `
+		WITH updated AS (
+			UPDATE todo_group_members
+			SET role = $3
+			WHERE todo_group_id = $1 AND user_id = $2 AND role <> 'owner'
+			RETURNING user_id, role, created_at
+		)
+		SELECT u.id, u.first_name, u.last_name, u.email, updated.role, updated.created_at
+		FROM updated
+		JOIN users u ON u.id = updated.user_id
+	`
+
+	var m Member
+	err := r.db.QueryRow(ctx, query, groupID, userID, role).
+		Scan(&m.UserID, &m.FirstName, &m.LastName, &m.Email, &m.Role, &m.JoinedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMemberNotFound
+		}
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// RemoveMember เอาสมาชิกที่ไม่ใช่ owner ออก
+func (r *memberRepo) RemoveMember(ctx context.Context, groupID, userID int64) error {
+	query := `
+		DELETE FROM todo_group_members
+		WHERE todo_group_id = $1 AND user_id = $2 AND role <> 'owner'
+	`
+
+	cmdTag, err := r.db.Exec(ctx, query, groupID, userID)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrMemberNotFound
+	}
+
+	return nil
+}
+
+func (r *memberRepo) CreateInvitation(ctx context.Context, inv *Invitation, tokenHash []byte) error {
+	// email ที่เป็นสมาชิกอยู่แล้วไม่ต้องเชิญซ้ำ
+	var isMember bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM todo_group_members m
+			JOIN users u ON u.id = m.user_id
+			WHERE m.todo_group_id = $1 AND LOWER(u.email) = LOWER($2)
+		)
+	`, inv.TodoGroupID, inv.Email).Scan(&isMember)
+	if err != nil {
+		return err
+	}
+	if isMember {
+		return ErrAlreadyMember
+	}
+
+	query := `
+		WITH inserted AS (
+			INSERT INTO todo_group_invitations (todo_group_id, email, role, invited_by, token_hash)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING *
+		)
+		SELECT ` + invitationColumns + `
+		FROM inserted i
+		JOIN todo_groups g ON g.id = i.todo_group_id
+	`
+
+	err = r.db.QueryRow(ctx, query, inv.TodoGroupID, inv.Email, inv.Role, inv.InvitedBy, tokenHash).Scan(invitationDest(inv)...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_todo_group_invitations_pending" {
+			return ErrAlreadyInvited
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *memberRepo) ListGroupInvitations(ctx context.Context, groupID int64) ([]Invitation, error) {
+	query := `
+		SELECT ` + invitationColumns + `
+		FROM todo_group_invitations i
+		JOIN todo_groups g ON g.id = i.todo_group_id
+		WHERE i.todo_group_id = $1 AND i.status = 'pending'
+		ORDER BY i.created_at, i.id
+	`
+
+	rows, err := r.db.Query(ctx, query, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanInvitations(rows)
+}
+
+func (r *memberRepo) RevokeInvitation(ctx context.Context, groupID, invitationID int64) error {
+	query := `
+		UPDATE todo_group_invitations
+		SET status = 'revoked', responded_at = NOW()
+		WHERE id = $1 AND todo_group_id = $2 AND status = 'pending'
+	`
+
+	cmdTag, err := r.db.Exec(ctx, query, invitationID, groupID)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrInvitationNotFound
+	}
+
+	return nil
+}
+
+func (r *memberRepo) RespondInvitation(ctx context.Context, invitationID int64, tokenHash []byte, userID int64, accept bool) (*Invitation, error) {
+	status := InvitationDeclined
+	if accept {
+		status = InvitationAccepted
+	}
+
+	query := `
+		WITH updated AS (
+			UPDATE todo_group_invitations
+			SET status = $3, responded_at = NOW()
+			WHERE id = $1
+			  AND status = 'pending'
+			  AND token_hash = $2
+			RETURNING *
+		)
+		SELECT ` + invitationColumns + `
+		FROM updated i
+		JOIN todo_groups g ON g.id = i.todo_group_id
+	`
+
+	var inv Invitation
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, query, invitationID, tokenHash, status).Scan(invitationDest(&inv)...); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrInvitationNotFound
+			}
+			return err
+		}
+
+		if !accept {
+			return nil
+		}
+
+		// เป็นสมาชิกอยู่แล้ว (เช่นถูกเชิญสองทางพร้อมกัน) ก็ไม่ต้องทำอะไร role เดิมไม่เปลี่ยน
+		_, err := tx.Exec(ctx, `
+			INSERT INTO todo_group_members (todo_group_id, user_id, role)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (todo_group_id, user_id) DO NOTHING
+		`, inv.TodoGroupID, userID, inv.Role)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &inv, nil
+}
+
+func scanInvitations(rows pgx.Rows) ([]Invitation, error) {
+	defer rows.Close()
+
+	invitations := []Invitation{}
+	for rows.Next() {
+		var inv Invitation
+		if err := rows.Scan(invitationDest(&inv)...); err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, inv)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return invitations, nil
+}
@@ -0,0 +1,186 @@
+package todogroup
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"strings"
+
+	"github.com/Nasaee/go-todo-backend/internal/event"
+	"github.com/Nasaee/go-todo-backend/pkg/invitetoken"
+)
+
+// EventInvitationCreated ส่ง token ของคำเชิญให้ worker ส่ง email ไปหาคนที่ถูกเชิญ
+// token ไม่ตอบกลับไปให้คนเชิญ คนที่รับคำเชิญได้ต้องเปิด email นั้นได้จริง
+const EventInvitationCreated = "todogroup.invitation_created"
+
+type InvitationCreatedEvent struct {
+	InvitationID int64  `json:"invitation_id"`
+	TodoGroupID  int64  `json:"todo_group_id"`
+	GroupName    string `json:"group_name"`
+	Email        string `json:"email"`
+	Role         Role   `json:"role"`
+	InvitedBy    int64  `json:"invited_by"`
+	Token        string `json:"token"`
+}
+
+type MemberService interface {
+	ListMembers(ctx context.Context, groupID, userID int64) ([]Member, error)
+	UpdateMember(ctx context.Context, groupID, userID, memberID int64, input UpdateMemberInput) (*Member, error)
+	// RemoveMember owner เอาคนอื่นออก หรือสมาชิกออกจาก group เอง (memberID = userID)
+	RemoveMember(ctx context.Context, groupID, userID, memberID int64) error
+
+	Invite(ctx context.Context, groupID, userID int64, input InviteInput) (*Invitation, error)
+	ListGroupInvitations(ctx context.Context, groupID, userID int64) ([]Invitation, error)
+	RevokeInvitation(ctx context.Context, groupID, invitationID, userID int64) error
+
+	// AcceptInvitation / DeclineInvitation ต้องมี token จาก email ที่ถูกเชิญ
+	AcceptInvitation(ctx context.Context, invitationID, userID int64, input RespondInvitationInput) (*Invitation, error)
+	DeclineInvitation(ctx context.Context, invitationID, userID int64, input RespondInvitationInput) (*Invitation, error)
+}
+
+type memberService struct {
+	repo   MemberRepository
+	groups TodoGroupRepository
+	events event.Publisher
+}
+
+func NewMemberService(repo MemberRepository, groups TodoGroupRepository, events event.Publisher) MemberService {
+	return &memberService{repo: repo, groups: groups, events: events}
+}
+
+// สมาชิกทุก role ดูรายชื่อกันเองได้
+func (s *memberService) ListMembers(ctx context.Context, groupID, userID int64) ([]Member, error) {
+	if _, err := requireRole(ctx, s.groups, groupID, userID, RoleViewer); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListMembers(ctx, groupID)
+}
+
+func (s *memberService) UpdateMember(ctx context.Context, groupID, userID, memberID int64, input UpdateMemberInput) (*Member, error) {
+	g, err := requireRole(ctx, s.groups, groupID, userID, RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+	if memberID == g.UserID {
+		return nil, ErrOwnerMember
+	}
+
+	role, err := parseMemberRole(input.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpdateMemberRole(ctx, groupID, memberID, role)
+}
+
+func (s *memberService) RemoveMember(ctx context.Context, groupID, userID, memberID int64) error {
+	min := RoleOwner
+	if memberID == userID {
+		min = RoleViewer
+	}
+
+	g, err := requireRole(ctx, s.groups, groupID, userID, min)
+	if err != nil {
+		return err
+	}
+	if memberID == g.UserID {
+		return ErrOwnerMember
+	}
+
+	return s.repo.RemoveMember(ctx, groupID, memberID)
+}
+
+// Invite สร้างคำเชิญถึง email (ยังไม่มี account ก็ได้) แล้วส่ง token ไปทาง email ผ่าน event
+// email ของ account ไม่ได้ยืนยัน จึงใช้จับคู่กับคำเชิญไม่ได้ ต้องถือ token เท่านั้น
+func (s *memberService) Invite(ctx context.Context, groupID, userID int64, input InviteInput) (*Invitation, error) {
+	g, err := requireRole(ctx, s.groups, groupID, userID, RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+	if g.IsInbox {
+		return nil, ErrInboxShare
+	}
+
+	addr, err := mail.ParseAddress(strings.TrimSpace(input.Email))
+	if err != nil || addr.Name != "" {
+		return nil, ErrInvalidEmail
+	}
+
+	role := RoleViewer
+	if strings.TrimSpace(input.Role) != "" {
+		if role, err = parseMemberRole(input.Role); err != nil {
+			return nil, err
+		}
+	}
+
+	inv := &Invitation{
+		TodoGroupID: groupID,
+		Email:       strings.ToLower(addr.Address),
+		Role:        role,
+		InvitedBy:   userID,
+	}
+
+	token, err := invitetoken.New()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateInvitation(ctx, inv, invitetoken.Hash(token)); err != nil {
+		return nil, err
+	}
+
+	// ส่ง email ไม่ได้ = ไม่มีใครรับคำเชิญนี้ได้ ยกเลิกทิ้งไม่ให้ค้างกันเชิญซ้ำ
+	e := event.New(EventInvitationCreated, InvitationCreatedEvent{
+		InvitationID: inv.ID,
+		TodoGroupID:  inv.TodoGroupID,
+		GroupName:    inv.GroupName,
+		Email:        inv.Email,
+		Role:         inv.Role,
+		InvitedBy:    inv.InvitedBy,
+		Token:        token,
+	})
+	if err := s.events.Publish(ctx, e); err != nil {
+		if revokeErr := s.repo.RevokeInvitation(ctx, groupID, inv.ID); revokeErr != nil {
+			return nil, errors.Join(err, revokeErr)
+		}
+		return nil, err
+	}
+
+	return inv, nil
+}
+
+func (s *memberService) ListGroupInvitations(ctx context.Context, groupID, userID int64) ([]Invitation, error) {
+	if _, err := requireRole(ctx, s.groups, groupID, userID, RoleOwner); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListGroupInvitations(ctx, groupID)
+}
+
+func (s *memberService) RevokeInvitation(ctx context.Context, groupID, invitationID, userID int64) error {
+	if _, err := requireRole(ctx, s.groups, groupID, userID, RoleOwner); err != nil {
+		return err
+	}
+
+	return s.repo.RevokeInvitation(ctx, groupID, invitationID)
+}
+
+func (s *memberService) AcceptInvitation(ctx context.Context, invitationID, userID int64, input RespondInvitationInput) (*Invitation, error) {
+	return s.respondInvitation(ctx, invitationID, userID, input, true)
+}
+
+func (s *memberService) DeclineInvitation(ctx context.Context, invitationID, userID int64, input RespondInvitationInput) (*Invitation, error) {
+	return s.respondInvitation(ctx, invitationID, userID, input, false)
+}
+
+// token ผิด / ไม่ส่งมา ตอบเหมือนไม่มีคำเชิญ (ไม่บอกว่า id นี้มีอยู่จริง)
+func (s *memberService) respondInvitation(ctx context.Context, invitationID, userID int64, input RespondInvitationInput, accept bool) (*Invitation, error) {
+	token := strings.TrimSpace(input.Token)
+	if token == "" {
+		return nil, ErrInvitationNotFound
+	}
+
+	return s.repo.RespondInvitation(ctx, invitationID, invitetoken.Hash(token), userID, accept)
+}
@@ -1,17 +1,103 @@
 package todogroup
 
-import "time"
+import (
+	"time"
+
+	"github.com/Nasaee/go-todo-backend/pkg/civil"
+	"github.com/Nasaee/go-todo-backend/pkg/nullable"
+)
 
 type TodoGroup struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	Color     string    `json:"color"`
-	UserID    int64     `json:"user_id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          int64     `json:"id"`
+	WorkspaceID int64     `json:"workspace_id"`
+	Name        string    `json:"name"`
+	Color       string    `json:"color"`
+	UserID      int64     `json:"user_id"`
+	IsInbox     bool      `json:"is_inbox"`
+	Position    string    `json:"position"`
+	ParentID    *int64    `json:"parent_id"` // nil = อยู่ชั้นบนสุด
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+
+	// role ของ user ที่เรียก API ใน group นี้ (group ที่คนอื่นแชร์มาจะไม่ใช่ owner)
+	Role Role `json:"role,omitempty"`
+
+	// มีค่าเฉพาะตอนขอ ?with=stats
+	Stats *TodoGroupStats `json:"stats,omitempty"`
+}
+
+// TodoGroupStats สรุปจำนวน todos ในกลุ่ม (ไม่นับ group ลูก)
+// overdue / due_today / next_due_date นับเฉพาะ todo ที่ยังไม่เสร็จ วันคิดตาม timezone ของ user ที่ดู
+//   - todo ทั้งวันครบกำหนด date_end ถ้ามี ไม่งั้น date_start
+//   - todo ที่มีเวลาครบกำหนด end_at ถ้ามี ไม่งั้น start_at (เลยเวลาแล้ว = overdue แม้ยังเป็นวันนี้)
+type TodoGroupStats struct {
+	Total       int         `json:"total"`
+	Open        int         `json:"open"`
+	Done        int         `json:"done"`
+	Overdue     int         `json:"overdue"`
+	DueToday    int         `json:"due_today"`
+	NextDueDate *civil.Date `json:"next_due_date"`
+}
+
+// TodoGroupNode ใช้กับ GET /todo-groups?view=tree
+type TodoGroupNode struct {
+	TodoGroup
+	Children []*TodoGroupNode `json:"children"`
+}
+
+// ListOptions ใช้กับ GET /todo-groups
+type ListOptions struct {
+	IncludeArchived bool // ?include=archived
+	WithStats       bool // ?with=stats
+
+	// nil = ทุก workspace (route เดิม) service ใส่ให้จาก context ของ /workspaces/{wid}/...
+	WorkspaceID *int64
+
+	// Clock ใช้เมื่อ WithStats (service ใส่ให้ตาม timezone ของ user)
+	Clock StatsClock
+}
+
+// StatsClock วันนี้ / ตอนนี้ของ user ที่ดู ใช้คิด overdue / due_today / next_due_date
+type StatsClock struct {
+	Timezone string
+	Today    civil.Date
+	Now      time.Time
+	TodayEnd time.Time // เที่ยงคืนของพรุ่งนี้ตาม Timezone
+}
+
+// Access สิทธิ์ของ user ต่อ group หนึ่ง (ใช้เช็คก่อนเขียน todo)
+type Access struct {
+	OwnerID     int64
+	WorkspaceID int64
+	Role        Role // "" = ไม่ได้เป็นสมาชิก
 }
 
 type CreateTodoGroupInput struct {
-	Name  string `json:"name"`
-	Color string `json:"color"`
+	Name     string `json:"name"`
+	Color    string `json:"color"`
+	ParentID *int64 `json:"parent_id"`
+}
+
+// ใช้กับ PUT (แทนที่ทั้งก้อน ไม่ส่ง parent_id = ย้ายขึ้นชั้นบนสุด)
+type UpdateTodoGroupInput struct {
+	Name     string `json:"name"`
+	Color    string `json:"color"`
+	ParentID *int64 `json:"parent_id"`
+}
+
+// ใช้กับ PATCH (ส่งมาเฉพาะ field ที่จะแก้)
+// parent_id: null = ย้ายขึ้นชั้นบนสุด, ไม่ส่ง = ไม่แก้
+type PatchTodoGroupInput struct {
+	Name     *string               `json:"name"`
+	Color    *string               `json:"color"`
+	ParentID nullable.Field[int64] `json:"parent_id"`
+}
+
+// ใช้กับ POST /todo-groups/{id}/move
+// ส่งตัวใดตัวหนึ่งหรือทั้งคู่ก็ได้ (ทั้งคู่ = ต้องเป็น group ที่อยู่ติดกัน)
+type MoveTodoGroupInput struct {
+	BeforeID *int64 `json:"before_id"` // วางไว้ก่อน group นี้
+	AfterID  *int64 `json:"after_id"`  // วางไว้หลัง group นี้
 }
@@ -3,15 +3,127 @@ package todogroup
 import (
 	"context"
 	"errors"
+	"fmt"
 
+	"github.com/Nasaee/go-todo-backend/internal/db/postgres"
+	"github.com/Nasaee/go-todo-backend/pkg/rank"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-var ErrEmptyName = errors.New("todo group name is required")
+var (
+	ErrEmptyName         = errors.New("todo group name is required")
+	ErrNotFound          = errors.New("todo group not found")
+	ErrInvalidMoveTarget = errors.New("target todo group must be another group you own in the same workspace")
+	ErrInboxUndeletable  = errors.New("inbox group cannot be deleted")
+	ErrInvalidMove       = errors.New("before_id / after_id must reference other groups you own, in order")
+	ErrInboxArchive      = errors.New("inbox group cannot be archived")
+	ErrInvalidParent     = errors.New("parent_id must reference another group you own in the same workspace (not the inbox)")
+	ErrParentCycle       = errors.New("a group cannot be nested inside itself or one of its subgroups")
+	ErrTooDeep           = fmt.Errorf("groups can be nested at most %d levels deep", MaxDepth)
+)
+
+// MaxDepth จำนวนชั้นสูงสุดของ group ที่ซ้อนกัน (ชั้นบนสุด = 1) เช่น Area > Project > Sub-project
+const MaxDepth = 3
+
+// column ที่ SELECT / RETURNING ทุกครั้ง (ลำดับต้องตรงกับ scanGroup)
+// ใส่ชื่อตารางนำหน้าไว้ เพราะบาง query JOIN กับ todo_group_access ที่มี user_id ซ้ำกัน
+const groupColumns = `todo_groups.id, todo_groups.workspace_id, todo_groups.name, todo_groups.color, todo_groups.user_id,
+	todo_groups.is_inbox, todo_groups.position, todo_groups.parent_id, todo_groups.archived_at,
+	todo_groups.created_at, todo_groups.updated_at`
+
+// JOIN เฉพาะ group ที่ user ($1 / $2 แล้วแต่ query) มีสิทธิ์ พร้อม role ของ user ใน group นั้น
+// todo_group_access = สมาชิกของ group โดยตรง + สมาชิกของ team workspace ที่ group อยู่ (ดู migration 00015)
+const memberJoin = `JOIN todo_group_access m ON m.todo_group_id = todo_groups.id AND m.user_id = `
+
+// mapParentFKError parent อยู่คนละ workspace = FK (parent_id, workspace_id) ไม่ผ่าน
+func mapParentFKError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" && pgErr.ConstraintName == "fk_todo_groups_parent_workspace" {
+		return ErrInvalidParent
+	}
+	return err
+}
+
+func scanGroup(row pgx.Row, g *TodoGroup) error {
+	return row.Scan(groupDest(g)...)
+}
+
+func groupDest(g *TodoGroup) []any {
+	return []any{
+		&g.ID,
+		&g.WorkspaceID,
+		&g.Name,
+		&g.Color,
+		&g.UserID,
+		&g.IsInbox,
+		&g.Position,
+		&g.ParentID,
+		&g.ArchivedAt,
+		&g.CreatedAt,
+		&g.UpdatedAt,
+	}
+}
+
+// statsJoin นับ todos ของทุก group ใน query เดียว (GROUP BY) แล้ว LEFT JOIN เข้ากับ group
+// "ครบกำหนด" ของ todo ทั้งวัน = date_end ถ้ามี ไม่งั้น date_start, todo ที่มีเวลา = end_at ถ้ามี ไม่งั้น start_at
+// วันนี้ / ตอนนี้มาจาก StatsClock ($4 timezone, $5 วันนี้, $6 ตอนนี้, $7 เที่ยงคืนของพรุ่งนี้) ไม่ใช่ CURRENT_DATE ของ DB
+const statsJoin = `
+	LEFT JOIN (
+		SELECT
+			todo_group_id,
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE NOT is_success) AS open,
+			COUNT(*) FILTER (WHERE is_success) AS done,
+			COUNT(*) FILTER (WHERE NOT is_success AND CASE WHEN start_at IS NULL
+				THEN COALESCE(date_end, date_start) < $5::DATE
+				ELSE COALESCE(end_at, start_at) < $6
+			END) AS overdue,
+			COUNT(*) FILTER (WHERE NOT is_success AND CASE WHEN start_at IS NULL
+				THEN COALESCE(date_end, date_start) = $5::DATE
+				ELSE COALESCE(end_at, start_at) >= $6 AND COALESCE(end_at, start_at) < $7
+			END) AS due_today,
+			MIN(CASE WHEN start_at IS NULL
+				THEN COALESCE(date_end, date_start)
+				ELSE (COALESCE(end_at, start_at) AT TIME ZONE $4::TEXT)::DATE
+			END) FILTER (WHERE NOT is_success AND CASE WHEN start_at IS NULL
+				THEN COALESCE(date_end, date_start) >= $5::DATE
+				ELSE COALESCE(end_at, start_at) >= $6
+			END) AS next_due_date
+		FROM todos
+		WHERE todo_group_id IN (SELECT todo_group_id FROM todo_group_access WHERE user_id = $1)
+		GROUP BY todo_group_id
+	) s ON s.todo_group_id = todo_groups.id
+`
+
+const statsColumns = `
+	COALESCE(s.total, 0),
+	COALESCE(s.open, 0),
+	COALESCE(s.done, 0),
+	COALESCE(s.overdue, 0),
+	COALESCE(s.due_today, 0),
+	s.next_due_date
+`
 
 type TodoGroupRepository interface {
 	Create(ctx context.Context, g *TodoGroup) error
-	GetAllByUser(ctx context.Context, userID int64) ([]TodoGroup, error)
+	CreateWithTodos(ctx context.Context, g *TodoGroup, seeds []TodoSeed) error
+	ListTodoSeeds(ctx context.Context, groupID int64) ([]TodoSeed, error)
+	GetAllByUser(ctx context.Context, userID int64, opts ListOptions) ([]TodoGroup, error)
+	GetByID(ctx context.Context, id, userID int64) (*TodoGroup, error)
+	// GetAccess คืนเจ้าของ group / workspace ของ group + role ของ userID ("" = ไม่ได้เป็นสมาชิก)
+	GetAccess(ctx context.Context, id, userID int64) (Access, error)
+	GetInboxID(ctx context.Context, userID int64) (int64, error)
+	// GetSubtreeIDs คืน id ของ group นี้ + group ลูกหลานทั้งหมด (includeArchived = false ตัด subtree ที่ archive ทิ้ง)
+	GetSubtreeIDs(ctx context.Context, id, userID int64, includeArchived bool) ([]int64, error)
+	Update(ctx context.Context, g *TodoGroup) error
+	Delete(ctx context.Context, id, userID int64) error
+	MoveTodosAndDelete(ctx context.Context, id, targetID, userID int64) error
+	Move(ctx context.Context, id, userID int64, beforeID, afterID *int64) (*TodoGroup, error)
+	SetArchived(ctx context.Context, id, userID int64, archived bool) (*TodoGroup, error)
+	// UserTimezone timezone ของ user ("UTC" ถ้าไม่เจอ user)
+	UserTimezone(ctx context.Context, userID int64) (string, error)
 }
 
 type postgresRepo struct {
@@ -27,30 +139,169 @@ func (r *postgresRepo) Create(ctx context.Context, g *TodoGroup) error {
 		return ErrEmptyName
 	}
 
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := postgres.LockUserOrdering(ctx, tx, g.UserID); err != nil {
+			return err
+		}
+
+		return insertGroup(ctx, tx, g)
+	})
+}
+
+// CreateWithTodos สร้าง group พร้อม todos ใน transaction เดียว (ใช้ตอน clone / สร้างจาก template)
+// todos ต่อท้าย list ของ user ตามลำดับใน seeds, ยังไม่เสร็จทั้งหมด และยังไม่มีคนรับผิดชอบ
+func (r *postgresRepo) CreateWithTodos(ctx context.Context, g *TodoGroup, seeds []TodoSeed) error {
+	if g.Name == "" {
+		return ErrEmptyName
+	}
+
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := postgres.LockUserOrdering(ctx, tx, g.UserID); err != nil {
+			return err
+		}
+
+		if err := insertGroup(ctx, tx, g); err != nil {
+			return err
+		}
+
+		if len(seeds) == 0 {
+			return nil
+		}
+
+		var last string
+		if err := tx.QueryRow(ctx, `
+			SELECT COALESCE(MAX(position), '')
+			FROM todos
+			WHERE user_id = $1
+		`, g.UserID).Scan(&last); err != nil {
+			return err
+		}
+
+		// ส่งทีเดียวเป็น batch ไม่ต้องวิ่งไปกลับทีละแถว
+		batch := &pgx.Batch{}
+		for _, t := range seeds {
+			pos, err := rank.After(last)
+			if err != nil {
+				return err
+			}
+			last = pos
+
+			batch.Queue(`
+				INSERT INTO todos (
+					title, description, date_start, date_end, user_id, todo_group_id, position,
+					start_at, end_at, timezone, priority, important
+				)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			`, t.Title, t.Description, t.DateStart, t.DateEnd, g.UserID, g.ID, pos,
+				t.StartAt, t.EndAt, t.Timezone, t.Priority, t.Important)
+		}
+
+		return tx.SendBatch(ctx, batch).Close()
+	})
+}
+
+// insertGroup ใส่ group ใหม่ต่อท้าย list ของ user (ต้อง lock ลำดับของ user ไว้แล้ว)
+func insertGroup(ctx context.Context, tx pgx.Tx, g *TodoGroup) error {
+	// สร้าง group ใน team workspace ได้เฉพาะสมาชิกที่ไม่ใช่ viewer
+	if g.WorkspaceID != 0 {
+		var canWrite bool
+		if err := tx.QueryRow(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM workspace_members
+				WHERE workspace_id = $1 AND user_id = $2 AND role <> 'viewer'
+			)
+		`, g.WorkspaceID, g.UserID).Scan(&canWrite); err != nil {
+			return err
+		}
+		if !canWrite {
+			return ErrForbidden
+		}
+	}
+
+	if g.ParentID != nil {
+		if err := checkParent(ctx, tx, 0, *g.ParentID, g.UserID); err != nil {
+			return err
+		}
+	}
+
+	var last string
+	err := tx.QueryRow(ctx, `
+		SELECT COALESCE(MAX(position), '')
+		FROM todo_groups
+		WHERE user_id = $1
+	`, g.UserID).Scan(&last)
+	if err != nil {
+		return err
+	}
+
+	g.Position, err = rank.After(last)
+	if err != nil {
+		return err
+	}
+
+	// ไม่ระบุ workspace (0) = personal workspace ของ user
 	query := `
-		INSERT INTO todo_groups (name, user_id, color)
-		VALUES ($1, $2, $3)
-		RETURNING id, created_at, updated_at
+		INSERT INTO todo_groups (name, user_id, color, position, parent_id, workspace_id)
+		VALUES ($1, $2, $3, $4, $5, COALESCE(
+			NULLIF($6::BIGINT, 0),
+			(SELECT id FROM workspaces WHERE personal_user_id = $2)
+		))
+		RETURNING id, workspace_id, created_at, updated_at
 	`
 	/*
 		ม่ต้องปิด(defer rows.Close()) ถ้าใช้:
 		db.QueryRow()
 		pool.QueryRow()
 	*/
-	row := r.db.QueryRow(ctx, query, g.Name, g.UserID, g.Color)
+	row := tx.QueryRow(ctx, query, g.Name, g.UserID, g.Color, g.Position, g.ParentID, g.WorkspaceID)
+
+	return mapParentFKError(row.Scan(&g.ID, &g.WorkspaceID, &g.CreatedAt, &g.UpdatedAt))
+}
 
-	return row.Scan(&g.ID, &g.CreatedAt, &g.UpdatedAt)
+// ListTodoSeeds todos ใน group ตามลำดับใน list (ใช้เป็นต้นแบบตอน clone / บันทึกเป็น template)
+// ไม่ scope ด้วย user ให้ service เช็คสิทธิ์ดู group ก่อน
+func (r *postgresRepo) ListTodoSeeds(ctx context.Context, groupID int64) ([]TodoSeed, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT title, description, date_start, date_end, start_at, end_at, timezone, priority, important
+		FROM todos
+		WHERE todo_group_id = $1
+		ORDER BY position
+	`, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (TodoSeed, error) {
+		var t TodoSeed
+		err := row.Scan(
+			&t.Title, &t.Description, &t.DateStart, &t.DateEnd,
+			&t.StartAt, &t.EndAt, &t.Timezone, &t.Priority, &t.Important,
+		)
+		return t, err
+	})
 }
 
-func (r *postgresRepo) GetAllByUser(ctx context.Context, userID int64) ([]TodoGroup, error) {
+func (r *postgresRepo) GetAllByUser(ctx context.Context, userID int64, opts ListOptions) ([]TodoGroup, error) {
+	columns, join := groupColumns+", m.role", memberJoin+"$1"
+	args := []any{userID, opts.IncludeArchived, opts.WorkspaceID}
+	if opts.WithStats {
+		columns += ", " + statsColumns
+		join += statsJoin
+		c := opts.Clock
+		args = append(args, c.Timezone, c.Today, c.Now, c.TodayEnd)
+	}
+
+	// ทั้ง group ของตัวเองและ group ที่คนอื่นแชร์มา
 	query := `
-		SELECT id, name, color, user_id, created_at, updated_at
+		SELECT ` + columns + `
 		FROM todo_groups
-		WHERE user_id = $1
-		ORDER BY name
+		` + join + `
+		WHERE ($2 OR todo_groups.archived_at IS NULL)
+		  AND ($3::BIGINT IS NULL OR todo_groups.workspace_id = $3)
+		ORDER BY todo_groups.position, todo_groups.id
 	`
 
-	rows, err := r.db.Query(ctx, query, userID)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -59,15 +310,21 @@ func (r *postgresRepo) GetAllByUser(ctx context.Context, userID int64) ([]TodoGr
 	var groups []TodoGroup
 	for rows.Next() {
 		var g TodoGroup
+		dest := append(groupDest(&g), &g.Role)
+		if opts.WithStats {
+			g.Stats = &TodoGroupStats{}
+			dest = append(dest,
+				&g.Stats.Total,
+				&g.Stats.Open,
+				&g.Stats.Done,
+				&g.Stats.Overdue,
+				&g.Stats.DueToday,
+				&g.Stats.NextDueDate,
+			)
+		}
+
 		// .Scan() เพื่อ อ่านค่าของแถวนี้ แล้ว ใส่ลงในตัวแปร ที่เตรียมไว้
-		if err := rows.Scan(
-			&g.ID,
-			&g.Name,
-			&g.Color,
-			&g.UserID,
-			&g.CreatedAt,
-			&g.UpdatedAt,
-		); err != nil {
+		if err := rows.Scan(dest...); err != nil {
 			return nil, err
 		}
 
@@ -80,3 +337,394 @@ func (r *postgresRepo) GetAllByUser(ctx context.Context, userID int64) ([]TodoGr
 
 	return groups, nil
 }
+
+// GetByID คืน group ที่ user มองเห็น (เป็นสมาชิก role ไหนก็ได้) พร้อม Role ของ user
+// สิทธิ์แก้ไขให้ service เช็คจาก Role เอง
+func (r *postgresRepo) GetByID(ctx context.Context, id, userID int64) (*TodoGroup, error) {
+	query := `
+		SELECT ` + groupColumns + `, m.role
+		FROM todo_groups
+		` + memberJoin + `$2
+		WHERE todo_groups.id = $1
+	`
+
+	var g TodoGroup
+	err := r.db.QueryRow(ctx, query, id, userID).Scan(append(groupDest(&g), &g.Role)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &g, nil
+}
+
+// GetAccess ไม่ scope ด้วย user เพราะใช้แยกเคส "ไม่มี group นี้" (ErrNotFound) กับ "ไม่ได้เป็นสมาชิก" (role = "")
+func (r *postgresRepo) GetAccess(ctx context.Context, id, userID int64) (Access, error) {
+	query := `
+		SELECT g.user_id, g.workspace_id, COALESCE(m.role, '')
+		FROM todo_groups g
+		LEFT JOIN todo_group_access m ON m.todo_group_id = g.id AND m.user_id = $2
+		WHERE g.id = $1
+	`
+
+	var a Access
+	if err := r.db.QueryRow(ctx, query, id, userID).Scan(&a.OwnerID, &a.WorkspaceID, &a.Role); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Access{}, ErrNotFound
+		}
+		return Access{}, err
+	}
+
+	return a, nil
+}
+
+func (r *postgresRepo) GetInboxID(ctx context.Context, userID int64) (int64, error) {
+	query := `
+		SELECT id
+		FROM todo_groups
+		WHERE user_id = $1 AND is_inbox
+	`
+
+	var id int64
+	if err := r.db.QueryRow(ctx, query, userID).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *postgresRepo) GetSubtreeIDs(ctx context.Context, id, userID int64, includeArchived bool) ([]int64, error) {
+	// group ตั้งต้นคืนเสมอแม้จะ archive แล้ว (ขอมาตรง ๆ) ส่วนลูกหลานที่ archive ตัดทั้งกิ่ง
+	// เอาเฉพาะ group ที่ user เป็นสมาชิก (ลูกที่ไม่ได้แชร์มาด้วยก็ตัดทั้งกิ่งเหมือนกัน)
+	query := `
+		WITH RECURSIVE visible AS (
+			SELECT todo_group_id AS id FROM todo_group_access WHERE user_id = $2
+		),
+		subtree AS (
+			SELECT id, 1 AS depth
+			FROM todo_groups
+			WHERE id = $1 AND id IN (SELECT id FROM visible)
+			UNION ALL
+			SELECT g.id, s.depth + 1
+			FROM todo_groups g
+			JOIN subtree s ON g.parent_id = s.id
+			WHERE ($3 OR g.archived_at IS NULL)
+			  AND g.id IN (SELECT id FROM visible)
+			  AND s.depth <= $4
+		)
+		SELECT id FROM subtree
+	`
+
+	rows, err := r.db.Query(ctx, query, id, userID, includeArchived, MaxDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return ids, nil
+}
+
+func (r *postgresRepo) Update(ctx context.Context, g *TodoGroup) error {
+	if g.Name == "" {
+		return ErrEmptyName
+	}
+
+	query := `
+		UPDATE todo_groups
+		SET name = $1, color = $2, parent_id = $3
+		WHERE id = $4 AND user_id = $5
+		RETURNING ` + groupColumns + `
+	`
+
+	// lock ลำดับของ user ไว้ด้วย กันสอง request ย้าย group สลับกันจนเกิด cycle
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := postgres.LockUserOrdering(ctx, tx, g.UserID); err != nil {
+			return err
+		}
+
+		if g.ParentID != nil {
+			if err := checkParent(ctx, tx, g.ID, *g.ParentID, g.UserID); err != nil {
+				return err
+			}
+		}
+
+		err := scanGroup(tx.QueryRow(ctx, query, g.Name, g.Color, g.ParentID, g.ID, g.UserID), g)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		g.Role = RoleOwner
+		return mapParentFKError(err)
+	})
+}
+
+// checkParent เช็คว่าเอา group id ไปไว้ใต้ parentID ได้ไหม (id = 0 คือ group ที่กำลังสร้างใหม่)
+//   - parent ต้องเป็นของ user และไม่ใช่ inbox, inbox เองก็ซ้อนใต้ใครไม่ได้
+//   - parent ต้องไม่ใช่ตัวเองหรือลูกหลานของตัวเอง (กัน cycle)
+//   - ความลึกของ parent + ความสูงของ subtree ที่ย้ายมา ต้องไม่เกิน MaxDepth
+//
+// ต้องเรียกใน transaction ที่ lock ลำดับของ user ไว้แล้ว
+func checkParent(ctx context.Context, tx pgx.Tx, id, parentID, userID int64) error {
+	if parentID == id {
+		return ErrParentCycle
+	}
+
+	height := 1
+	if id != 0 {
+		var isInbox bool
+		err := tx.QueryRow(ctx, `
+			SELECT is_inbox FROM todo_groups WHERE id = $1 AND user_id = $2
+		`, id, userID).Scan(&isInbox)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if isInbox {
+			return ErrInvalidParent
+		}
+
+		if err := tx.QueryRow(ctx, `
+			WITH RECURSIVE subtree AS (
+				SELECT id, 1 AS depth
+				FROM todo_groups
+				WHERE id = $1 AND user_id = $2
+				UNION ALL
+				SELECT g.id, s.depth + 1
+				FROM todo_groups g
+				JOIN subtree s ON g.parent_id = s.id
+				WHERE s.depth <= $3
+			)
+			SELECT MAX(depth) FROM subtree
+		`, id, userID, MaxDepth).Scan(&height); err != nil {
+			return err
+		}
+	}
+
+	// เดินจาก parent ขึ้นไปถึงชั้นบนสุด
+	var (
+		depth       int
+		isCycle     bool
+		parentInbox bool
+	)
+	err := tx.QueryRow(ctx, `
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id, is_inbox, 1 AS depth
+			FROM todo_groups
+			WHERE id = $1 AND user_id = $2
+			UNION ALL
+			SELECT g.id, g.parent_id, g.is_inbox, a.depth + 1
+			FROM todo_groups g
+			JOIN ancestors a ON g.id = a.parent_id
+			WHERE a.depth <= $4
+		)
+		SELECT
+			COUNT(*),
+			COALESCE(BOOL_OR(id = $3), FALSE),
+			COALESCE(BOOL_OR(is_inbox), FALSE)
+		FROM ancestors
+	`, parentID, userID, id, MaxDepth).Scan(&depth, &isCycle, &parentInbox)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case depth == 0, parentInbox:
+		return ErrInvalidParent
+	case isCycle:
+		return ErrParentCycle
+	case depth+height > MaxDepth:
+		return ErrTooDeep
+	}
+
+	return nil
+}
+
+// Delete ลบ group ทิ้ง todos ในกลุ่มจะหายไปด้วย (ON DELETE CASCADE)
+// inbox ไม่โดนลบ (service เช็คก่อนแล้ว อันนี้กันไว้อีกชั้น)
+func (r *postgresRepo) Delete(ctx context.Context, id, userID int64) error {
+	query := `
+		DELETE FROM todo_groups
+		WHERE id = $1 AND user_id = $2 AND NOT is_inbox
+	`
+
+	cmdTag, err := r.db.Exec(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// MoveTodosAndDelete ย้าย todos ทั้งหมด (+ series ของ todo ที่เกิดซ้ำ) ไป group ปลายทางก่อน แล้วค่อยลบ group (ทำใน transaction เดียว)
+func (r *postgresRepo) MoveTodosAndDelete(ctx context.Context, id, targetID, userID int64) error {
+	if id == targetID {
+		return ErrInvalidMoveTarget
+	}
+
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		// lock ทั้งสอง group กันโดนลบ/ย้ายพร้อมกัน (ปลายทางต้องอยู่ workspace เดียวกัน)
+		var n int
+		err := tx.QueryRow(ctx, `
+			SELECT COUNT(*)
+			FROM (
+				SELECT id FROM todo_groups
+				WHERE id IN ($1, $2) AND user_id = $3
+				  AND workspace_id = (SELECT workspace_id FROM todo_groups WHERE id = $1)
+				FOR UPDATE
+			) g
+		`, id, targetID, userID).Scan(&n)
+		if err != nil {
+			return err
+		}
+
+		switch n {
+		case 2:
+		case 1:
+			// มีแค่ตัวเดียว ต้องดูว่าหายตัวไหน
+			var exists bool
+			if err := tx.QueryRow(ctx,
+				`SELECT EXISTS (SELECT 1 FROM todo_groups WHERE id = $1 AND user_id = $2)`,
+				id, userID,
+			).Scan(&exists); err != nil {
+				return err
+			}
+			if !exists {
+				return ErrNotFound
+			}
+			return ErrInvalidMoveTarget
+		default:
+			return ErrNotFound
+		}
+
+		// คนรับผิดชอบที่เข้าถึง group ปลายทางไม่ได้ = ไม่มีคนรับผิดชอบ (เหมือนโดนเอาออกจาก group)
+		if _, err := tx.Exec(ctx, `
+			UPDATE todos
+			SET todo_group_id = $1,
+				assignee_id = CASE WHEN EXISTS (
+					SELECT 1 FROM todo_group_access a
+					WHERE a.todo_group_id = $1 AND a.user_id = todos.assignee_id
+				) THEN assignee_id END
+			WHERE todo_group_id = $2 AND user_id = $3
+		`, targetID, id, userID); err != nil {
+			return err
+		}
+
+		// series ของ todos ที่เกิดซ้ำย้ายตามไปด้วย ไม่งั้นโดน cascade ลบพร้อม group แล้ว todos เลิกเกิดซ้ำ
+		if _, err := tx.Exec(ctx, `
+			UPDATE todo_series
+			SET todo_group_id = $1,
+				assignee_id = CASE WHEN EXISTS (
+					SELECT 1 FROM todo_group_access a
+					WHERE a.todo_group_id = $1 AND a.user_id = todo_series.assignee_id
+				) THEN assignee_id END
+			WHERE todo_group_id = $2
+		`, targetID, id); err != nil {
+			return err
+		}
+
+		cmdTag, err := tx.Exec(ctx, `DELETE FROM todo_groups WHERE id = $1 AND user_id = $2 AND NOT is_inbox`, id, userID)
+		if err != nil {
+			return err
+		}
+		if cmdTag.RowsAffected() == 0 {
+			return ErrInboxUndeletable
+		}
+		return nil
+	})
+}
+
+// Move เปลี่ยน position ของ group แถวเดียว ให้ไปอยู่ก่อน beforeID และ/หรือหลัง afterID
+func (r *postgresRepo) Move(ctx context.Context, id, userID int64, beforeID, afterID *int64) (*TodoGroup, error) {
+	if beforeID == nil && afterID == nil {
+		return nil, ErrInvalidMove
+	}
+	if (beforeID != nil && *beforeID == id) || (afterID != nil && *afterID == id) {
+		return nil, ErrInvalidMove
+	}
+
+	var g *TodoGroup
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := postgres.LockUserOrdering(ctx, tx, userID); err != nil {
+			return err
+		}
+
+		lower, upper, err := postgres.NeighborPositions(ctx, tx, "todo_groups", id, userID, beforeID, afterID)
+		if err != nil {
+			if errors.Is(err, postgres.ErrAnchorNotFound) {
+				return ErrInvalidMove
+			}
+			return err
+		}
+
+		pos, err := rank.Between(lower, upper)
+		if err != nil {
+			return ErrInvalidMove
+		}
+
+		g = &TodoGroup{}
+		err = scanGroup(tx.QueryRow(ctx, `
+			UPDATE todo_groups
+			SET position = $1
+			WHERE id = $2 AND user_id = $3
+			RETURNING `+groupColumns,
+			pos, id, userID,
+		), g)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		g.Role = RoleOwner
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// SetArchived archive / unarchive group (archive ซ้ำจะไม่เปลี่ยนเวลาเดิม)
+func (r *postgresRepo) SetArchived(ctx context.Context, id, userID int64, archived bool) (*TodoGroup, error) {
+	query := `
+		UPDATE todo_groups
+		SET archived_at = CASE WHEN $1 THEN COALESCE(archived_at, NOW()) ELSE NULL END
+		WHERE id = $2 AND user_id = $3 AND NOT is_inbox
+		RETURNING ` + groupColumns + `
+	`
+
+	var g TodoGroup
+	err := scanGroup(r.db.QueryRow(ctx, query, archived, id, userID), &g)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	g.Role = RoleOwner
+
+	return &g, nil
+}
+
+func (r *postgresRepo) UserTimezone(ctx context.Context, userID int64) (string, error) {
+	var tz string
+	err := r.db.QueryRow(ctx, `SELECT timezone FROM users WHERE id = $1`, userID).Scan(&tz)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "UTC", nil
+	}
+	return tz, err
+}
@@ -3,11 +3,26 @@ package todogroup
 import (
 	"context"
 	"strings"
+	"time"
+
+	"github.com/Nasaee/go-todo-backend/internal/auth"
+	"github.com/Nasaee/go-todo-backend/pkg/civil"
 )
 
 type TodoGroupService interface {
 	Create(ctx context.Context, userID int64, input CreateTodoGroupInput) (*TodoGroup, error)
-	GetAll(ctx context.Context, userID int64) ([]TodoGroup, error)
+	GetAll(ctx context.Context, userID int64, opts ListOptions) ([]TodoGroup, error)
+	GetTree(ctx context.Context, userID int64, opts ListOptions) ([]*TodoGroupNode, error)
+	Get(ctx context.Context, id, userID int64) (*TodoGroup, error)
+	Update(ctx context.Context, id, userID int64, input UpdateTodoGroupInput) (*TodoGroup, error)
+	Patch(ctx context.Context, id, userID int64, input PatchTodoGroupInput) (*TodoGroup, error)
+	// moveTodosTo = nil -> ลบ todos ในกลุ่มทิ้งด้วย, ไม่ nil -> ย้าย todos ไป group นั้นก่อนลบ
+	Delete(ctx context.Context, id, userID int64, moveTodosTo *int64) error
+	Move(ctx context.Context, id, userID int64, input MoveTodoGroupInput) (*TodoGroup, error)
+	Archive(ctx context.Context, id, userID int64) (*TodoGroup, error)
+	Unarchive(ctx context.Context, id, userID int64) (*TodoGroup, error)
+	// Clone copy group + todos ทั้งหมดเป็น group ใหม่ของ userID
+	Clone(ctx context.Context, id, userID int64, input CloneInput) (*TodoGroup, error)
 }
 
 type service struct {
@@ -24,10 +39,22 @@ func (s *service) Create(ctx context.Context, userID int64, input CreateTodoGrou
 		return nil, ErrEmptyName
 	}
 
+	// ไม่ส่งสีมา = ใช้ DefaultColor
+	color, err := NormalizeColor(input.Color)
+	if err != nil {
+		return nil, err
+	}
+
 	g := &TodoGroup{
-		Name:   name,
-		UserID: userID,
-		Color:  input.Color,
+		Name:     name,
+		UserID:   userID,
+		Color:    color,
+		ParentID: input.ParentID,
+		Role:     RoleOwner,
+	}
+	// สร้างผ่าน /workspaces/{wid}/todo-groups = ลง workspace นั้น ไม่งั้นลง personal workspace
+	if wid, ok := auth.WorkspaceIDFromContext(ctx); ok {
+		g.WorkspaceID = wid
 	}
 
 	if err := s.repo.Create(ctx, g); err != nil {
@@ -37,6 +64,233 @@ func (s *service) Create(ctx context.Context, userID int64, input CreateTodoGrou
 	return g, nil
 }
 
-func (s *service) GetAll(ctx context.Context, userID int64) ([]TodoGroup, error) {
-	return s.repo.GetAllByUser(ctx, userID)
+// requireRole ดึง group ที่ user มองเห็น แล้วเช็คว่า role ของ user ถึง min
+// ไม่ได้เป็นสมาชิก / อยู่คนละ workspace กับ route = ErrNotFound (ไม่บอกว่ามี group นี้อยู่), role ไม่พอ = ErrForbidden
+func requireRole(ctx context.Context, repo TodoGroupRepository, id, userID int64, min Role) (*TodoGroup, error) {
+	g, err := repo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !auth.InWorkspace(ctx, g.WorkspaceID) {
+		return nil, ErrNotFound
+	}
+	if !g.Role.AtLeast(min) {
+		return nil, ErrForbidden
+	}
+	return g, nil
+}
+
+// setClock ใส่วันนี้ / ตอนนี้ตาม timezone ของ user ให้ stats (ไม่ขอ stats ไม่ต้องดึง)
+func (s *service) setClock(ctx context.Context, userID int64, opts *ListOptions) error {
+	if !opts.WithStats {
+		return nil
+	}
+	tz, err := s.repo.UserTimezone(ctx, userID)
+	if err != nil {
+		return err
+	}
+	opts.Clock = clockIn(tz, time.Now())
+	return nil
+}
+
+// clockIn StatsClock ของเวลา now ตาม timezone tz
+func clockIn(tz string, now time.Time) StatsClock {
+	loc, err := time.LoadLocation(tz)
+	// "Local" คือ timezone ของ server ไม่ใช่ของ user
+	if err != nil || tz == "" || tz == "Local" {
+		tz, loc = "UTC", time.UTC
+	}
+	today := civil.DateOf(now.In(loc))
+	return StatsClock{Timezone: tz, Today: today, Now: now, TodayEnd: today.AddDays(1).StartIn(loc)}
+}
+
+func (s *service) GetAll(ctx context.Context, userID int64, opts ListOptions) ([]TodoGroup, error) {
+	opts.WorkspaceID = auth.WorkspaceScope(ctx)
+	if err := s.setClock(ctx, userID, &opts); err != nil {
+		return nil, err
+	}
+	groups, err := s.repo.GetAllByUser(ctx, userID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	detachHiddenParents(groups, userID)
+	return groups, nil
+}
+
+// GetTree คืน group เป็นต้นไม้ (ลูกเรียงตาม position เหมือน list ปกติ)
+func (s *service) GetTree(ctx context.Context, userID int64, opts ListOptions) ([]*TodoGroupNode, error) {
+	opts.WorkspaceID = auth.WorkspaceScope(ctx)
+	if err := s.setClock(ctx, userID, &opts); err != nil {
+		return nil, err
+	}
+	groups, err := s.repo.GetAllByUser(ctx, userID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	detachHiddenParents(groups, userID)
+	return BuildTree(groups), nil
+}
+
+func (s *service) Get(ctx context.Context, id, userID int64) (*TodoGroup, error) {
+	return requireRole(ctx, s.repo, id, userID, RoleViewer)
+}
+
+// แก้ไข / ลบ / ย้าย / archive group ทำได้เฉพาะ owner (editor จัดการได้แค่ todos ข้างใน)
+func (s *service) Update(ctx context.Context, id, userID int64, input UpdateTodoGroupInput) (*TodoGroup, error) {
+	if _, err := requireRole(ctx, s.repo, id, userID, RoleOwner); err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, ErrEmptyName
+	}
+
+	color, err := NormalizeColor(input.Color)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &TodoGroup{
+		ID:       id,
+		Name:     name,
+		UserID:   userID,
+		Color:    color,
+		ParentID: input.ParentID,
+	}
+
+	if err := s.repo.Update(ctx, g); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+func (s *service) Patch(ctx context.Context, id, userID int64, input PatchTodoGroupInput) (*TodoGroup, error) {
+	// ดึงข้อมูลเดิมมาก่อน แล้ว merge เฉพาะ field ที่ส่งมา
+	g, err := requireRole(ctx, s.repo, id, userID, RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return nil, ErrEmptyName
+		}
+		g.Name = name
+	}
+
+	// PATCH ส่ง color มาแปลว่าตั้งใจจะเปลี่ยน ค่าว่างจึงไม่ถือเป็น "ใช้ default"
+	if input.Color != nil {
+		if strings.TrimSpace(*input.Color) == "" {
+			return nil, ErrInvalidColor
+		}
+		color, err := NormalizeColor(*input.Color)
+		if err != nil {
+			return nil, err
+		}
+		g.Color = color
+	}
+
+	if input.ParentID.Set {
+		g.ParentID = input.ParentID.Ptr()
+	}
+
+	if err := s.repo.Update(ctx, g); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+func (s *service) Delete(ctx context.Context, id, userID int64, moveTodosTo *int64) error {
+	g, err := requireRole(ctx, s.repo, id, userID, RoleOwner)
+	if err != nil {
+		return err
+	}
+	if g.IsInbox {
+		return ErrInboxUndeletable
+	}
+
+	if moveTodosTo == nil {
+		return s.repo.Delete(ctx, id, userID)
+	}
+
+	return s.repo.MoveTodosAndDelete(ctx, id, *moveTodosTo, userID)
+}
+
+func (s *service) Move(ctx context.Context, id, userID int64, input MoveTodoGroupInput) (*TodoGroup, error) {
+	if _, err := requireRole(ctx, s.repo, id, userID, RoleOwner); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Move(ctx, id, userID, input.BeforeID, input.AfterID)
+}
+
+// Archive ซ่อน group (และ todos ในกลุ่ม) ออกจาก list ปกติ inbox archive ไม่ได้
+func (s *service) Archive(ctx context.Context, id, userID int64) (*TodoGroup, error) {
+	g, err := requireRole(ctx, s.repo, id, userID, RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+	if g.IsInbox {
+		return nil, ErrInboxArchive
+	}
+
+	return s.repo.SetArchived(ctx, id, userID, true)
+}
+
+func (s *service) Unarchive(ctx context.Context, id, userID int64) (*TodoGroup, error) {
+	if _, err := requireRole(ctx, s.repo, id, userID, RoleOwner); err != nil {
+		return nil, err
+	}
+
+	return s.repo.SetArchived(ctx, id, userID, false)
+}
+
+// Clone สมาชิกทุก role copy ได้ (ได้ group ใหม่เป็นของตัวเอง todos ยังไม่เสร็จทั้งหมด)
+// group ของตัวเอง copy ไปไว้ข้าง ๆ ตัวเดิม (workspace / parent เดิม)
+// group ที่คนอื่นแชร์มา copy ไป workspace ของ route หรือ personal workspace
+func (s *service) Clone(ctx context.Context, id, userID int64, input CloneInput) (*TodoGroup, error) {
+	src, err := requireRole(ctx, s.repo, id, userID, RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	name := src.Name + " (copy)"
+	if input.Name != nil {
+		if name = strings.TrimSpace(*input.Name); name == "" {
+			return nil, ErrEmptyName
+		}
+	}
+
+	seeds, err := s.repo.ListTodoSeeds(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if input.AnchorDate != nil {
+		ShiftDates(seeds, *input.AnchorDate)
+	}
+
+	g := &TodoGroup{
+		Name:   name,
+		UserID: userID,
+		Color:  src.Color,
+		Role:   RoleOwner,
+	}
+	if src.UserID == userID {
+		g.WorkspaceID = src.WorkspaceID
+		g.ParentID = src.ParentID
+	} else if wid, ok := auth.WorkspaceIDFromContext(ctx); ok {
+		g.WorkspaceID = wid
+	}
+
+	if err := s.repo.CreateWithTodos(ctx, g, seeds); err != nil {
+		return nil, err
+	}
+
+	return g, nil
 }
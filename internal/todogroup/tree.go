@@ -0,0 +1,41 @@
+package todogroup
+
+// BuildTree ประกอบ list ที่เรียงตาม position แล้ว ให้เป็นต้นไม้ (ลำดับในแต่ละชั้นคงเดิม)
+// group ที่ parent ไม่อยู่ใน list (เช่น parent ถูก archive แล้วไม่ได้ขอ include=archived)
+// จะถูกตัดออกไปทั้งกิ่ง เหมือนโฟลเดอร์ที่ถูกซ่อน
+func BuildTree(groups []TodoGroup) []*TodoGroupNode {
+	nodes := make(map[int64]*TodoGroupNode, len(groups))
+	for _, g := range groups {
+		nodes[g.ID] = &TodoGroupNode{TodoGroup: g, Children: []*TodoGroupNode{}}
+	}
+
+	roots := []*TodoGroupNode{}
+	for _, g := range groups {
+		n := nodes[g.ID]
+		if g.ParentID == nil {
+			roots = append(roots, n)
+			continue
+		}
+		if parent, ok := nodes[*g.ParentID]; ok {
+			parent.Children = append(parent.Children, n)
+		}
+	}
+
+	return roots
+}
+
+// detachHiddenParents group ที่คนอื่นแชร์มา parent อาจไม่ได้แชร์มาด้วย
+// ให้ถือว่าอยู่ชั้นบนสุดของคนที่ได้รับแชร์ (และไม่เผย id ของ group ที่เขามองไม่เห็น)
+func detachHiddenParents(groups []TodoGroup, userID int64) {
+	visible := make(map[int64]bool, len(groups))
+	for _, g := range groups {
+		visible[g.ID] = true
+	}
+
+	for i := range groups {
+		g := &groups[i]
+		if g.UserID != userID && g.ParentID != nil && !visible[*g.ParentID] {
+			g.ParentID = nil
+		}
+	}
+}
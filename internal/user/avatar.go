@@ -0,0 +1,92 @@
+package user
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/jpeg" // register decoder
+	"image/png"
+	"io"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // register decoder
+)
+
+var (
+	ErrAvatarTooLarge      = errors.New("avatar file is too large")
+	ErrAvatarUnsupported   = errors.New("avatar must be a PNG, JPEG or WebP image")
+	ErrAvatarBadDimensions = errors.New("avatar dimensions are out of range")
+)
+
+// format ที่ image.DecodeConfig คืนมา
+var avatarAllowedFormats = map[string]bool{"png": true, "jpeg": true, "webp": true}
+
+// ขนาดมาตรฐานที่ resize เก็บไว้ (สี่เหลี่ยมจัตุรัส, หน่วย px)
+var AvatarSizes = map[string]int{
+	"small":  64,
+	"medium": 128,
+	"large":  256,
+}
+
+type AvatarLimits struct {
+	MaxBytes     int64
+	MinDimension int
+	MaxDimension int
+}
+
+var DefaultAvatarLimits = AvatarLimits{
+	MaxBytes:     5 << 20, // 5 MB
+	MinDimension: 32,
+	MaxDimension: 4096,
+}
+
+// processAvatar ตรวจไฟล์แล้ว crop ตรงกลางให้เป็นสี่เหลี่ยมจัตุรัส + resize ตาม AvatarSizes
+// คืนค่าเป็น PNG ของแต่ละขนาด
+func processAvatar(r io.Reader, limits AvatarLimits) (map[string][]byte, error) {
+	// อ่านเกิน limit มา 1 byte เพื่อรู้ว่าไฟล์ใหญ่เกิน
+	data, err := io.ReadAll(io.LimitReader(r, limits.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limits.MaxBytes {
+		return nil, ErrAvatarTooLarge
+	}
+
+	// เช็คขนาดจาก header ก่อน decode ทั้งรูป กันไฟล์ขนาดเล็กแต่ pixel มหาศาล
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || !avatarAllowedFormats[format] {
+		return nil, ErrAvatarUnsupported
+	}
+	if cfg.Width < limits.MinDimension || cfg.Height < limits.MinDimension ||
+		cfg.Width > limits.MaxDimension || cfg.Height > limits.MaxDimension {
+		return nil, ErrAvatarBadDimensions
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrAvatarUnsupported
+	}
+
+	square := centerSquare(src.Bounds())
+
+	out := make(map[string][]byte, len(AvatarSizes))
+	for name, size := range AvatarSizes {
+		dst := image.NewRGBA(image.Rect(0, 0, size, size))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, square, draw.Over, nil)
+
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, dst); err != nil {
+			return nil, err
+		}
+		out[name] = buf.Bytes()
+	}
+
+	return out, nil
+}
+
+func centerSquare(b image.Rectangle) image.Rectangle {
+	side := min(b.Dx(), b.Dy())
+	x := b.Min.X + (b.Dx()-side)/2
+	y := b.Min.Y + (b.Dy()-side)/2
+	return image.Rect(x, y, x+side, y+side)
+}
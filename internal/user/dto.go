@@ -3,11 +3,21 @@ package user
 type UserDTO struct {
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
+	Timezone  string `json:"timezone"`
+	// key = ชื่อขนาดใน AvatarSizes (small / medium / large)
+	AvatarURLs map[string]string `json:"avatar_urls,omitempty"`
 }
 
 func ToUserDTO(u *User) *UserDTO {
 	return &UserDTO{
-		FirstName: u.FirstName,
-		LastName:  u.LastName,
+		FirstName:  u.FirstName,
+		LastName:   u.LastName,
+		Timezone:   u.Timezone,
+		AvatarURLs: u.AvatarURLs,
 	}
 }
+
+// ใช้กับ PUT /me/timezone
+type SetTimezoneInput struct {
+	Timezone string `json:"timezone"`
+}
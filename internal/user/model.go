@@ -7,7 +7,15 @@ type User struct {
 	FirstName string    `json:"first_name"`
 	LastName  string    `json:"last_name"`
 	Email     string    `json:"email"`
-	Password  string    `json:"-"` // hashed
+	Password  string    `json:"-"`        // hashed
+	Timezone  string    `json:"timezone"` // IANA เช่น Asia/Bangkok (ค่าเริ่มต้น UTC)
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// เวลาที่ account จะถูกลบจริง (nil = ไม่ได้ขอลบ)
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+
+	// prefix ของไฟล์ avatar ใน blob storage, AvatarURLs service เป็นคนเติมให้
+	AvatarKey  *string           `json:"-"`
+	AvatarURLs map[string]string `json:"avatar_urls,omitempty"`
 }
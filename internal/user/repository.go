@@ -2,13 +2,29 @@ package user
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/Nasaee/go-todo-backend/internal/db/postgres"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type UserRepository interface {
 	Create(ctx context.Context, u *User) error
 	FindByEmail(ctx context.Context, email string) (*User, error)
+	FindByID(ctx context.Context, id int64) (*User, error)
+	ScheduleDeletion(ctx context.Context, id int64, at time.Time) error
+	CancelDeletion(ctx context.Context, id int64) error
+	DeleteScheduled(ctx context.Context, now time.Time) ([]DeletedUser, error)
+	SetAvatarKey(ctx context.Context, id int64, key *string) error
+	SetTimezone(ctx context.Context, id int64, tz string) error
+}
+
+// DeletedUser คือข้อมูลที่ยังต้องใช้หลังลบแถวไปแล้ว (เช่นไปตามลบไฟล์ avatar)
+type DeletedUser struct {
+	ID        int64
+	AvatarKey *string
 }
 
 type repo struct {
@@ -19,28 +35,185 @@ func NewRepository(db *pgxpool.Pool) UserRepository {
 	return &repo{db: db}
 }
 
+// Create สร้าง user พร้อม personal workspace + inbox group ใน transaction เดียว
+// user ใหม่จะได้สร้าง todo ได้ทันทีโดยไม่ต้องสร้าง group เองก่อน
 func (r *repo) Create(ctx context.Context, u *User) error {
-	query := `
-		INSERT INTO users (first_name, last_name, email, password)
-		VALUES ($1, $2, LOWER($3), $4)
-		RETURNING id, created_at, updated_at
-	`
-	row := r.db.QueryRow(ctx, query, u.FirstName, u.LastName, u.Email, u.Password)
-	return row.Scan(&u.ID, &u.CreatedAt, &u.UpdatedAt)
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		query := `
+			INSERT INTO users (first_name, last_name, email, password)
+			VALUES ($1, $2, LOWER($3), $4)
+			RETURNING id, timezone, created_at, updated_at
+		`
+		row := tx.QueryRow(ctx, query, u.FirstName, u.LastName, u.Email, u.Password)
+		if err := row.Scan(&u.ID, &u.Timezone, &u.CreatedAt, &u.UpdatedAt); err != nil {
+			return err
+		}
+
+		// personal workspace (ชื่อเดียวกับที่ migration 00015 backfill ให้ user เก่า)
+		var workspaceID int64
+		if err := tx.QueryRow(ctx, `
+			INSERT INTO workspaces (name, kind, personal_user_id)
+			VALUES ('Personal', 'personal', $1)
+			RETURNING id
+		`, u.ID).Scan(&workspaceID); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO workspace_members (workspace_id, user_id, role)
+			VALUES ($1, $2, 'owner')
+		`, workspaceID, u.ID); err != nil {
+			return err
+		}
+
+		// ชื่อเดียวกับที่ migration 00008 ใช้ backfill ให้ user เก่า
+		// position 'a0' = key แรกของ list (rank.Initial)
+		_, err := tx.Exec(ctx, `
+			INSERT INTO todo_groups (name, user_id, is_inbox, position, workspace_id)
+			VALUES ('Inbox', $1, TRUE, 'a0', $2)
+		`, u.ID, workspaceID)
+		return err
+	})
 }
 
 func (r *repo) FindByEmail(ctx context.Context, email string) (*User, error) {
 	query := `
-		SELECT id, first_name, last_name, email, password, created_at, updated_at
+		SELECT id, first_name, last_name, email, password, timezone, created_at, updated_at, deletion_scheduled_at, avatar_key
 		FROM users
 		WHERE LOWER(email) = LOWER($1)
 		LIMIT 1
 	`
 	var u User
 	row := r.db.QueryRow(ctx, query, email)
-	err := row.Scan(&u.ID, &u.FirstName, &u.LastName, &u.Email, &u.Password, &u.CreatedAt, &u.UpdatedAt)
+	err := row.Scan(&u.ID, &u.FirstName, &u.LastName, &u.Email, &u.Password, &u.Timezone, &u.CreatedAt, &u.UpdatedAt, &u.DeletionScheduledAt, &u.AvatarKey)
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *repo) FindByID(ctx context.Context, id int64) (*User, error) {
+	query := `
+		SELECT id, first_name, last_name, email, password, timezone, created_at, updated_at, deletion_scheduled_at, avatar_key
+		FROM users
+		WHERE id = $1
+	`
+	var u User
+	row := r.db.QueryRow(ctx, query, id)
+	err := row.Scan(&u.ID, &u.FirstName, &u.LastName, &u.Email, &u.Password, &u.Timezone, &u.CreatedAt, &u.UpdatedAt, &u.DeletionScheduledAt, &u.AvatarKey)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
 		return nil, err
 	}
 	return &u, nil
 }
+
+func (r *repo) ScheduleDeletion(ctx context.Context, id int64, at time.Time) error {
+	query := `
+		UPDATE users
+		SET deletion_scheduled_at = $1
+		WHERE id = $2
+	`
+	cmdTag, err := r.db.Exec(ctx, query, at, id)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *repo) CancelDeletion(ctx context.Context, id int64) error {
+	query := `
+		UPDATE users
+		SET deletion_scheduled_at = NULL
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(ctx, query, id)
+	return err
+}
+
+// DeleteScheduled ลบ user ที่เลยเวลา grace period แล้ว ทีละคนใน transaction ของตัวเอง
+// ก่อนลบโอน group ใน team workspace ให้ owner ของ workspace ที่เหลือ (personal) หายตาม ON DELETE CASCADE
+func (r *repo) DeleteScheduled(ctx context.Context, now time.Time) ([]DeletedUser, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id
+		FROM users
+		WHERE deletion_scheduled_at IS NOT NULL
+		  AND deletion_scheduled_at <= $1
+		ORDER BY id
+	`, now)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted []DeletedUser
+	for _, id := range ids {
+		d := DeletedUser{ID: id}
+		err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+			// เช็คซ้ำ เผื่อ user login กลับมายกเลิกระหว่างทาง
+			if err := tx.QueryRow(ctx, `
+				SELECT avatar_key FROM users
+				WHERE id = $1 AND deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= $2
+				FOR UPDATE
+			`, id, now).Scan(&d.AvatarKey); err != nil {
+				return err
+			}
+
+			if err := postgres.LeaveTeamWorkspaces(ctx, tx, id); err != nil {
+				return err
+			}
+
+			_, err := tx.Exec(ctx, `DELETE FROM users WHERE id = $1`, id)
+			return err
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, d)
+	}
+
+	return deleted, nil
+}
+
+func (r *repo) SetAvatarKey(ctx context.Context, id int64, key *string) error {
+	query := `
+		UPDATE users
+		SET avatar_key = $1
+		WHERE id = $2
+	`
+	cmdTag, err := r.db.Exec(ctx, query, key, id)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *repo) SetTimezone(ctx context.Context, id int64, tz string) error {
+	query := `
+		UPDATE users
+		SET timezone = $1
+		WHERE id = $2
+	`
+	cmdTag, err := r.db.Exec(ctx, query, tz, id)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
@@ -1,28 +1,49 @@
 package user
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Nasaee/go-todo-backend/internal/storage"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
 var (
 	ErrEmailTaken      = errors.New("email already in use")
 	ErrInvalidPassword = errors.New("invalid email or password")
+	ErrUserNotFound    = errors.New("user not found")
+	ErrInvalidTimezone = errors.New("timezone must be an IANA name such as Asia/Bangkok")
 )
 
 type UserService interface {
 	Register(ctx context.Context, firstName, lastName, email, password string) (*User, error)
 	Authenticate(ctx context.Context, email, password string) (*User, error)
+	GetByID(ctx context.Context, id int64) (*User, error)
+	ScheduleDeletion(ctx context.Context, id int64, grace time.Duration) (time.Time, error)
+	PurgeScheduledDeletions(ctx context.Context) (int64, error)
+	SetAvatar(ctx context.Context, id int64, r io.Reader) (*User, error)
+	SetTimezone(ctx context.Context, id int64, tz string) (*User, error)
 }
 
 type service struct {
-	repo UserRepository
+	repo         UserRepository
+	blobs        storage.BlobStore
+	avatarLimits AvatarLimits
 }
 
-func NewService(repo UserRepository) UserService {
-	return &service{repo: repo}
+func NewService(repo UserRepository, blobs storage.BlobStore, avatarLimits AvatarLimits) UserService {
+	return &service{
+		repo:         repo,
+		blobs:        blobs,
+		avatarLimits: avatarLimits,
+	}
 }
 
 func (s *service) Register(ctx context.Context, firstName, lastName, email, password string) (*User, error) {
@@ -61,5 +82,131 @@ func (s *service) Authenticate(ctx context.Context, email, password string) (*Us
 		return nil, ErrInvalidPassword
 	}
 
+	// account ที่ขอลบไว้: ถ้ายังอยู่ใน grace period การ login = ยกเลิกการลบ
+	// ถ้าเลยเวลาแล้ว (แค่ job purge ยังไม่ได้รัน) ให้ถือว่าไม่มี account นี้แล้ว
+	if u.DeletionScheduledAt != nil {
+		if !time.Now().Before(*u.DeletionScheduledAt) {
+			return nil, ErrInvalidPassword
+		}
+
+		if err := s.repo.CancelDeletion(ctx, u.ID); err != nil {
+			return nil, err
+		}
+		u.DeletionScheduledAt = nil
+	}
+
+	s.fillAvatarURLs(u)
+	return u, nil
+}
+
+func (s *service) GetByID(ctx context.Context, id int64) (*User, error) {
+	u, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.fillAvatarURLs(u)
+	return u, nil
+}
+
+func (s *service) SetTimezone(ctx context.Context, id int64, tz string) (*User, error) {
+	tz = strings.TrimSpace(tz)
+	// "Local" คือ timezone ของ server ไม่ใช่ของ user
+	if tz == "" || tz == "Local" {
+		return nil, ErrInvalidTimezone
+	}
+	if _, err := time.LoadLocation(tz); err != nil {
+		return nil, ErrInvalidTimezone
+	}
+
+	if err := s.repo.SetTimezone(ctx, id, tz); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+func (s *service) ScheduleDeletion(ctx context.Context, id int64, grace time.Duration) (time.Time, error) {
+	at := time.Now().UTC().Add(grace)
+
+	if err := s.repo.ScheduleDeletion(ctx, id, at); err != nil {
+		return time.Time{}, err
+	}
+
+	return at, nil
+}
+
+func (s *service) PurgeScheduledDeletions(ctx context.Context) (int64, error) {
+	// error กลางทาง = คนที่ลบไปแล้วยังอยู่ใน deleted ตามไปลบ avatar ก่อนค่อยคืน error
+	deleted, err := s.repo.DeleteScheduled(ctx, time.Now().UTC())
+
+	// แถวใน DB หายแล้ว ตามไปลบไฟล์ avatar ด้วย (พลาดก็แค่ log ไว้)
+	for _, d := range deleted {
+		if d.AvatarKey != nil {
+			s.deleteAvatarFiles(ctx, *d.AvatarKey)
+		}
+	}
+
+	return int64(len(deleted)), err
+}
+
+// ===== Avatar =====
+
+// SetAvatar resize รูปเป็นทุกขนาดใน AvatarSizes แล้วเก็บลง blob storage
+// ใช้ key ใหม่ทุกครั้ง (version) เพื่อไม่ให้ติด cache ของ browser / CDN
+func (s *service) SetAvatar(ctx context.Context, id int64, r io.Reader) (*User, error) {
+	u, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := processAvatar(r, s.avatarLimits)
+	if err != nil {
+		return nil, err
+	}
+
+	key := "avatars/" + strconv.FormatInt(id, 10) + "/" + uuid.NewString()
+	for name, data := range images {
+		if err := s.blobs.Put(ctx, avatarFileKey(key, name), bytes.NewReader(data), "image/png"); err != nil {
+			s.deleteAvatarFiles(ctx, key)
+			return nil, err
+		}
+	}
+
+	if err := s.repo.SetAvatarKey(ctx, id, &key); err != nil {
+		s.deleteAvatarFiles(ctx, key)
+		return nil, err
+	}
+
+	if u.AvatarKey != nil {
+		s.deleteAvatarFiles(ctx, *u.AvatarKey)
+	}
+
+	u.AvatarKey = &key
+	s.fillAvatarURLs(u)
 	return u, nil
 }
+
+func (s *service) fillAvatarURLs(u *User) {
+	if u.AvatarKey == nil {
+		u.AvatarURLs = nil
+		return
+	}
+
+	u.AvatarURLs = make(map[string]string, len(AvatarSizes))
+	for name := range AvatarSizes {
+		u.AvatarURLs[name] = s.blobs.URL(avatarFileKey(*u.AvatarKey, name))
+	}
+}
+
+func (s *service) deleteAvatarFiles(ctx context.Context, key string) {
+	for name := range AvatarSizes {
+		if err := s.blobs.Delete(ctx, avatarFileKey(key, name)); err != nil {
+			slog.Error("failed to delete avatar file", "key", key, "size", name, "error", err)
+		}
+	}
+}
+
+func avatarFileKey(key, size string) string {
+	return key + "/" + size + ".png"
+}
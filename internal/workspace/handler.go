@@ -0,0 +1,387 @@
+package workspace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Nasaee/go-todo-backend/internal/auth"
+	"github.com/Nasaee/go-todo-backend/pkg/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// Middleware ใช้ครอบ route /workspaces/{wid}/...
+// เช็คว่า user เป็นสมาชิกของ workspace แล้วใส่ workspace id ลง context ต่อจาก user id
+// handler ข้างในไม่ต้องรู้จัก {wid} เอง service จะ scope ข้อมูลจาก context ให้
+func (h *Handler) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := auth.UserIDFromContext(r.Context())
+		if !ok {
+			http.Error(w, "user not found in context", http.StatusUnauthorized)
+			return
+		}
+
+		id, ok := idFromURL(w, r, "wid")
+		if !ok {
+			return
+		}
+
+		ws, err := h.svc.Get(r.Context(), id, userID)
+		if err != nil {
+			writeServiceError(w, err, "could not fetch workspace")
+			return
+		}
+
+		ctx := auth.ContextWithWorkspaceID(r.Context(), ws.ID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// POST /workspaces
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	var input CreateWorkspaceInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	ws, err := h.svc.Create(r.Context(), userID, input)
+	if err != nil {
+		writeServiceError(w, err, "could not create workspace")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, ws)
+}
+
+// GET /workspaces
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	workspaces, err := h.svc.List(r.Context(), userID)
+	if err != nil {
+		writeServiceError(w, err, "could not fetch workspaces")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, workspaces)
+}
+
+// GET /workspaces/{wid}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id, ok := idFromURL(w, r, "wid")
+	if !ok {
+		return
+	}
+
+	ws, err := h.svc.Get(r.Context(), id, userID)
+	if err != nil {
+		writeServiceError(w, err, "could not fetch workspace")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, ws)
+}
+
+// PATCH /workspaces/{wid}
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id, ok := idFromURL(w, r, "wid")
+	if !ok {
+		return
+	}
+
+	var input UpdateWorkspaceInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	ws, err := h.svc.Update(r.Context(), id, userID, input)
+	if err != nil {
+		writeServiceError(w, err, "could not update workspace")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, ws)
+}
+
+// DELETE /workspaces/{wid} (group / todos ข้างในถูกลบด้วย)
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id, ok := idFromURL(w, r, "wid")
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Delete(r.Context(), id, userID); err != nil {
+		writeServiceError(w, err, "could not delete workspace")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+// GET /workspaces/{wid}/members
+func (h *Handler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id, ok := idFromURL(w, r, "wid")
+	if !ok {
+		return
+	}
+
+	members, err := h.svc.ListMembers(r.Context(), id, userID)
+	if err != nil {
+		writeServiceError(w, err, "could not fetch members")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, members)
+}
+
+// PATCH /workspaces/{wid}/members/{userID}
+func (h *Handler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id, ok := idFromURL(w, r, "wid")
+	if !ok {
+		return
+	}
+
+	memberID, ok := idFromURL(w, r, "userID")
+	if !ok {
+		return
+	}
+
+	var input UpdateMemberInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	m, err := h.svc.UpdateMember(r.Context(), id, userID, memberID, input)
+	if err != nil {
+		writeServiceError(w, err, "could not update member")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, m)
+}
+
+// DELETE /workspaces/{wid}/members/{userID} (ใส่ id ตัวเอง = ออกจาก workspace)
+func (h *Handler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id, ok := idFromURL(w, r, "wid")
+	if !ok {
+		return
+	}
+
+	memberID, ok := idFromURL(w, r, "userID")
+	if !ok {
+		return
+	}
+
+	if err := h.svc.RemoveMember(r.Context(), id, userID, memberID); err != nil {
+		writeServiceError(w, err, "could not remove member")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+// POST /workspaces/{wid}/invitations
+func (h *Handler) Invite(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id, ok := idFromURL(w, r, "wid")
+	if !ok {
+		return
+	}
+
+	var input InviteInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	inv, err := h.svc.Invite(r.Context(), id, userID, input)
+	if err != nil {
+		writeServiceError(w, err, "could not create invitation")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, inv)
+}
+
+// GET /workspaces/{wid}/invitations (เฉพาะที่ยังค้างอยู่)
+func (h *Handler) ListInvitations(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id, ok := idFromURL(w, r, "wid")
+	if !ok {
+		return
+	}
+
+	invitations, err := h.svc.ListInvitations(r.Context(), id, userID)
+	if err != nil {
+		writeServiceError(w, err, "could not fetch invitations")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, invitations)
+}
+
+// DELETE /workspaces/{wid}/invitations/{invitationID}
+func (h *Handler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id, ok := idFromURL(w, r, "wid")
+	if !ok {
+		return
+	}
+
+	invitationID, ok := idFromURL(w, r, "invitationID")
+	if !ok {
+		return
+	}
+
+	if err := h.svc.RevokeInvitation(r.Context(), id, invitationID, userID); err != nil {
+		writeServiceError(w, err, "could not revoke invitation")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+// POST /workspaces/invitations/{invitationID}/accept
+func (h *Handler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	h.respondInvitation(w, r, true)
+}
+
+// POST /workspaces/invitations/{invitationID}/decline
+func (h *Handler) DeclineInvitation(w http.ResponseWriter, r *http.Request) {
+	h.respondInvitation(w, r, false)
+}
+
+func (h *Handler) respondInvitation(w http.ResponseWriter, r *http.Request, accept bool) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	invitationID, ok := idFromURL(w, r, "invitationID")
+	if !ok {
+		return
+	}
+
+	var input RespondInvitationInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	var (
+		inv *Invitation
+		err error
+	)
+	if accept {
+		inv, err = h.svc.AcceptInvitation(r.Context(), invitationID, userID, input)
+	} else {
+		inv, err = h.svc.DeclineInvitation(r.Context(), invitationID, userID, input)
+	}
+	if err != nil {
+		writeServiceError(w, err, "could not respond to invitation")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, inv)
+}
+
+func idFromURL(w http.ResponseWriter, r *http.Request, param string) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, param), 10, 64)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid "+param)
+		return 0, false
+	}
+	return id, true
+}
+
+func writeServiceError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrMemberNotFound), errors.Is(err, ErrInvitationNotFound):
+		utils.WriteError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrEmptyName), errors.Is(err, ErrInvalidRole), errors.Is(err, ErrInvalidEmail):
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrForbidden):
+		utils.WriteError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, ErrPersonalWorkspace), errors.Is(err, ErrAlreadyMember), errors.Is(err, ErrAlreadyInvited),
+		errors.Is(err, ErrOwnerMember):
+		utils.WriteError(w, http.StatusConflict, err.Error())
+	default:
+		fmt.Println(err)
+		utils.WriteError(w, http.StatusInternalServerError, fallback)
+	}
+}
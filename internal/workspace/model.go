@@ -0,0 +1,133 @@
+package workspace
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound           = errors.New("workspace not found")
+	ErrEmptyName          = errors.New("workspace name is required")
+	ErrForbidden          = errors.New("you do not have permission to do this in this workspace")
+	ErrPersonalWorkspace  = errors.New("personal workspace cannot be deleted or have other members")
+	ErrInvalidRole        = errors.New("role must be admin, member or viewer")
+	ErrInvalidEmail       = errors.New("a valid email is required")
+	ErrAlreadyMember      = errors.New("user is already a member of this workspace")
+	ErrAlreadyInvited     = errors.New("this email already has a pending invitation to this workspace")
+	ErrInvitationNotFound = errors.New("invitation not found")
+	ErrMemberNotFound     = errors.New("member not found")
+	ErrOwnerMember        = errors.New("the owner cannot be removed or change role")
+)
+
+const (
+	KindPersonal = "personal"
+	KindTeam     = "team"
+)
+
+// Role สิทธิ์ของสมาชิกใน workspace
+//   - owner  = ลบ workspace ได้
+//   - admin  = แก้ชื่อ / จัดการสมาชิก
+//   - member = เห็นและแก้ todos ในทุก group ของ workspace
+//   - viewer = ดูได้อย่างเดียว
+type Role string
+
+const (
+	RoleOwner  Role = "owner"
+	RoleAdmin  Role = "admin"
+	RoleMember Role = "member"
+	RoleViewer Role = "viewer"
+)
+
+func (r Role) level() int {
+	switch r {
+	case RoleOwner:
+		return 4
+	case RoleAdmin:
+		return 3
+	case RoleMember:
+		return 2
+	case RoleViewer:
+		return 1
+	}
+	return 0
+}
+
+// AtLeast = role นี้มีสิทธิ์เท่ากับหรือมากกว่า min ("" = ไม่ได้เป็นสมาชิก ไม่ผ่านเสมอ)
+func (r Role) AtLeast(min Role) bool {
+	return r.level() > 0 && r.level() >= min.level()
+}
+
+// parseMemberRole รับเฉพาะ role ที่ให้คนอื่นได้ (owner โอนให้กันไม่ได้)
+func parseMemberRole(s string) (Role, error) {
+	switch r := Role(strings.ToLower(strings.TrimSpace(s))); r {
+	case RoleAdmin, RoleMember, RoleViewer:
+		return r, nil
+	}
+	return "", ErrInvalidRole
+}
+
+type Workspace struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// role ของ user ที่เรียก API ใน workspace นี้
+	Role Role `json:"role"`
+}
+
+type Member struct {
+	UserID    int64     `json:"user_id"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Email     string    `json:"email"`
+	Role      Role      `json:"role"`
+	JoinedAt  time.Time `json:"joined_at"`
+}
+
+// ใช้กับ POST /workspaces (สร้างได้แค่ team, personal สร้างให้ตอน register)
+type CreateWorkspaceInput struct {
+	Name string `json:"name"`
+}
+
+// ใช้กับ PATCH /workspaces/{wid}
+type UpdateWorkspaceInput struct {
+	Name string `json:"name"`
+}
+
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+	InvitationRevoked  = "revoked"
+)
+
+type Invitation struct {
+	ID            int64      `json:"id"`
+	WorkspaceID   int64      `json:"workspace_id"`
+	WorkspaceName string     `json:"workspace_name"`
+	Email         string     `json:"email"`
+	Role          Role       `json:"role"`
+	InvitedBy     int64      `json:"invited_by"`
+	Status        string     `json:"status"`
+	CreatedAt     time.Time  `json:"created_at"`
+	RespondedAt   *time.Time `json:"responded_at,omitempty"`
+}
+
+// ใช้กับ POST /workspaces/{wid}/invitations (ยังไม่มี account ก็เชิญได้ เข้ามาได้เมื่อ accept ด้วย token)
+type InviteInput struct {
+	Email string `json:"email"`
+	Role  string `json:"role"` // admin / member / viewer (ไม่ส่ง = member)
+}
+
+// ใช้กับ POST /workspaces/invitations/{invitationID}/accept และ /decline
+type RespondInvitationInput struct {
+	Token string `json:"token"` // token ที่ส่งไปทาง email ตอนเชิญ
+}
+
+// ใช้กับ PATCH /workspaces/{wid}/members/{userID}
+type UpdateMemberInput struct {
+	Role string `json:"role"`
+}
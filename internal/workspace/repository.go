@@ -0,0 +1,393 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Nasaee/go-todo-backend/internal/db/postgres"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// column ของ workspace + role ของ user (ลำดับต้องตรงกับ workspaceDest)
+const workspaceColumns = `w.id, w.name, w.kind, w.created_at, w.updated_at, m.role`
+
+func workspaceDest(w *Workspace) []any {
+	return []any{
+		&w.ID,
+		&w.Name,
+		&w.Kind,
+		&w.CreatedAt,
+		&w.UpdatedAt,
+		&w.Role,
+	}
+}
+
+const memberColumns = `u.id, u.first_name, u.last_name, u.email, m.role, m.created_at`
+
+func memberDest(m *Member) []any {
+	return []any{
+		&m.UserID,
+		&m.FirstName,
+		&m.LastName,
+		&m.Email,
+		&m.Role,
+		&m.JoinedAt,
+	}
+}
+
+// column ของคำเชิญ (ลำดับต้องตรงกับ invitationDest)
+const invitationColumns = `i.id, i.workspace_id, w.name, i.email, i.role, i.invited_by, i.status, i.created_at, i.responded_at`
+
+func invitationDest(inv *Invitation) []any {
+	return []any{
+		&inv.ID,
+		&inv.WorkspaceID,
+		&inv.WorkspaceName,
+		&inv.Email,
+		&inv.Role,
+		&inv.InvitedBy,
+		&inv.Status,
+		&inv.CreatedAt,
+		&inv.RespondedAt,
+	}
+}
+
+type Repository interface {
+	// Create สร้าง team workspace ให้ ownerID เป็น owner
+	Create(ctx context.Context, w *Workspace, ownerID int64) error
+	ListByUser(ctx context.Context, userID int64) ([]Workspace, error)
+	// GetByID คืน workspace ที่ user เป็นสมาชิก พร้อม Role ของ user
+	GetByID(ctx context.Context, id, userID int64) (*Workspace, error)
+	Rename(ctx context.Context, id int64, name string) error
+	// Delete ลบได้เฉพาะ team workspace (group / todos ข้างในหายตาม)
+	Delete(ctx context.Context, id int64) error
+
+	ListMembers(ctx context.Context, id int64) ([]Member, error)
+	UpdateMemberRole(ctx context.Context, id, userID int64, role Role) (*Member, error)
+	RemoveMember(ctx context.Context, id, userID int64) error
+
+	// CreateInvitation เก็บแค่ hash ของ token ตัวจริงส่งไปทาง email เท่านั้น
+	CreateInvitation(ctx context.Context, inv *Invitation, tokenHash []byte) error
+	ListInvitations(ctx context.Context, id int64) ([]Invitation, error)
+	RevokeInvitation(ctx context.Context, id, invitationID int64) error
+	// RespondInvitation ตอบคำเชิญที่ token ตรง (ถือ token = เข้าถึง email ที่ถูกเชิญได้)
+	// accept = เพิ่ม userID เป็นสมาชิกใน transaction เดียวกัน
+	RespondInvitation(ctx context.Context, invitationID int64, tokenHash []byte, userID int64, accept bool) (*Invitation, error)
+}
+
+type postgresRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Repository {
+	return &postgresRepo{db: db}
+}
+
+func (r *postgresRepo) Create(ctx context.Context, w *Workspace, ownerID int64) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `
+			INSERT INTO workspaces (name, kind)
+			VALUES ($1, 'team')
+			RETURNING id, kind, created_at, updated_at
+		`, w.Name).Scan(&w.ID, &w.Kind, &w.CreatedAt, &w.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		w.Role = RoleOwner
+		_, err = tx.Exec(ctx, `
+			INSERT INTO workspace_members (workspace_id, user_id, role)
+			VALUES ($1, $2, 'owner')
+		`, w.ID, ownerID)
+		return err
+	})
+}
+
+// ListByUser personal workspace ขึ้นก่อนเสมอ
+func (r *postgresRepo) ListByUser(ctx context.Context, userID int64) ([]Workspace, error) {
+	query := `
+		SELECT ` + workspaceColumns + `
+		FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.id AND m.user_id = $1
+		ORDER BY w.kind = 'personal' DESC, w.name, w.id
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	workspaces := []Workspace{}
+	for rows.Next() {
+		var w Workspace
+		if err := rows.Scan(workspaceDest(&w)...); err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, w)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return workspaces, nil
+}
+
+func (r *postgresRepo) GetByID(ctx context.Context, id, userID int64) (*Workspace, error) {
+	query := `
+		SELECT ` + workspaceColumns + `
+		FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.id AND m.user_id = $2
+		WHERE w.id = $1
+	`
+
+	var w Workspace
+	if err := r.db.QueryRow(ctx, query, id, userID).Scan(workspaceDest(&w)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+func (r *postgresRepo) Rename(ctx context.Context, id int64, name string) error {
+	cmdTag, err := r.db.Exec(ctx, `UPDATE workspaces SET name = $1 WHERE id = $2`, name, id)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *postgresRepo) Delete(ctx context.Context, id int64) error {
+	cmdTag, err := r.db.Exec(ctx, `DELETE FROM workspaces WHERE id = $1 AND kind = 'team'`, id)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *postgresRepo) ListMembers(ctx context.Context, id int64) ([]Member, error) {
+	query := `
+		SELECT ` + memberColumns + `
+		FROM workspace_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.workspace_id = $1
+		ORDER BY m.role = 'owner' DESC, m.created_at, u.id
+	`
+
+	rows, err := r.db.Query(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []Member{}
+	for rows.Next() {
+		var m Member
+		if err := rows.Scan(memberDest(&m)...); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return members, nil
+}
+
+// UpdateMemberRole แก้ role ของสมาชิกที่ไม่ใช่ owner
+func (r *postgresRepo) UpdateMemberRole(ctx context.Context, id, userID int64, role Role) (*Member, error) {
+	query := `
+		WITH updated AS (
+			UPDATE workspace_members
+			SET role = $3
+			WHERE workspace_id = $1 AND user_id = $2 AND role <> 'owner'
+			RETURNING user_id, role, created_at
+		)
+		SELECT ` + memberColumns + `
+		FROM updated m
+		JOIN users u ON u.id = m.user_id
+	`
+
+	var m Member
+	if err := r.db.QueryRow(ctx, query, id, userID, role).Scan(memberDest(&m)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMemberNotFound
+		}
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// RemoveMember เอาสมาชิกที่ไม่ใช่ owner ออก
+// group ที่คนนั้นสร้างไว้ (+ todos) โอนให้ owner ของ workspace และสิทธิ์ที่แชร์ให้ตรง ๆ ใน workspace นี้หายไปด้วย
+func (r *postgresRepo) RemoveMember(ctx context.Context, id, userID int64) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		cmdTag, err := tx.Exec(ctx, `
+			DELETE FROM workspace_members
+			WHERE workspace_id = $1 AND user_id = $2 AND role <> 'owner'
+		`, id, userID)
+		if err != nil {
+			return err
+		}
+		if cmdTag.RowsAffected() == 0 {
+			return ErrMemberNotFound
+		}
+
+		return postgres.TransferWorkspaceGroups(ctx, tx, id, userID)
+	})
+}
+
+func (r *postgresRepo) CreateInvitation(ctx context.Context, inv *Invitation, tokenHash []byte) error {
+	// email ที่เป็นสมาชิกอยู่แล้วไม่ต้องเชิญซ้ำ
+	var isMember bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM workspace_members m
+			JOIN users u ON u.id = m.user_id
+			WHERE m.workspace_id = $1 AND LOWER(u.email) = LOWER($2)
+		)
+	`, inv.WorkspaceID, inv.Email).Scan(&isMember)
+	if err != nil {
+		return err
+	}
+	if isMember {
+		return ErrAlreadyMember
+	}
+
+	query := `
+		WITH inserted AS (
+			INSERT INTO workspace_invitations (workspace_id, email, role, invited_by, token_hash)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING *
+		)
+		SELECT ` + invitationColumns + `
+		FROM inserted i
+		JOIN workspaces w ON w.id = i.workspace_id
+	`
+
+	err = r.db.QueryRow(ctx, query, inv.WorkspaceID, inv.Email, inv.Role, inv.InvitedBy, tokenHash).Scan(invitationDest(inv)...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_workspace_invitations_pending" {
+			return ErrAlreadyInvited
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *postgresRepo) ListInvitations(ctx context.Context, id int64) ([]Invitation, error) {
+	query := `
+		SELECT ` + invitationColumns + `
+		FROM workspace_invitations i
+		JOIN workspaces w ON w.id = i.workspace_id
+		WHERE i.workspace_id = $1 AND i.status = 'pending'
+		ORDER BY i.created_at, i.id
+	`
+
+	rows, err := r.db.Query(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []Invitation{}
+	for rows.Next() {
+		var inv Invitation
+		if err := rows.Scan(invitationDest(&inv)...); err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, inv)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return invitations, nil
+}
+
+func (r *postgresRepo) RevokeInvitation(ctx context.Context, id, invitationID int64) error {
+	query := `
+		UPDATE workspace_invitations
+		SET status = 'revoked', responded_at = NOW()
+		WHERE id = $1 AND workspace_id = $2 AND status = 'pending'
+	`
+
+	cmdTag, err := r.db.Exec(ctx, query, invitationID, id)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrInvitationNotFound
+	}
+
+	return nil
+}
+
+func (r *postgresRepo) RespondInvitation(ctx context.Context, invitationID int64, tokenHash []byte, userID int64, accept bool) (*Invitation, error) {
+	status := InvitationDeclined
+	if accept {
+		status = InvitationAccepted
+	}
+
+	query := `
+		WITH updated AS (
+			UPDATE workspace_invitations
+			SET status = $3, responded_at = NOW()
+			WHERE id = $1
+			  AND status = 'pending'
+			  AND token_hash = $2
+			RETURNING *
+		)
+		SELECT ` + invitationColumns + `
+		FROM updated i
+		JOIN workspaces w ON w.id = i.workspace_id
+	`
+
+	var inv Invitation
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, query, invitationID, tokenHash, status).Scan(invitationDest(&inv)...); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrInvitationNotFound
+			}
+			return err
+		}
+
+		if !accept {
+			return nil
+		}
+
+		// เป็นสมาชิกอยู่แล้วก็ไม่ต้องทำอะไร role เดิมไม่เปลี่ยน
+		_, err := tx.Exec(ctx, `
+			INSERT INTO workspace_members (workspace_id, user_id, role)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (workspace_id, user_id) DO NOTHING
+		`, inv.WorkspaceID, userID, inv.Role)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &inv, nil
+}
@@ -0,0 +1,250 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"strings"
+
+	"github.com/Nasaee/go-todo-backend/internal/event"
+	"github.com/Nasaee/go-todo-backend/pkg/invitetoken"
+)
+
+// EventInvitationCreated ส่ง token ของคำเชิญให้ worker ส่ง email ไปหาคนที่ถูกเชิญ (เหมือนคำเชิญเข้า group)
+const EventInvitationCreated = "workspace.invitation_created"
+
+type InvitationCreatedEvent struct {
+	InvitationID  int64  `json:"invitation_id"`
+	WorkspaceID   int64  `json:"workspace_id"`
+	WorkspaceName string `json:"workspace_name"`
+	Email         string `json:"email"`
+	Role          Role   `json:"role"`
+	InvitedBy     int64  `json:"invited_by"`
+	Token         string `json:"token"`
+}
+
+type Service interface {
+	Create(ctx context.Context, userID int64, input CreateWorkspaceInput) (*Workspace, error)
+	List(ctx context.Context, userID int64) ([]Workspace, error)
+	Get(ctx context.Context, id, userID int64) (*Workspace, error)
+	Update(ctx context.Context, id, userID int64, input UpdateWorkspaceInput) (*Workspace, error)
+	Delete(ctx context.Context, id, userID int64) error
+
+	ListMembers(ctx context.Context, id, userID int64) ([]Member, error)
+	UpdateMember(ctx context.Context, id, userID, memberID int64, input UpdateMemberInput) (*Member, error)
+	// RemoveMember admin เอาคนอื่นออก หรือสมาชิกออกจาก workspace เอง (memberID = userID)
+	RemoveMember(ctx context.Context, id, userID, memberID int64) error
+
+	// เข้า workspace ได้ทางคำเชิญเท่านั้น คนถูกเชิญต้อง accept ด้วย token จาก email
+	Invite(ctx context.Context, id, userID int64, input InviteInput) (*Invitation, error)
+	ListInvitations(ctx context.Context, id, userID int64) ([]Invitation, error)
+	RevokeInvitation(ctx context.Context, id, invitationID, userID int64) error
+	AcceptInvitation(ctx context.Context, invitationID, userID int64, input RespondInvitationInput) (*Invitation, error)
+	DeclineInvitation(ctx context.Context, invitationID, userID int64, input RespondInvitationInput) (*Invitation, error)
+}
+
+type service struct {
+	repo   Repository
+	events event.Publisher
+}
+
+func NewService(repo Repository, events event.Publisher) Service {
+	return &service{repo: repo, events: events}
+}
+
+// requireRole ไม่ได้เป็นสมาชิก = ErrNotFound, role ไม่พอ = ErrForbidden
+func (s *service) requireRole(ctx context.Context, id, userID int64, min Role) (*Workspace, error) {
+	w, err := s.repo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !w.Role.AtLeast(min) {
+		return nil, ErrForbidden
+	}
+	return w, nil
+}
+
+func (s *service) Create(ctx context.Context, userID int64, input CreateWorkspaceInput) (*Workspace, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, ErrEmptyName
+	}
+
+	w := &Workspace{Name: name}
+	if err := s.repo.Create(ctx, w, userID); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (s *service) List(ctx context.Context, userID int64) ([]Workspace, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+func (s *service) Get(ctx context.Context, id, userID int64) (*Workspace, error) {
+	return s.repo.GetByID(ctx, id, userID)
+}
+
+func (s *service) Update(ctx context.Context, id, userID int64, input UpdateWorkspaceInput) (*Workspace, error) {
+	w, err := s.requireRole(ctx, id, userID, RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, ErrEmptyName
+	}
+
+	if err := s.repo.Rename(ctx, id, name); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(ctx, w.ID, userID)
+}
+
+func (s *service) Delete(ctx context.Context, id, userID int64) error {
+	w, err := s.requireRole(ctx, id, userID, RoleOwner)
+	if err != nil {
+		return err
+	}
+	if w.Kind == KindPersonal {
+		return ErrPersonalWorkspace
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+// สมาชิกทุก role ดูรายชื่อกันเองได้
+func (s *service) ListMembers(ctx context.Context, id, userID int64) ([]Member, error) {
+	if _, err := s.requireRole(ctx, id, userID, RoleViewer); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListMembers(ctx, id)
+}
+
+func (s *service) UpdateMember(ctx context.Context, id, userID, memberID int64, input UpdateMemberInput) (*Member, error) {
+	if _, err := s.requireRole(ctx, id, userID, RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	role, err := parseMemberRole(input.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpdateMemberRole(ctx, id, memberID, role)
+}
+
+func (s *service) RemoveMember(ctx context.Context, id, userID, memberID int64) error {
+	min := RoleAdmin
+	if memberID == userID {
+		min = RoleViewer
+	}
+
+	w, err := s.requireRole(ctx, id, userID, min)
+	if err != nil {
+		return err
+	}
+	// owner ออกจาก workspace ตัวเองไม่ได้ (personal ก็มีแต่ owner)
+	if memberID == userID && w.Role == RoleOwner {
+		return ErrOwnerMember
+	}
+
+	return s.repo.RemoveMember(ctx, id, memberID)
+}
+
+// Invite สร้างคำเชิญถึง email (ยังไม่มี account ก็ได้) แล้วส่ง token ไปทาง email ผ่าน event
+// เข้าแล้วเห็น / แก้ todos ทุก group ใน workspace จึงต้องให้เจ้าของ email ยอมรับเองเท่านั้น
+func (s *service) Invite(ctx context.Context, id, userID int64, input InviteInput) (*Invitation, error) {
+	w, err := s.requireRole(ctx, id, userID, RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if w.Kind == KindPersonal {
+		return nil, ErrPersonalWorkspace
+	}
+
+	addr, err := mail.ParseAddress(strings.TrimSpace(input.Email))
+	if err != nil || addr.Name != "" {
+		return nil, ErrInvalidEmail
+	}
+
+	role := RoleMember
+	if strings.TrimSpace(input.Role) != "" {
+		if role, err = parseMemberRole(input.Role); err != nil {
+			return nil, err
+		}
+	}
+
+	inv := &Invitation{
+		WorkspaceID: id,
+		Email:       strings.ToLower(addr.Address),
+		Role:        role,
+		InvitedBy:   userID,
+	}
+
+	token, err := invitetoken.New()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateInvitation(ctx, inv, invitetoken.Hash(token)); err != nil {
+		return nil, err
+	}
+
+	// ส่ง email ไม่ได้ = ไม่มีใครรับคำเชิญนี้ได้ ยกเลิกทิ้งไม่ให้ค้างกันเชิญซ้ำ
+	e := event.New(EventInvitationCreated, InvitationCreatedEvent{
+		InvitationID:  inv.ID,
+		WorkspaceID:   inv.WorkspaceID,
+		WorkspaceName: inv.WorkspaceName,
+		Email:         inv.Email,
+		Role:          inv.Role,
+		InvitedBy:     inv.InvitedBy,
+		Token:         token,
+	})
+	if err := s.events.Publish(ctx, e); err != nil {
+		if revokeErr := s.repo.RevokeInvitation(ctx, id, inv.ID); revokeErr != nil {
+			return nil, errors.Join(err, revokeErr)
+		}
+		return nil, err
+	}
+
+	return inv, nil
+}
+
+func (s *service) ListInvitations(ctx context.Context, id, userID int64) ([]Invitation, error) {
+	if _, err := s.requireRole(ctx, id, userID, RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListInvitations(ctx, id)
+}
+
+func (s *service) RevokeInvitation(ctx context.Context, id, invitationID, userID int64) error {
+	if _, err := s.requireRole(ctx, id, userID, RoleAdmin); err != nil {
+		return err
+	}
+
+	return s.repo.RevokeInvitation(ctx, id, invitationID)
+}
+
+func (s *service) AcceptInvitation(ctx context.Context, invitationID, userID int64, input RespondInvitationInput) (*Invitation, error) {
+	return s.respondInvitation(ctx, invitationID, userID, input, true)
+}
+
+func (s *service) DeclineInvitation(ctx context.Context, invitationID, userID int64, input RespondInvitationInput) (*Invitation, error) {
+	return s.respondInvitation(ctx, invitationID, userID, input, false)
+}
+
+// token ผิด / ไม่ส่งมา ตอบเหมือนไม่มีคำเชิญ (ไม่บอกว่า id นี้มีอยู่จริง)
+func (s *service) respondInvitation(ctx context.Context, invitationID, userID int64, input RespondInvitationInput, accept bool) (*Invitation, error) {
+	token := strings.TrimSpace(input.Token)
+	if token == "" {
+		return nil, ErrInvitationNotFound
+	}
+
+	return s.repo.RespondInvitation(ctx, invitationID, invitetoken.Hash(token), userID, accept)
+}
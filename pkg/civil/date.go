@@ -0,0 +1,100 @@
+// Package civil วันที่แบบไม่มีเวลา / timezone (เช่น date_start ของ todo ทั้งวัน)
+//
+// JSON เป็น "YYYY-MM-DD" ส่วน DB ใช้กับ column DATE ได้ตรง ๆ (sql.Scanner / driver.Valuer)
+package civil
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidDate = errors.New("date must be YYYY-MM-DD")
+
+// Date เก็บเป็นเที่ยงคืน UTC ของวันนั้นเสมอ (เทียบ / ลบกันได้ตรง ๆ)
+type Date struct {
+	time.Time
+}
+
+// DateOf วันที่ของ t ตาม location ของ t เอง
+func DateOf(t time.Time) Date {
+	return Date{time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+// Today วันนี้ตาม timezone loc
+func Today(loc *time.Location) Date {
+	return DateOf(time.Now().In(loc))
+}
+
+// ParseDate รับ YYYY-MM-DD
+// RFC3339 ที่เป็นเที่ยงคืน UTC พอดียังรับอยู่ (รูปแบบที่ API เคยตอบกลับไป client เก่าส่งกลับมาได้)
+// แต่ถ้ามีเวลาปนมาถือว่าผิด ไม่ตัดทิ้งเงียบ ๆ
+func ParseDate(s string) (Date, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return Date{t}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return Date{}, ErrInvalidDate
+	}
+	if d := DateOf(t.UTC()); d.Equal(t) {
+		return d, nil
+	}
+	return Date{}, fmt.Errorf("%w (got a time of day; use start_at / end_at for timed todos)", ErrInvalidDate)
+}
+
+// AddDays บวก / ลบวัน
+func (d Date) AddDays(n int) Date {
+	return Date{d.Time.AddDate(0, 0, n)}
+}
+
+// DaysSince จำนวนวันจาก o ถึง d (d ก่อน o = ติดลบ)
+func (d Date) DaysSince(o Date) int {
+	return int(d.Time.Sub(o.Time).Hours() / 24)
+}
+
+// StartIn เที่ยงคืนของวันนี้ตาม timezone loc (ใช้ทำช่วงเวลาของวันนั้นใน loc)
+func (d Date) StartIn(loc *time.Location) time.Time {
+	return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, loc)
+}
+
+func (d Date) String() string {
+	return d.Format(time.DateOnly)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return ErrInvalidDate
+	}
+	v, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// Value ส่งเข้า DB เป็น time.Time (pgx แปลงเป็น DATE ให้)
+func (d Date) Value() (driver.Value, error) {
+	return d.Time, nil
+}
+
+func (d *Date) Scan(src any) error {
+	switch v := src.(type) {
+	case time.Time:
+		*d = DateOf(v)
+		return nil
+	case nil:
+		*d = Date{}
+		return nil
+	}
+	return fmt.Errorf("civil: cannot scan %T into Date", src)
+}
@@ -0,0 +1,29 @@
+// Package invitetoken token ของคำเชิญ (group / workspace) ที่ส่งไปทาง email
+//
+// email ของ account ไม่ได้ยืนยัน จึงจับคู่คำเชิญด้วย email ไม่ได้ คนที่รับคำเชิญได้ต้องถือ token นี้
+// DB เก็บแค่ hash ตัว token จริงส่งออกไปทาง email ครั้งเดียว
+package invitetoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// size = ความยาว token ก่อน encode (256 bit เดาไม่ได้)
+const size = 32
+
+// New สร้าง token ใหม่ (base64 แบบใช้ใน URL ได้)
+func New() (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Hash ค่าที่เก็บใน DB และใช้ค้นคำเชิญตอน accept / decline
+func Hash(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
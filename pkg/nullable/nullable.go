@@ -0,0 +1,49 @@
+// Package nullable แยก 3 สถานะของ field ใน JSON body ที่ pointer ธรรมดาแยกไม่ได้
+//   - ไม่ส่ง key มาเลย      -> Set = false
+//   - ส่งมาเป็น null        -> Set = true, Valid = false
+//   - ส่งค่ามา              -> Set = true, Valid = true, Value = ค่านั้น
+//
+// ใช้กับ PATCH ที่ต้องการ "ล้างค่า" field ได้ (ส่ง null) โดยไม่ปนกับ "ไม่แก้"
+package nullable
+
+import "encoding/json"
+
+type Field[T any] struct {
+	Set   bool
+	Valid bool
+	Value T
+}
+
+// UnmarshalJSON ถูกเรียกเฉพาะตอนมี key ใน JSON เท่านั้น จึงเซ็ต Set ได้เลย
+func (f *Field[T]) UnmarshalJSON(data []byte) error {
+	f.Set = true
+
+	if string(data) == "null" {
+		f.Valid = false
+		var zero T
+		f.Value = zero
+		return nil
+	}
+
+	if err := json.Unmarshal(data, &f.Value); err != nil {
+		return err
+	}
+	f.Valid = true
+	return nil
+}
+
+func (f Field[T]) MarshalJSON() ([]byte, error) {
+	if !f.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(f.Value)
+}
+
+// Ptr คืน nil ถ้าเป็น null / ไม่ได้ส่งมา
+func (f Field[T]) Ptr() *T {
+	if !f.Valid {
+		return nil
+	}
+	v := f.Value
+	return &v
+}
@@ -0,0 +1,305 @@
+// Package rank สร้าง key สำหรับเรียงลำดับแบบ fractional indexing
+//
+// key เป็น string ที่เรียงตาม byte order (Postgres ต้องใช้ COLLATE "C")
+// อยากแทรกระหว่าง a กับ b ก็แค่สร้าง key ใหม่ที่อยู่ระหว่างสองตัวนั้น
+// ย้ายของหนึ่งชิ้นจึงแก้แค่แถวเดียว ไม่ต้อง renumber ทั้ง list
+//
+// รูปแบบ key = integer part + fraction part (อิงจาก https://github.com/rocicorp/fractional-indexing)
+//   - ตัวแรกบอกความยาวของ integer part: 'a' = 1 หลัก, 'b' = 2 หลัก, ... / 'Z' = 1 หลัก (ติดลบ), 'Y' = 2 หลัก ...
+//   - ต่อท้ายด้วย fraction (ห้ามลงท้ายด้วย '0')
+//
+// ต่อท้าย list ไปเรื่อย ๆ จะแค่ increment integer part key จึงยาวขึ้นแบบ log ไม่ใช่ linear
+package rank
+
+import (
+	"errors"
+	"strings"
+)
+
+const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// smallestInteger ต่ำกว่านี้ decrement ต่อไม่ได้แล้ว
+var smallestInteger = "A" + strings.Repeat("0", 26)
+
+var (
+	ErrInvalidKey = errors.New("invalid rank key")
+	ErrOutOfOrder = errors.New("rank keys out of order")
+	ErrExhausted  = errors.New("rank key space exhausted")
+)
+
+// Initial คือ key ของ item แรกใน list ว่าง
+func Initial() string {
+	return "a" + digits[:1]
+}
+
+// Between คืน key ที่อยู่ระหว่าง a กับ b (a < key < b)
+// a = "" แปลว่าต้นสุดของ list, b = "" แปลว่าท้ายสุดของ list
+func Between(a, b string) (string, error) {
+	if a != "" {
+		if err := validateKey(a); err != nil {
+			return "", err
+		}
+	}
+	if b != "" {
+		if err := validateKey(b); err != nil {
+			return "", err
+		}
+	}
+	if a != "" && b != "" && a >= b {
+		return "", ErrOutOfOrder
+	}
+
+	switch {
+	case a == "" && b == "":
+		return Initial(), nil
+
+	case a == "":
+		ib, err := integerPart(b)
+		if err != nil {
+			return "", err
+		}
+		fb := b[len(ib):]
+		if ib == smallestInteger {
+			m, err := midpoint("", fb, true)
+			if err != nil {
+				return "", err
+			}
+			return ib + m, nil
+		}
+		if ib < b {
+			return ib, nil
+		}
+		res, ok := decrementInteger(ib)
+		if !ok {
+			return "", ErrExhausted
+		}
+		return res, nil
+
+	case b == "":
+		ia, err := integerPart(a)
+		if err != nil {
+			return "", err
+		}
+		fa := a[len(ia):]
+		if i, ok := incrementInteger(ia); ok {
+			return i, nil
+		}
+		m, err := midpoint(fa, "", false)
+		if err != nil {
+			return "", err
+		}
+		return ia + m, nil
+	}
+
+	ia, err := integerPart(a)
+	if err != nil {
+		return "", err
+	}
+	ib, err := integerPart(b)
+	if err != nil {
+		return "", err
+	}
+	fa, fb := a[len(ia):], b[len(ib):]
+
+	if ia == ib {
+		m, err := midpoint(fa, fb, true)
+		if err != nil {
+			return "", err
+		}
+		return ia + m, nil
+	}
+
+	i, ok := incrementInteger(ia)
+	if !ok {
+		return "", ErrExhausted
+	}
+	if i < b {
+		return i, nil
+	}
+
+	m, err := midpoint(fa, "", false)
+	if err != nil {
+		return "", err
+	}
+	return ia + m, nil
+}
+
+// After คืน key ที่ต่อท้าย a
+func After(a string) (string, error) {
+	return Between(a, "")
+}
+
+// midpoint หา fraction ระหว่าง a กับ b (hasB = false แปลว่าไม่มีขอบบน)
+func midpoint(a, b string, hasB bool) (string, error) {
+	if hasB && a >= b {
+		return "", ErrOutOfOrder
+	}
+	if strings.HasSuffix(a, "0") || (hasB && strings.HasSuffix(b, "0")) {
+		return "", ErrInvalidKey
+	}
+
+	if hasB {
+		// ตัดส่วนหน้าที่เหมือนกันออก (a ที่สั้นกว่าถือว่าเติม '0' ต่อท้าย)
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			m, err := midpoint(tail(a, n), b[n:], true)
+			if err != nil {
+				return "", err
+			}
+			return b[:n] + m, nil
+		}
+	}
+
+	digitA := 0
+	if a != "" {
+		digitA = strings.IndexByte(digits, a[0])
+	}
+	digitB := len(digits)
+	if hasB {
+		digitB = strings.IndexByte(digits, b[0])
+	}
+
+	if digitB-digitA > 1 {
+		// ปัดครึ่งขึ้นแบบเดียวกับ Math.round
+		return string(digits[(digitA+digitB+1)/2]), nil
+	}
+
+	// หลักติดกัน ต้องไปหาที่ว่างในหลักถัดไป
+	if hasB && len(b) > 1 {
+		return b[:1], nil
+	}
+
+	m, err := midpoint(tail(a, 1), "", false)
+	if err != nil {
+		return "", err
+	}
+	return string(digits[digitA]) + m, nil
+}
+
+func integerLength(head byte) (int, error) {
+	switch {
+	case head >= 'a' && head <= 'z':
+		return int(head-'a') + 2, nil
+	case head >= 'A' && head <= 'Z':
+		return int('Z'-head) + 2, nil
+	}
+	return 0, ErrInvalidKey
+}
+
+func integerPart(key string) (string, error) {
+	if key == "" {
+		return "", ErrInvalidKey
+	}
+	n, err := integerLength(key[0])
+	if err != nil {
+		return "", err
+	}
+	if n > len(key) {
+		return "", ErrInvalidKey
+	}
+	return key[:n], nil
+}
+
+func validateKey(key string) error {
+	if key == smallestInteger {
+		return ErrInvalidKey
+	}
+	for i := 0; i < len(key); i++ {
+		if strings.IndexByte(digits, key[i]) < 0 {
+			return ErrInvalidKey
+		}
+	}
+	i, err := integerPart(key)
+	if err != nil {
+		return err
+	}
+	if strings.HasSuffix(key[len(i):], "0") {
+		return ErrInvalidKey
+	}
+	return nil
+}
+
+func incrementInteger(x string) (string, bool) {
+	head, digs := x[0], []byte(x[1:])
+
+	carry := true
+	for i := len(digs) - 1; carry && i >= 0; i-- {
+		d := strings.IndexByte(digits, digs[i]) + 1
+		if d == len(digits) {
+			digs[i] = digits[0]
+		} else {
+			digs[i] = digits[d]
+			carry = false
+		}
+	}
+
+	if !carry {
+		return string(head) + string(digs), true
+	}
+
+	switch head {
+	case 'Z':
+		return "a" + digits[:1], true
+	case 'z':
+		return "", false
+	}
+
+	h := head + 1
+	if h > 'a' {
+		digs = append(digs, digits[0])
+	} else {
+		digs = digs[:len(digs)-1]
+	}
+	return string(h) + string(digs), true
+}
+
+func decrementInteger(x string) (string, bool) {
+	head, digs := x[0], []byte(x[1:])
+
+	borrow := true
+	for i := len(digs) - 1; borrow && i >= 0; i-- {
+		d := strings.IndexByte(digits, digs[i]) - 1
+		if d == -1 {
+			digs[i] = digits[len(digits)-1]
+		} else {
+			digs[i] = digits[d]
+			borrow = false
+		}
+	}
+
+	if !borrow {
+		return string(head) + string(digs), true
+	}
+
+	switch head {
+	case 'a':
+		return "Z" + digits[len(digits)-1:], true
+	case 'A':
+		return "", false
+	}
+
+	h := head - 1
+	if h < 'Z' {
+		digs = append(digs, digits[len(digits)-1])
+	} else {
+		digs = digs[:len(digs)-1]
+	}
+	return string(h) + string(digs), true
+}
+
+func digitAt(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return digits[0]
+}
+
+func tail(s string, n int) string {
+	if n >= len(s) {
+		return ""
+	}
+	return s[n:]
+}
@@ -0,0 +1,609 @@
+// Package rrule แปลงและไล่วันของ recurrence rule ตาม RFC 5545 (เฉพาะส่วนที่ใช้กับ todo)
+//
+// รองรับ FREQ=DAILY|WEEKLY|MONTHLY|YEARLY, INTERVAL, COUNT, UNTIL, BYDAY, BYMONTHDAY, BYMONTH, BYSETPOS, WKST
+// ไม่รองรับ BYHOUR / BYMINUTE / BYSECOND / BYWEEKNO / BYYEARDAY (todo ละเอียดแค่ระดับวัน)
+//
+// ทุกอย่างคิดตามเวลาท้องถิ่นของ dtstart (time.Location ที่ติดมากับ dtstart)
+// ข้ามช่วง DST แล้ววันและเวลาบนนาฬิกายังเท่าเดิม เช่น 09:00 ทุกวันจันทร์ก็ยังเป็น 09:00
+//
+// ตาม RFC dtstart นับเป็น occurrence แรกเสมอ (แม้จะไม่ตรงกับ rule) และนับรวมใน COUNT
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRule = errors.New("invalid rrule")
+
+type Frequency int
+
+const (
+	Daily Frequency = iota
+	Weekly
+	Monthly
+	Yearly
+)
+
+var freqNames = map[string]Frequency{
+	"DAILY":   Daily,
+	"WEEKLY":  Weekly,
+	"MONTHLY": Monthly,
+	"YEARLY":  Yearly,
+}
+
+func (f Frequency) String() string {
+	return [...]string{"DAILY", "WEEKLY", "MONTHLY", "YEARLY"}[f]
+}
+
+var weekdayNames = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+func parseWeekday(s string) (time.Weekday, bool) {
+	for i, name := range weekdayNames {
+		if name == s {
+			return time.Weekday(i), true
+		}
+	}
+	return 0, false
+}
+
+// WeekdayNum = วันในสัปดาห์ + ลำดับใน period (BYDAY=2MO, -1FR)
+// N = 0 คือทุกวันนั้นใน period
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+func (w WeekdayNum) String() string {
+	if w.N == 0 {
+		return weekdayNames[w.Day]
+	}
+	return strconv.Itoa(w.N) + weekdayNames[w.Day]
+}
+
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int // 0 = ไม่จำกัด
+	ByDay      []WeekdayNum
+	ByMonthDay []int // ติดลบ = นับจากท้ายเดือน
+	ByMonth    []time.Month
+	BySetPos   []int
+	WeekStart  time.Weekday
+
+	until    *time.Time
+	untilUTC bool // UNTIL ลงท้ายด้วย Z (ไม่งั้นเป็นเวลาท้องถิ่นของ dtstart)
+	untilDay bool // UNTIL เป็นวันอย่างเดียว (รวมทั้งวันนั้น)
+}
+
+// Parse อ่าน rule เช่น "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10" (มี "RRULE:" นำหน้าก็ได้)
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return nil, invalid("empty rule")
+	}
+
+	r := &Rule{Interval: 1, WeekStart: time.Monday}
+	seen := map[string]bool{}
+	hasFreq := false
+
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		key = strings.ToUpper(strings.TrimSpace(key))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !ok || value == "" {
+			return nil, invalid("malformed part %q", part)
+		}
+		if seen[key] {
+			return nil, invalid("%s given twice", key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			f, ok := freqNames[value]
+			if !ok {
+				return nil, invalid("unsupported FREQ %q", value)
+			}
+			r.Freq, hasFreq = f, true
+		case "INTERVAL":
+			r.Interval, err = parseInt(value, 1, 1000)
+		case "COUNT":
+			r.Count, err = parseInt(value, 1, 10000)
+		case "UNTIL":
+			err = r.parseUntil(value)
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseIntList(value, 1, 31)
+		case "BYMONTH":
+			var months []int
+			months, err = parseIntList(value, 1, 12)
+			for _, m := range months {
+				if m < 0 {
+					return nil, invalid("BYMONTH cannot be negative")
+				}
+				r.ByMonth = append(r.ByMonth, time.Month(m))
+			}
+		case "BYSETPOS":
+			r.BySetPos, err = parseIntList(value, 1, 366)
+		case "WKST":
+			wd, ok := parseWeekday(value)
+			if !ok {
+				return nil, invalid("invalid WKST %q", value)
+			}
+			r.WeekStart = wd
+		default:
+			return nil, invalid("unsupported part %s", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !hasFreq {
+		return nil, invalid("FREQ is required")
+	}
+	if r.Count > 0 && r.until != nil {
+		return nil, invalid("COUNT and UNTIL cannot be used together")
+	}
+	if r.Freq == Weekly && len(r.ByMonthDay) > 0 {
+		return nil, invalid("BYMONTHDAY cannot be used with FREQ=WEEKLY")
+	}
+	// ลำดับใน BYDAY (2MO) มีความหมายเฉพาะ period ที่ยาวกว่าสัปดาห์
+	if r.Freq == Daily || r.Freq == Weekly {
+		for _, d := range r.ByDay {
+			if d.N != 0 {
+				return nil, invalid("BYDAY ordinals need FREQ=MONTHLY or YEARLY")
+			}
+		}
+	}
+	if len(r.BySetPos) > 0 && len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 && len(r.ByMonth) == 0 {
+		return nil, invalid("BYSETPOS needs another BYxxx part")
+	}
+
+	return r, nil
+}
+
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: "+format, append([]any{ErrInvalidRule}, args...)...)
+}
+
+func parseInt(s string, min, max int) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < min || n > max {
+		return 0, invalid("%q must be between %d and %d", s, min, max)
+	}
+	return n, nil
+}
+
+// parseIntList ค่าติดลบได้ (นับจากท้าย) แต่ห้ามเป็น 0
+func parseIntList(s string, min, max int) ([]int, error) {
+	var out []int
+	for _, v := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(v)
+		abs := n
+		if abs < 0 {
+			abs = -abs
+		}
+		if err != nil || abs < min || abs > max {
+			return nil, invalid("%q must be between %d and %d (or negative)", v, min, max)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+func parseByDay(s string) ([]WeekdayNum, error) {
+	var out []WeekdayNum
+	for _, v := range strings.Split(s, ",") {
+		if len(v) < 2 {
+			return nil, invalid("invalid BYDAY %q", v)
+		}
+		wd, ok := parseWeekday(v[len(v)-2:])
+		if !ok {
+			return nil, invalid("invalid BYDAY %q", v)
+		}
+		n := 0
+		if num := v[:len(v)-2]; num != "" {
+			var err error
+			if n, err = strconv.Atoi(num); err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, invalid("invalid BYDAY %q", v)
+			}
+		}
+		out = append(out, WeekdayNum{N: n, Day: wd})
+	}
+	return out, nil
+}
+
+func (r *Rule) parseUntil(v string) error {
+	layouts := []struct {
+		layout string
+		utc    bool
+		day    bool
+	}{
+		{"20060102T150405Z", true, false},
+		{"20060102T150405", false, false},
+		{"20060102", false, true},
+	}
+	for _, l := range layouts {
+		if t, err := time.Parse(l.layout, v); err == nil {
+			r.until, r.untilUTC, r.untilDay = &t, l.utc, l.day
+			return nil
+		}
+	}
+	return invalid("invalid UNTIL %q", v)
+}
+
+// untilIn แปลง UNTIL เป็นเวลาจริงใน loc
+func (r *Rule) untilIn(loc *time.Location) (time.Time, bool) {
+	if r.until == nil {
+		return time.Time{}, false
+	}
+	u := *r.until
+	switch {
+	case r.untilUTC:
+		return u, true
+	case r.untilDay:
+		return time.Date(u.Year(), u.Month(), u.Day(), 23, 59, 59, 999999999, loc), true
+	default:
+		return time.Date(u.Year(), u.Month(), u.Day(), u.Hour(), u.Minute(), u.Second(), 0, loc), true
+	}
+}
+
+// String คืน rule ในรูปแบบมาตรฐาน (ลำดับ part คงที่ ใช้เก็บ / เทียบกันได้)
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + r.Freq.String()}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.until != nil {
+		switch {
+		case r.untilUTC:
+			parts = append(parts, "UNTIL="+r.until.Format("20060102T150405Z"))
+		case r.untilDay:
+			parts = append(parts, "UNTIL="+r.until.Format("20060102"))
+		default:
+			parts = append(parts, "UNTIL="+r.until.Format("20060102T150405"))
+		}
+	}
+	if len(r.ByMonth) > 0 {
+		var ms []string
+		for _, m := range r.ByMonth {
+			ms = append(ms, strconv.Itoa(int(m)))
+		}
+		parts = append(parts, "BYMONTH="+strings.Join(ms, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByDay) > 0 {
+		var ds []string
+		for _, d := range r.ByDay {
+			ds = append(ds, d.String())
+		}
+		parts = append(parts, "BYDAY="+strings.Join(ds, ","))
+	}
+	if len(r.BySetPos) > 0 {
+		parts = append(parts, "BYSETPOS="+joinInts(r.BySetPos))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayNames[r.WeekStart])
+	}
+	return strings.Join(parts, ";")
+}
+
+func joinInts(ns []int) string {
+	s := make([]string, len(ns))
+	for i, n := range ns {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, ",")
+}
+
+// Next occurrence แรกที่อยู่หลัง after (false = rule จบแล้ว)
+func (r *Rule) Next(dtstart, after time.Time) (time.Time, bool) {
+	var next time.Time
+	found := false
+	r.iterate(dtstart, after, func(t time.Time) bool {
+		if t.After(after) {
+			next, found = t, true
+			return false
+		}
+		return true
+	})
+	return next, found
+}
+
+// Between occurrences ในช่วง [from, to] ไม่เกิน limit อัน
+func (r *Rule) Between(dtstart, from, to time.Time, limit int) []time.Time {
+	var out []time.Time
+	r.iterate(dtstart, from, func(t time.Time) bool {
+		if t.After(to) {
+			return false
+		}
+		if !t.Before(from) {
+			out = append(out, t)
+		}
+		return len(out) < limit
+	})
+	return out
+}
+
+// iterate เรียก fn ทีละ occurrence ตามลำดับเวลา หยุดเมื่อ fn คืน false / ครบ COUNT / เลย UNTIL / ไม่มีวันตรงอีกแล้ว
+// เริ่มจาก period ที่มีวันของ from (occurrence ก่อนหน้านั้นอาจข้ามไป fn ต้องไม่สนใจตัวที่ก่อน from เอง)
+func (r *Rule) iterate(dtstart, from time.Time, fn func(time.Time) bool) {
+	loc := dtstart.Location()
+	until, hasUntil := r.untilIn(loc)
+	if hasUntil && dtstart.After(until) {
+		return
+	}
+
+	// คิดวันด้วย UTC (ไม่มี DST) แล้วค่อยประกอบเป็นเวลาท้องถิ่นด้วยเวลาบนนาฬิกาของ dtstart
+	start := dateOf(dtstart)
+	hour, min, sec := dtstart.Clock()
+
+	p := r.firstPeriod(start, dateOf(from.In(loc)))
+	count := 0
+	if p == 0 {
+		// dtstart เป็น occurrence แรกเสมอ
+		if !fn(dtstart) {
+			return
+		}
+		count = 1
+		if r.Count > 0 && count >= r.Count {
+			return
+		}
+	}
+
+	// ปฏิทินเกรกอเรียนวนซ้ำทุก 400 ปี ว่างติดกันครบรอบแล้ว = ไม่มีวันตรงอีก (เช่น 30 ก.พ.)
+	cycle := r.Freq.periodsPerCycle()
+	for empty := 0; empty < cycle; p++ {
+		days := r.applySetPos(r.periodDays(start, p))
+		if len(days) == 0 {
+			empty++
+			continue
+		}
+		empty = 0
+
+		for _, d := range days {
+			t := time.Date(d.Year(), d.Month(), d.Day(), hour, min, sec, 0, loc)
+			if !t.After(dtstart) {
+				continue
+			}
+			if hasUntil && t.After(until) {
+				return
+			}
+			if !fn(t) {
+				return
+			}
+			count++
+			if r.Count > 0 && count >= r.Count {
+				return
+			}
+		}
+	}
+}
+
+// periodsPerCycle จำนวน period ใน 400 ปี (146097 วัน) วันใน period ซ้ำรูปแบบเดิมภายในเท่านี้เสมอไม่ว่า INTERVAL เท่าไร
+func (f Frequency) periodsPerCycle() int {
+	return [...]int{146097, 146097 / 7, 400 * 12, 400}[f]
+}
+
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// firstPeriod period ที่มีวัน day (นับจาก period ของ start) ถ้ามี COUNT ต้องนับจาก dtstart เสมอจึงเริ่มที่ 0
+func (r *Rule) firstPeriod(start, day time.Time) int {
+	if r.Count > 0 || !day.After(start) {
+		return 0
+	}
+
+	var n int
+	switch r.Freq {
+	case Daily:
+		n = daysBetween(start, day)
+	case Weekly:
+		n = daysBetween(r.weekOf(start), r.weekOf(day)) / 7
+	case Monthly:
+		n = (day.Year()-start.Year())*12 + int(day.Month()-start.Month())
+	default: // Yearly
+		n = day.Year() - start.Year()
+	}
+	return n / r.Interval
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// weekOf วันแรกของสัปดาห์ (ตาม WKST) ที่มี d
+func (r *Rule) weekOf(d time.Time) time.Time {
+	offset := (int(d.Weekday()) - int(r.WeekStart) + 7) % 7
+	return d.AddDate(0, 0, -offset)
+}
+
+// periodDays วันที่เข้าเงื่อนไขใน period ที่ p (เรียงแล้ว, เป็นเที่ยงคืน UTC)
+func (r *Rule) periodDays(start time.Time, p int) []time.Time {
+	step := p * r.Interval
+
+	switch r.Freq {
+	case Daily:
+		d := start.AddDate(0, 0, step)
+		if r.matchMonth(d) && r.matchMonthDay(d) && r.matchDay(d, 0, 0) {
+			return []time.Time{d}
+		}
+		return nil
+
+	case Weekly:
+		week := r.weekOf(start).AddDate(0, 0, 7*step)
+		var out []time.Time
+		for i := 0; i < 7; i++ {
+			d := week.AddDate(0, 0, i)
+			if len(r.ByDay) == 0 && d.Weekday() != start.Weekday() {
+				continue
+			}
+			if r.matchMonth(d) && r.matchDay(d, 0, 0) {
+				out = append(out, d)
+			}
+		}
+		return out
+
+	case Monthly:
+		first := time.Date(start.Year(), start.Month()+time.Month(step), 1, 0, 0, 0, 0, time.UTC)
+		if !r.matchMonth(first) {
+			return nil
+		}
+		return r.monthDays(first, start.Day())
+
+	default: // Yearly
+		year := start.Year() + step
+		// ไม่มี BYxxx เลย = วัน / เดือนเดียวกับ dtstart (29 ก.พ. ข้ามปีที่ไม่มี)
+		if len(r.ByMonth) == 0 && len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+			d := time.Date(year, start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+			if d.Month() != start.Month() {
+				return nil
+			}
+			return []time.Time{d}
+		}
+
+		// มี BYMONTH = ไล่ทีละเดือน (ลำดับใน BYDAY นับในเดือน)
+		if len(r.ByMonth) > 0 {
+			var out []time.Time
+			for m := time.January; m <= time.December; m++ {
+				first := time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+				if r.matchMonth(first) {
+					out = append(out, r.monthDays(first, start.Day())...)
+				}
+			}
+			return out
+		}
+
+		// ไม่มี BYMONTH = ลำดับใน BYDAY นับทั้งปี
+		first := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		yearLen := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+		var out []time.Time
+		for i := 0; i < yearLen; i++ {
+			d := first.AddDate(0, 0, i)
+			if len(r.ByMonthDay) > 0 && !r.matchMonthDay(d) {
+				continue
+			}
+			if len(r.ByDay) > 0 && !r.matchDay(d, d.YearDay(), yearLen) {
+				continue
+			}
+			out = append(out, d)
+		}
+		return out
+	}
+}
+
+// monthDays วันในเดือนของ first ตาม BYMONTHDAY / BYDAY (ไม่มีทั้งคู่ = วันที่เดียวกับ dtstart)
+func (r *Rule) monthDays(first time.Time, defaultDay int) []time.Time {
+	n := daysIn(first)
+
+	var out []time.Time
+	for day := 1; day <= n; day++ {
+		d := first.AddDate(0, 0, day-1)
+		if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+			if day == defaultDay {
+				out = append(out, d)
+			}
+			continue
+		}
+		if len(r.ByMonthDay) > 0 && !r.matchMonthDay(d) {
+			continue
+		}
+		if len(r.ByDay) > 0 && !r.matchDay(d, day, n) {
+			continue
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+func daysIn(first time.Time) int {
+	return first.AddDate(0, 1, -1).Day()
+}
+
+func (r *Rule) matchMonth(d time.Time) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, m := range r.ByMonth {
+		if d.Month() == m {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchMonthDay(d time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	n := daysIn(time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, time.UTC))
+	for _, md := range r.ByMonthDay {
+		if md == d.Day() || (md < 0 && n+md+1 == d.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchDay เช็ค BYDAY, pos / length = ตำแหน่งของวันใน period กับความยาว period (ใช้กับลำดับ 2MO / -1FR)
+func (r *Rule) matchDay(d time.Time, pos, length int) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if wd.Day != d.Weekday() {
+			continue
+		}
+		switch {
+		case wd.N == 0:
+			return true
+		case wd.N > 0 && (pos-1)/7+1 == wd.N:
+			return true
+		case wd.N < 0 && -((length-pos)/7+1) == wd.N:
+			return true
+		}
+	}
+	return false
+}
+
+// applySetPos เลือกเฉพาะลำดับที่ BYSETPOS ระบุจากวันใน period (เช่น -1 = วันสุดท้าย)
+func (r *Rule) applySetPos(days []time.Time) []time.Time {
+	if len(r.BySetPos) == 0 || len(days) == 0 {
+		return days
+	}
+
+	var out []time.Time
+	for _, pos := range r.BySetPos {
+		i := pos - 1
+		if pos < 0 {
+			i = len(days) + pos
+		}
+		if i >= 0 && i < len(days) {
+			out = append(out, days[i])
+		}
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].Before(out[b]) })
+
+	// ตัดตัวซ้ำ (BYSETPOS=1,-1 ใน period ที่มีวันเดียว)
+	uniq := out[:0]
+	for i, d := range out {
+		if i == 0 || !d.Equal(out[i-1]) {
+			uniq = append(uniq, d)
+		}
+	}
+	return uniq
+}
@@ -0,0 +1,257 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("load %s: %v", name, err)
+	}
+	return loc
+}
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart time.Time
+		want    []string // YYYY-MM-DD ตามลำดับ (รวม dtstart)
+	}{
+		{
+			name:    "second monday of the month",
+			rule:    "FREQ=MONTHLY;BYDAY=2MO",
+			dtstart: date(2026, time.January, 12),
+			want:    []string{"2026-01-12", "2026-02-09", "2026-03-09", "2026-04-13"},
+		},
+		{
+			name:    "last friday of the month",
+			rule:    "FREQ=MONTHLY;BYDAY=-1FR",
+			dtstart: date(2026, time.January, 30),
+			want:    []string{"2026-01-30", "2026-02-27", "2026-03-27", "2026-04-24"},
+		},
+		{
+			name:    "fourth thursday of november",
+			rule:    "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH",
+			dtstart: date(2026, time.November, 26),
+			want:    []string{"2026-11-26", "2027-11-25", "2028-11-23"},
+		},
+		{
+			name:    "first monday of the year without BYMONTH",
+			rule:    "FREQ=YEARLY;BYDAY=1MO",
+			dtstart: date(2026, time.January, 5),
+			want:    []string{"2026-01-05", "2027-01-04", "2028-01-03"},
+		},
+		{
+			name:    "last weekday of the month",
+			rule:    "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			dtstart: date(2026, time.January, 30),
+			want:    []string{"2026-01-30", "2026-02-27", "2026-03-31", "2026-04-30"},
+		},
+		{
+			name:    "first and last day of the month",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=1,-1;BYSETPOS=1,-1",
+			dtstart: date(2026, time.February, 1),
+			want:    []string{"2026-02-01", "2026-02-28", "2026-03-01", "2026-03-31"},
+		},
+		{
+			name:    "day 31 skips short months",
+			rule:    "FREQ=MONTHLY",
+			dtstart: date(2026, time.January, 31),
+			want:    []string{"2026-01-31", "2026-03-31", "2026-05-31", "2026-07-31"},
+		},
+		{
+			name:    "february 29 only in leap years",
+			rule:    "FREQ=YEARLY",
+			dtstart: date(2024, time.February, 29),
+			want:    []string{"2024-02-29", "2028-02-29", "2032-02-29"},
+		},
+		{
+			name:    "february 29 skips 2100",
+			rule:    "FREQ=YEARLY;INTERVAL=4",
+			dtstart: date(2096, time.February, 29),
+			want:    []string{"2096-02-29", "2104-02-29"},
+		},
+		{
+			name:    "every other week on monday",
+			rule:    "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO",
+			dtstart: date(2026, time.January, 5),
+			want:    []string{"2026-01-05", "2026-01-19", "2026-02-02"},
+		},
+		{
+			name:    "count includes dtstart",
+			rule:    "FREQ=DAILY;COUNT=3",
+			dtstart: date(2026, time.January, 1),
+			want:    []string{"2026-01-01", "2026-01-02", "2026-01-03"},
+		},
+		{
+			name:    "until date is inclusive",
+			rule:    "FREQ=DAILY;UNTIL=20260103",
+			dtstart: date(2026, time.January, 1),
+			want:    []string{"2026-01-01", "2026-01-02", "2026-01-03"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+
+			// ขอเกินไปหนึ่งอัน rule ที่มี COUNT / UNTIL ต้องหยุดตรงตัวสุดท้าย
+			got := r.Between(tt.dtstart, tt.dtstart, tt.dtstart.AddDate(20, 0, 0), len(tt.want)+1)
+			if r.Count == 0 && r.until == nil && len(got) > len(tt.want) {
+				got = got[:len(tt.want)]
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d occurrences %v, want %v", len(got), got, tt.want)
+			}
+			for i, d := range got {
+				if s := d.Format(time.DateOnly); s != tt.want[i] {
+					t.Errorf("occurrence %d = %s, want %s", i, s, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart time.Time
+		after   time.Time
+		want    string // "" = rule จบแล้ว
+	}{
+		{
+			name:    "daily far in the future does not end",
+			rule:    "FREQ=DAILY",
+			dtstart: date(2000, time.January, 1),
+			after:   date(2100, time.June, 15),
+			want:    "2100-06-16",
+		},
+		{
+			name:    "interval keeps its phase after skipping ahead",
+			rule:    "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO",
+			dtstart: date(2026, time.January, 5),
+			after:   date(2026, time.March, 1),
+			want:    "2026-03-02",
+		},
+		{
+			name:    "monthly interval skipping ahead",
+			rule:    "FREQ=MONTHLY;INTERVAL=3;BYMONTHDAY=15",
+			dtstart: date(2026, time.January, 15),
+			after:   date(2030, time.February, 1),
+			want:    "2030-04-15",
+		},
+		{
+			name:    "after dtstart on the same day",
+			rule:    "FREQ=DAILY",
+			dtstart: date(2026, time.January, 1),
+			after:   date(2026, time.January, 1),
+			want:    "2026-01-02",
+		},
+		{
+			name:    "count exhausted",
+			rule:    "FREQ=DAILY;COUNT=3",
+			dtstart: date(2026, time.January, 1),
+			after:   date(2026, time.January, 3),
+			want:    "",
+		},
+		{
+			name:    "until passed",
+			rule:    "FREQ=WEEKLY;UNTIL=20260201",
+			dtstart: date(2026, time.January, 1),
+			after:   date(2026, time.January, 29),
+			want:    "",
+		},
+		{
+			name:    "impossible date ends",
+			rule:    "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30",
+			dtstart: date(2026, time.January, 1),
+			after:   date(2026, time.January, 1),
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+
+			next, ok := r.Next(tt.dtstart, tt.after)
+			switch {
+			case tt.want == "" && ok:
+				t.Fatalf("got %s, want the rule to have ended", next.Format(time.DateOnly))
+			case tt.want != "" && !ok:
+				t.Fatalf("rule ended, want %s", tt.want)
+			case ok && next.Format(time.DateOnly) != tt.want:
+				t.Fatalf("got %s, want %s", next.Format(time.DateOnly), tt.want)
+			}
+		})
+	}
+}
+
+func TestDSTKeepsWallClock(t *testing.T) {
+	tests := []struct {
+		name    string
+		zone    string
+		rule    string
+		dtstart []int // year, month, day, hour, minute
+		want    []string
+	}{
+		{
+			name:    "daily across spring forward",
+			zone:    "America/New_York",
+			rule:    "FREQ=DAILY",
+			dtstart: []int{2026, 3, 7, 9, 0},
+			want:    []string{"2026-03-07T09:00:00-05:00", "2026-03-08T09:00:00-04:00", "2026-03-09T09:00:00-04:00"},
+		},
+		{
+			name:    "weekly across fall back",
+			zone:    "Europe/Berlin",
+			rule:    "FREQ=WEEKLY",
+			dtstart: []int{2026, 10, 19, 18, 30},
+			want:    []string{"2026-10-19T18:30:00+02:00", "2026-10-26T18:30:00+01:00", "2026-11-02T18:30:00+01:00"},
+		},
+		{
+			name:    "midnight all-day occurrences stay on their date",
+			zone:    "America/Sao_Paulo",
+			rule:    "FREQ=MONTHLY;BYDAY=1SU",
+			dtstart: []int{2026, 1, 4, 0, 0},
+			want:    []string{"2026-01-04T00:00:00-03:00", "2026-02-01T00:00:00-03:00", "2026-03-01T00:00:00-03:00"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := mustLoad(t, tt.zone)
+			r, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+
+			d := tt.dtstart
+			dtstart := time.Date(d[0], time.Month(d[1]), d[2], d[3], d[4], 0, 0, loc)
+			got := r.Between(dtstart, dtstart, dtstart.AddDate(1, 0, 0), len(tt.want))
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d occurrences %v, want %v", len(got), got, tt.want)
+			}
+			for i, occ := range got {
+				if s := occ.Format(time.RFC3339); s != tt.want[i] {
+					t.Errorf("occurrence %d = %s, want %s", i, s, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -2,7 +2,9 @@ package utils
 
 import (
 	"encoding/json"
+	"net"
 	"net/http"
+	"strings"
 )
 
 func WriteJSON(w http.ResponseWriter, status int, data any) {
@@ -14,3 +16,25 @@ func WriteJSON(w http.ResponseWriter, status int, data any) {
 func WriteError(w http.ResponseWriter, status int, message string) {
 	WriteJSON(w, status, map[string]string{"message": message})
 }
+
+// ClientIP คืน IP ของ client (ตัด port ออก) ใช้คู่กับ middleware.RealIP ที่เซ็ต RemoteAddr จาก X-Forwarded-For ให้
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// QueryFlags อ่าน query param แบบคั่นด้วย comma เป็น set เช่น ?include=archived,stats
+func QueryFlags(r *http.Request, key string) map[string]bool {
+	flags := make(map[string]bool)
+	for _, v := range r.URL.Query()[key] {
+		for _, f := range strings.Split(v, ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				flags[f] = true
+			}
+		}
+	}
+	return flags
+}